@@ -1,6 +1,7 @@
 package main
 
 import (
+    "context"
     "log"
     "os"
     "os/signal"
@@ -10,6 +11,7 @@ import (
     "github.com/blockchain-dapp/backend/internal/pkg/config"
     "github.com/blockchain-dapp/backend/internal/pkg/database"
     "github.com/blockchain-dapp/backend/internal/pkg/logger"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
 
     "github.com/gofiber/fiber/v2"
     "github.com/gofiber/fiber/v2/middleware/cors"
@@ -59,6 +61,15 @@ func main() {
     // Load configuration
     cfg := config.Load()
 
+    // Load additional chains, such as new EVM networks, from the chain registry file
+    if cfg.ChainRegistryPath != "" {
+        registry, err := blockchain.LoadRegistry(cfg.ChainRegistryPath)
+        if err != nil {
+            log.Fatalf("Failed to load chain registry: %v", err)
+        }
+        blockchain.SetRegistry(registry)
+    }
+
     // Add a small delay to ensure database is ready
     log.Println("Waiting for database to be ready...")
     time.Sleep(5 * time.Second)
@@ -70,6 +81,17 @@ func main() {
     // Run database migrations
     database.Migrate(db)
 
+    wallets, err := setupWalletServices(db, cfg)
+    if err != nil {
+        log.Fatalf("Failed to set up wallet services: %v", err)
+    }
+    log.Printf("Wallet services ready for %d chains", len(wallets.adapters))
+
+    // Deposit detection and confirmation tracking run until the server shuts down
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    wallets.start(ctx)
+
     // Create fiber app
     app := fiber.New(fiber.Config{
         Prefork:       false,
@@ -113,5 +135,9 @@ func main() {
         log.Fatalf("Server forced to shutdown: %v", err)
     }
 
+    // Stop the wallet services before the database is closed
+    cancel()
+    wallets.wait()
+
     log.Println("Server exiting")
 }
\ No newline at end of file
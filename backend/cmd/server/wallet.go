@@ -0,0 +1,164 @@
+package main
+
+import (
+    "context"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/config"
+    "github.com/blockchain-dapp/backend/internal/pkg/security"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "github.com/blockchain-dapp/backend/internal/wallet/custodial"
+    "github.com/blockchain-dapp/backend/internal/wallet/hdwallet"
+    "github.com/blockchain-dapp/backend/internal/wallet/services"
+    "gorm.io/gorm"
+)
+
+// How often the background wallet services look at the chains
+const (
+    blockPollInterval        = 10 * time.Second
+    confirmationPollInterval = 30 * time.Second
+    mempoolPollInterval      = time.Minute
+)
+
+// walletServices holds the chain adapters, the deposit and withdrawal services built on them, and
+// the background services that detect deposits and follow them and withdrawals to finality
+type walletServices struct {
+    adapters    map[string]blockchain.Adapter
+    deposits    *services.DepositService
+    withdrawals *services.WithdrawalService
+    watchers    map[string]*services.BlockWatcher
+    mempools    map[string]*services.MempoolMonitor
+    tracker     *services.ConfirmationTracker
+    wg          sync.WaitGroup
+}
+
+// setupWalletServices creates an adapter and a block watcher for every registered chain, a mempool
+// monitor for every chain whose node shows pending transactions, and the services on top of them.
+// Deposit addresses are derived from the configured master seed, when there is one.
+func setupWalletServices(db *gorm.DB, cfg *config.Config) (*walletServices, error) {
+    factory := blockchain.NewAdapterFactory(blockchain.Chains())
+    // Nonces are reserved in the database, so replicas sending from the same hot wallet never collide
+    factory.UseNonceManager(blockchain.NewDBNonceManager(db))
+
+    adapters, err := factory.CreateAdapters()
+    if err != nil {
+        return nil, fmt.Errorf("failed to create chain adapters: %w", err)
+    }
+
+    deposits := services.NewDepositService(db, adapters)
+    keyring, err := loadKeyring(cfg)
+    if err != nil {
+        return nil, err
+    }
+    if keyring != nil {
+        deposits.UseKeyring(keyring)
+    } else {
+        log.Println("Warning: HD_MASTER_SEED is not set, deposit addresses get random keys that no seed backup can restore")
+    }
+
+    watchers, err := services.NewBlockWatchers(db, blockchain.Chains(), blockPollInterval)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create block watchers: %w", err)
+    }
+
+    // Deposits are only credited once the block watcher of their chain has read them again
+    tracker := services.NewConfirmationTracker(db, adapters, confirmationPollInterval)
+    mempools := make(map[string]*services.MempoolMonitor)
+    for _, chain := range blockchain.Chains().Chains() {
+        watcher := watchers[chain.Name]
+        deposits.OnAddressCreated(watcher.AddAddress)
+        tracker.UseBlockWatcher(watcher)
+
+        mempool, err := newMempoolMonitor(db, chain)
+        if err != nil {
+            return nil, err
+        }
+        if mempool != nil {
+            deposits.OnAddressCreated(mempool.AddAddress)
+            mempools[chain.Name] = mempool
+        }
+    }
+
+    return &walletServices{
+        adapters:    adapters,
+        deposits:    deposits,
+        withdrawals: services.NewWithdrawalService(db, adapters, map[string]custodial.Provider{}),
+        watchers:    watchers,
+        mempools:    mempools,
+        tracker:     tracker,
+    }, nil
+}
+
+// newMempoolMonitor creates the mempool monitor of a chain, nil if its node cannot show pending transactions
+func newMempoolMonitor(db *gorm.DB, chain blockchain.ChainConfig) (*services.MempoolMonitor, error) {
+    watcher, err := services.NewChainWatcher(chain)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create chain watcher for %s: %w", chain.Name, err)
+    }
+
+    mempool, err := services.NewMempoolMonitor(db, chain, watcher, mempoolPollInterval)
+    if errors.Is(err, services.ErrMempoolUnsupported) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return mempool, nil
+}
+
+// start runs the block watchers, mempool monitors and confirmation tracker in the background
+// until the context is cancelled
+func (w *walletServices) start(ctx context.Context) {
+    for chain, watcher := range w.watchers {
+        w.run(ctx, chain+" block watcher", watcher.Start)
+    }
+    for chain, mempool := range w.mempools {
+        w.run(ctx, chain+" mempool monitor", mempool.Start)
+    }
+    w.run(ctx, "confirmation tracker", w.tracker.Start)
+}
+
+// run runs a background service on its own goroutine, logging why it stopped unless the context
+// was cancelled
+func (w *walletServices) run(ctx context.Context, name string, start func(context.Context) error) {
+    w.wg.Add(1)
+    go func() {
+        defer w.wg.Done()
+
+        if err := start(ctx); err != nil && ctx.Err() == nil {
+            log.Printf("Error: %s stopped: %v", name, err)
+        }
+    }()
+}
+
+// wait blocks until every background service started by start has returned
+func (w *walletServices) wait() {
+    w.wg.Wait()
+}
+
+// loadKeyring decrypts the base64 master seed of the configuration with the KMS master key. It
+// returns nil when no seed is configured.
+func loadKeyring(cfg *config.Config) (*hdwallet.Keyring, error) {
+    if cfg.HDMasterSeed == "" {
+        return nil, nil
+    }
+    if cfg.KMSMasterKey == "" {
+        return nil, fmt.Errorf("KMS_MASTER_KEY is required to decrypt HD_MASTER_SEED")
+    }
+
+    ciphertext, err := base64.StdEncoding.DecodeString(cfg.HDMasterSeed)
+    if err != nil {
+        return nil, fmt.Errorf("HD_MASTER_SEED is not valid base64: %w", err)
+    }
+
+    keyring, err := hdwallet.NewKeyringFromEncryptedSeed(security.NewLocalKMS(cfg.KMSMasterKey), ciphertext)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load the deposit keyring: %w", err)
+    }
+    return keyring, nil
+}
@@ -2,6 +2,8 @@ package accounting
 
 import (
     "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
 )
 
 // TransactionType represents the type of financial transaction
@@ -40,19 +42,20 @@ const (
     AccountTypeExpense   AccountType = "expense"
 )
 
-// Account represents a financial account
+// Account represents a financial account. A user has at most one account of a name, type and
+// currency, and so does the platform, whose accounts have no user.
 type Account struct {
-    ID          uint        `gorm:"primaryKey" json:"id"`
-    UserID      *uint       `json:"user_id,omitempty"`
-    Name        string      `gorm:"not null" json:"name"`
-    Type        AccountType `gorm:"not null" json:"type"`
-    Currency    string      `gorm:"not null" json:"currency"`
-    Balance     float64     `gorm:"not null;default:0" json:"balance"`
-    IsFrozen    bool        `gorm:"not null;default:false" json:"is_frozen"`
-    Description string      `json:"description,omitempty"`
-    Metadata    string      `json:"metadata,omitempty"` // JSON field for additional data
-    CreatedAt   time.Time   `json:"created_at"`
-    UpdatedAt   time.Time   `json:"updated_at"`
+    ID          uint          `gorm:"primaryKey" json:"id"`
+    UserID      *uint         `gorm:"uniqueIndex:idx_account_user_name,priority:1,where:user_id IS NOT NULL" json:"user_id,omitempty"`
+    Name        string        `gorm:"not null;uniqueIndex:idx_account_user_name,priority:2;uniqueIndex:idx_account_platform_name,priority:1,where:user_id IS NULL" json:"name"`
+    Type        AccountType   `gorm:"not null;uniqueIndex:idx_account_user_name,priority:3;uniqueIndex:idx_account_platform_name,priority:2" json:"type"`
+    Currency    string        `gorm:"not null;uniqueIndex:idx_account_user_name,priority:4;uniqueIndex:idx_account_platform_name,priority:3" json:"currency"`
+    Balance     units.Decimal `gorm:"not null;default:0" json:"balance"` // exact, in display units of the currency
+    IsFrozen    bool          `gorm:"not null;default:false" json:"is_frozen"`
+    Description string        `json:"description,omitempty"`
+    Metadata    string        `json:"metadata,omitempty"` // JSON field for additional data
+    CreatedAt   time.Time     `json:"created_at"`
+    UpdatedAt   time.Time     `json:"updated_at"`
 }
 
 // Transaction represents a financial transaction
@@ -63,9 +66,9 @@ type Transaction struct {
     CounterpartyID  *uint            `json:"counterparty_id,omitempty"`
     Type            TransactionType  `gorm:"not null" json:"type"`
     Status          TransactionStatus `gorm:"not null" json:"status"`
-    Amount          float64          `gorm:"not null" json:"amount"`
+    Amount          units.Decimal    `gorm:"not null" json:"amount"` // exact, in display units of the currency
     Currency        string           `gorm:"not null" json:"currency"`
-    Fee             *float64         `json:"fee,omitempty"`
+    Fee             *units.Decimal   `json:"fee,omitempty"`
     Description     string           `json:"description,omitempty"`
     Metadata        string           `json:"metadata,omitempty"` // JSON field for additional data
     ProcessedAt     *time.Time       `json:"processed_at,omitempty"`
@@ -77,20 +80,20 @@ type Transaction struct {
 
 // JournalEntry represents a double-entry bookkeeping journal entry
 type JournalEntry struct {
-    ID            uint      `gorm:"primaryKey" json:"id"`
-    TransactionID uint      `gorm:"not null" json:"transaction_id"`
-    AccountID     uint      `gorm:"not null" json:"account_id"`
-    Debit         float64   `gorm:"not null;default:0" json:"debit"`
-    Credit        float64   `gorm:"not null;default:0" json:"credit"`
-    Description   string    `json:"description,omitempty"`
-    CreatedAt     time.Time `json:"created_at"`
+    ID            uint          `gorm:"primaryKey" json:"id"`
+    TransactionID uint          `gorm:"not null" json:"transaction_id"`
+    AccountID     uint          `gorm:"not null" json:"account_id"`
+    Debit         units.Decimal `gorm:"not null;default:0" json:"debit"` // exact, in display units of the account's currency
+    Credit        units.Decimal `gorm:"not null;default:0" json:"credit"`
+    Description   string        `json:"description,omitempty"`
+    CreatedAt     time.Time     `json:"created_at"`
 }
 
 // Balance represents an account balance at a point in time
 type Balance struct {
-    ID        uint      `gorm:"primaryKey" json:"id"`
-    AccountID uint      `gorm:"not null" json:"account_id"`
-    Date      time.Time `gorm:"not null" json:"date"`
-    Balance   float64   `gorm:"not null" json:"balance"`
-    CreatedAt time.Time `json:"created_at"`
+    ID        uint          `gorm:"primaryKey" json:"id"`
+    AccountID uint          `gorm:"not null" json:"account_id"`
+    Date      time.Time     `gorm:"not null" json:"date"`
+    Balance   units.Decimal `gorm:"not null" json:"balance"`
+    CreatedAt time.Time     `json:"created_at"`
 }
\ No newline at end of file
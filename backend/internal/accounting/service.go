@@ -5,7 +5,9 @@ import (
     "fmt"
     "time"
 
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 // LedgerService handles accounting and ledger operations
@@ -64,7 +66,7 @@ func (s *LedgerService) GetAccountByUserID(ctx context.Context, userID uint) (*A
 }
 
 // UpdateAccountBalance updates an account's balance
-func (s *LedgerService) UpdateAccountBalance(ctx context.Context, accountID uint, amount float64) error {
+func (s *LedgerService) UpdateAccountBalance(ctx context.Context, accountID uint, amount units.Decimal) error {
     return s.db.WithContext(ctx).Model(&Account{}).Where("id = ?", accountID).Update("balance", gorm.Expr("balance + ?", amount)).Error
 }
 
@@ -144,13 +146,13 @@ func (s *LedgerService) GetJournalEntriesByTransaction(ctx context.Context, tran
 }
 
 // GetAccountBalance retrieves the current balance for an account
-func (s *LedgerService) GetAccountBalance(ctx context.Context, accountID uint) (float64, error) {
+func (s *LedgerService) GetAccountBalance(ctx context.Context, accountID uint) (units.Decimal, error) {
     var account Account
     if err := s.db.WithContext(ctx).First(&account, "id = ?", accountID).Error; err != nil {
         if err == gorm.ErrRecordNotFound {
-            return 0, fmt.Errorf("account not found")
+            return units.Decimal{}, fmt.Errorf("account not found")
         }
-        return 0, fmt.Errorf("failed to get account: %w", err)
+        return units.Decimal{}, fmt.Errorf("failed to get account: %w", err)
     }
     
     return account.Balance, nil
@@ -164,4 +166,184 @@ func (s *LedgerService) GetAccountTransactions(ctx context.Context, accountID ui
     }
     
     return transactions, nil
-}
\ No newline at end of file
+}
+
+// FindOrCreateAccount returns the account with the given user, name, type and currency, creating
+// it from the given fields if there is none. A nil user ID names a platform account. Concurrent
+// calls for the same account all get the one row, as the unique index turns the losing inserts
+// into no-ops.
+func (s *LedgerService) FindOrCreateAccount(ctx context.Context, account Account) (*Account, error) {
+    columns := []clause.Column{{Name: "name"}, {Name: "type"}, {Name: "currency"}}
+    owner := "user_id IS NULL"
+    if account.UserID != nil {
+        columns = append([]clause.Column{{Name: "user_id"}}, columns...)
+        owner = "user_id IS NOT NULL"
+    }
+
+    now := time.Now()
+    account.CreatedAt = now
+    account.UpdatedAt = now
+    err := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+        Columns:     columns,
+        TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: owner}}},
+        DoNothing:   true,
+    }).Create(&account).Error
+    if err != nil {
+        return nil, fmt.Errorf("failed to create account: %w", err)
+    }
+
+    query := s.db.WithContext(ctx).Where("name = ? AND type = ? AND currency = ?", account.Name, account.Type, account.Currency)
+    if account.UserID != nil {
+        query = query.Where("user_id = ?", *account.UserID)
+    } else {
+        query = query.Where("user_id IS NULL")
+    }
+
+    var existing Account
+    if err := query.First(&existing).Error; err != nil {
+        return nil, fmt.Errorf("failed to get account: %w", err)
+    }
+    return &existing, nil
+}
+
+// PostTransaction records a completed transaction together with its journal entries and applies
+// the entries to the account balances, all in one database transaction. The debits must equal
+// the credits exactly. Posting is idempotent by reference ID: if a transaction with the same reference
+// was already posted, it is returned and nothing is written, and posted is false.
+func (s *LedgerService) PostTransaction(ctx context.Context, transaction Transaction, entries []JournalEntry) (result *Transaction, posted bool, err error) {
+    var debits, credits units.Decimal
+    for _, entry := range entries {
+        if entry.Debit.Sign() < 0 || entry.Credit.Sign() < 0 {
+            return nil, false, fmt.Errorf("journal entries must not be negative")
+        }
+        debits = debits.Add(entry.Debit)
+        credits = credits.Add(entry.Credit)
+    }
+    if len(entries) < 2 || debits.Cmp(credits) != 0 {
+        return nil, false, fmt.Errorf("journal entries of %s are not balanced: debits %s, credits %s", transaction.ReferenceID, debits, credits)
+    }
+
+    err = s.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+        var existing Transaction
+        if err := db.Where("reference_id = ?", transaction.ReferenceID).Limit(1).Find(&existing).Error; err != nil {
+            return err
+        }
+        if existing.ID != 0 {
+            result = &existing
+            return nil
+        }
+
+        now := time.Now()
+        transaction.Status = StatusCompleted
+        transaction.ProcessedAt = &now
+        transaction.CreatedAt = now
+        transaction.UpdatedAt = now
+        if err := db.Create(&transaction).Error; err != nil {
+            return err
+        }
+
+        for _, entry := range entries {
+            entry.TransactionID = transaction.ID
+            entry.CreatedAt = now
+            if err := db.Create(&entry).Error; err != nil {
+                return err
+            }
+
+            var account Account
+            if err := db.First(&account, "id = ?", entry.AccountID).Error; err != nil {
+                return fmt.Errorf("failed to get account %d: %w", entry.AccountID, err)
+            }
+            if err := db.Model(&Account{}).Where("id = ?", account.ID).Updates(map[string]interface{}{
+                "balance":    gorm.Expr("balance + ?", balanceChange(account.Type, entry)),
+                "updated_at": now,
+            }).Error; err != nil {
+                return err
+            }
+        }
+
+        result, posted = &transaction, true
+        return nil
+    })
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to post transaction %s: %w", transaction.ReferenceID, err)
+    }
+
+    return result, posted, nil
+}
+
+// ReverseTransaction posts the mirror image of a completed transaction under a new reference ID
+// and marks the original as reversed. Reversing a transaction twice is a no-op that returns the
+// first reversal, and posted is false.
+func (s *LedgerService) ReverseTransaction(ctx context.Context, referenceID, reason string) (reversal *Transaction, posted bool, err error) {
+    err = s.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+        var original Transaction
+        if err := db.First(&original, "reference_id = ?", referenceID).Error; err != nil {
+            if err == gorm.ErrRecordNotFound {
+                return fmt.Errorf("transaction not found")
+            }
+            return err
+        }
+
+        if original.Status == StatusReversed {
+            var existing Transaction
+            if err := db.First(&existing, "reference_id = ?", referenceID+":reversal").Error; err != nil {
+                return fmt.Errorf("failed to get reversal: %w", err)
+            }
+            reversal = &existing
+            return nil
+        }
+
+        var entries []JournalEntry
+        if err := db.Where("transaction_id = ?", original.ID).Find(&entries).Error; err != nil {
+            return err
+        }
+        for i := range entries {
+            entries[i].ID = 0
+            entries[i].Debit, entries[i].Credit = entries[i].Credit, entries[i].Debit
+            entries[i].Description = "Reversal: " + entries[i].Description
+        }
+
+        ledger := NewLedgerService(db)
+        result, isNew, err := ledger.PostTransaction(ctx, Transaction{
+            ReferenceID:    referenceID + ":reversal",
+            AccountID:      original.AccountID,
+            CounterpartyID: original.CounterpartyID,
+            Type:           TypeAdjustment,
+            Amount:         original.Amount,
+            Currency:       original.Currency,
+            Description:    fmt.Sprintf("Reversal of %s: %s", referenceID, reason),
+        }, entries)
+        if err != nil {
+            return err
+        }
+
+        now := time.Now()
+        if err := db.Model(&original).Updates(map[string]interface{}{
+            "status":          StatusReversed,
+            "reversed_at":     now,
+            "reversal_reason": reason,
+            "updated_at":      now,
+        }).Error; err != nil {
+            return err
+        }
+
+        reversal, posted = result, isNew
+        return nil
+    })
+    if err != nil {
+        return nil, false, fmt.Errorf("failed to reverse transaction %s: %w", referenceID, err)
+    }
+
+    return reversal, posted, nil
+}
+
+// balanceChange returns how a journal entry changes the balance of an account of a type:
+// debits increase assets and expenses, credits increase liabilities, equity and revenue
+func balanceChange(accountType AccountType, entry JournalEntry) units.Decimal {
+    switch accountType {
+    case AccountTypeAsset, AccountTypeExpense:
+        return entry.Debit.Sub(entry.Credit)
+    default:
+        return entry.Credit.Sub(entry.Debit)
+    }
+}
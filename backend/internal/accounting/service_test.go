@@ -0,0 +1,226 @@
+package accounting
+
+import (
+    "context"
+    "fmt"
+    "testing"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "gorm.io/driver/sqlite"
+    "gorm.io/gorm"
+    "gorm.io/gorm/logger"
+)
+
+// newTestLedger creates a ledger service on an in-memory database
+func newTestLedger(t *testing.T) *LedgerService {
+    t.Helper()
+
+    db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{
+        Logger: logger.Default.LogMode(logger.Silent),
+    })
+    if err != nil {
+        t.Fatalf("failed to open database: %v", err)
+    }
+    sqlDB, err := db.DB()
+    if err != nil {
+        t.Fatalf("failed to open database: %v", err)
+    }
+    sqlDB.SetMaxOpenConns(1)
+    t.Cleanup(func() { sqlDB.Close() })
+
+    ledger := NewLedgerService(db)
+    if err := ledger.InitializeDB(); err != nil {
+        t.Fatalf("failed to migrate ledger tables: %v", err)
+    }
+    return ledger
+}
+
+// newTestAccounts creates an asset and a liability account in the same currency
+func newTestAccounts(t *testing.T, ledger *LedgerService) (*Account, *Account) {
+    t.Helper()
+
+    ctx := context.Background()
+    asset, err := ledger.FindOrCreateAccount(ctx, Account{Name: "hot wallet", Type: AccountTypeAsset, Currency: "ETH"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    userID := uint(7)
+    liability, err := ledger.FindOrCreateAccount(ctx, Account{UserID: &userID, Name: "customer deposits", Type: AccountTypeLiability, Currency: "ETH"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    return asset, liability
+}
+
+// postDeposit posts a deposit of amount from the asset to the liability account under a reference
+func postDeposit(t *testing.T, ledger *LedgerService, reference string, asset, liability *Account, amount string) (*Transaction, bool) {
+    t.Helper()
+
+    value := mustDecimal(t, amount)
+    result, posted, err := ledger.PostTransaction(context.Background(), Transaction{
+        ReferenceID:    reference,
+        AccountID:      liability.ID,
+        CounterpartyID: &asset.ID,
+        Type:           TypeDeposit,
+        Amount:         value,
+        Currency:       "ETH",
+    }, []JournalEntry{
+        {AccountID: asset.ID, Debit: value},
+        {AccountID: liability.ID, Credit: value},
+    })
+    if err != nil {
+        t.Fatalf("PostTransaction(%s) error = %v", reference, err)
+    }
+    return result, posted
+}
+
+// assertBalance fails the test unless an account's balance is want
+func assertBalance(t *testing.T, ledger *LedgerService, account *Account, want string) {
+    t.Helper()
+
+    balance, err := ledger.GetAccountBalance(context.Background(), account.ID)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if balance.String() != want {
+        t.Errorf("balance of %s = %s, want %s", account.Name, balance, want)
+    }
+}
+
+func TestPostTransactionIsIdempotent(t *testing.T) {
+    ledger := newTestLedger(t)
+    asset, liability := newTestAccounts(t, ledger)
+
+    first, posted := postDeposit(t, ledger, "deposit:1", asset, liability, "1.5")
+    if !posted {
+        t.Fatalf("first posting was not posted")
+    }
+    second, posted := postDeposit(t, ledger, "deposit:1", asset, liability, "1.5")
+    if posted {
+        t.Errorf("second posting of the same reference was posted")
+    }
+    if second.ID != first.ID {
+        t.Errorf("second posting returned transaction %d, want %d", second.ID, first.ID)
+    }
+
+    assertBalance(t, ledger, asset, "1.5")
+    assertBalance(t, ledger, liability, "1.5")
+}
+
+func TestReverseTransactionIsIdempotent(t *testing.T) {
+    ledger := newTestLedger(t)
+    asset, liability := newTestAccounts(t, ledger)
+    ctx := context.Background()
+
+    postDeposit(t, ledger, "deposit:1", asset, liability, "2")
+
+    first, posted, err := ledger.ReverseTransaction(ctx, "deposit:1", "reorg")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !posted {
+        t.Fatalf("first reversal was not posted")
+    }
+    second, posted, err := ledger.ReverseTransaction(ctx, "deposit:1", "reorg")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if posted {
+        t.Errorf("second reversal was posted")
+    }
+    if second.ID != first.ID {
+        t.Errorf("second reversal returned transaction %d, want %d", second.ID, first.ID)
+    }
+
+    original, err := ledger.GetTransactionByReference(ctx, "deposit:1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    if original.Status != StatusReversed {
+        t.Errorf("original status = %s, want %s", original.Status, StatusReversed)
+    }
+    assertBalance(t, ledger, asset, "0")
+    assertBalance(t, ledger, liability, "0")
+}
+
+func TestReverseTransactionOfUnknownReference(t *testing.T) {
+    ledger := newTestLedger(t)
+    if _, _, err := ledger.ReverseTransaction(context.Background(), "deposit:missing", "reorg"); err == nil {
+        t.Errorf("reversing an unknown reference succeeded")
+    }
+}
+
+func TestPostTransactionRejectsUnbalancedEntries(t *testing.T) {
+    ledger := newTestLedger(t)
+    asset, liability := newTestAccounts(t, ledger)
+
+    one := mustDecimal(t, "1")
+    two := mustDecimal(t, "2")
+    tests := []struct {
+        name    string
+        entries []JournalEntry
+    }{
+        {"no entries", nil},
+        {"single entry", []JournalEntry{{AccountID: asset.ID, Debit: one}}},
+        {"debits exceed credits", []JournalEntry{{AccountID: asset.ID, Debit: two}, {AccountID: liability.ID, Credit: one}}},
+        {"negative entries", []JournalEntry{{AccountID: asset.ID, Debit: one.Neg()}, {AccountID: liability.ID, Credit: one.Neg()}}},
+    }
+    for _, tt := range tests {
+        _, posted, err := ledger.PostTransaction(context.Background(), Transaction{
+            ReferenceID: "unbalanced:" + tt.name,
+            AccountID:   liability.ID,
+            Type:        TypeAdjustment,
+            Amount:      one,
+            Currency:    "ETH",
+        }, tt.entries)
+        if err == nil || posted {
+            t.Errorf("%s: posted = %v, err = %v, want an error", tt.name, posted, err)
+        }
+    }
+
+    assertBalance(t, ledger, asset, "0")
+    assertBalance(t, ledger, liability, "0")
+}
+
+func TestFindOrCreateAccountReturnsOneRow(t *testing.T) {
+    ledger := newTestLedger(t)
+    ctx := context.Background()
+    userID := uint(7)
+    otherUserID := uint(8)
+
+    tests := []struct {
+        name    string
+        a, b    Account
+        sameRow bool
+    }{
+        {"same platform account", Account{Name: "fees", Type: AccountTypeRevenue, Currency: "ETH"}, Account{Name: "fees", Type: AccountTypeRevenue, Currency: "ETH"}, true},
+        {"same user account", Account{UserID: &userID, Name: "wallet", Type: AccountTypeLiability, Currency: "ETH"}, Account{UserID: &userID, Name: "wallet", Type: AccountTypeLiability, Currency: "ETH"}, true},
+        {"platform and user", Account{Name: "spot", Type: AccountTypeLiability, Currency: "ETH"}, Account{UserID: &userID, Name: "spot", Type: AccountTypeLiability, Currency: "ETH"}, false},
+        {"different users", Account{UserID: &userID, Name: "margin", Type: AccountTypeLiability, Currency: "ETH"}, Account{UserID: &otherUserID, Name: "margin", Type: AccountTypeLiability, Currency: "ETH"}, false},
+        {"different currencies", Account{Name: "reserve", Type: AccountTypeAsset, Currency: "ETH"}, Account{Name: "reserve", Type: AccountTypeAsset, Currency: "BTC"}, false},
+    }
+    for _, tt := range tests {
+        a, err := ledger.FindOrCreateAccount(ctx, tt.a)
+        if err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+        b, err := ledger.FindOrCreateAccount(ctx, tt.b)
+        if err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+        if (a.ID == b.ID) != tt.sameRow {
+            t.Errorf("%s: got accounts %d and %d, same row = %v", tt.name, a.ID, b.ID, tt.sameRow)
+        }
+    }
+}
+
+// mustDecimal parses a decimal or fails the test
+func mustDecimal(t *testing.T, s string) units.Decimal {
+    t.Helper()
+
+    d, err := units.ParseDecimal(s)
+    if err != nil {
+        t.Fatalf("ParseDecimal(%q) error = %v", s, err)
+    }
+    return d
+}
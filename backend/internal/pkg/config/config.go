@@ -14,6 +14,14 @@ type Config struct {
     JWTSecret   string
     StripeKey   string
     Environment string
+
+    // ChainRegistryPath points to a JSON file with extra or overriding chain entries
+    ChainRegistryPath string
+
+    // KMSMasterKey encrypts secrets at rest; HDMasterSeed is the base64 KMS-encrypted
+    // seed that deposit addresses are derived from
+    KMSMasterKey string
+    HDMasterSeed string
 }
 
 // Load reads configuration from environment variables
@@ -30,6 +38,11 @@ func Load() *Config {
         JWTSecret:   getEnv("JWT_SECRET", ""),
         StripeKey:   getEnv("STRIPE_KEY", ""),
         Environment: getEnv("ENVIRONMENT", "development"),
+
+        ChainRegistryPath: getEnv("CHAIN_REGISTRY_PATH", ""),
+
+        KMSMasterKey: getEnv("KMS_MASTER_KEY", ""),
+        HDMasterSeed: getEnv("HD_MASTER_SEED", ""),
     }
 }
 
@@ -1,7 +1,9 @@
 package database
 
 import (
+    "fmt"
     "log"
+    "strings"
 
     "github.com/blockchain-dapp/backend/internal/accounting"
     "github.com/blockchain-dapp/backend/internal/admin"
@@ -10,12 +12,16 @@ import (
     "github.com/blockchain-dapp/backend/internal/kyc"
     "github.com/blockchain-dapp/backend/internal/payments"
     "github.com/blockchain-dapp/backend/internal/wallet"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
 
     "gorm.io/gorm"
 )
 
 // Migrate runs the database migrations
 func Migrate(db *gorm.DB) {
+    // Amounts used to be float display values; convert them to integer base units first
+    convertLegacyAmounts(db)
+
     // Run migrations for all models
     models := []interface{}{
         // Auth models
@@ -27,6 +33,12 @@ func Migrate(db *gorm.DB) {
         &wallet.Wallet{},
         &wallet.Transaction{},
         &wallet.CustodialWallet{},
+        &wallet.TokenBalance{},
+        &wallet.DerivationCursor{},
+        &wallet.BlockCursor{},
+        &wallet.WatchedBlock{},
+        &blockchain.NonceCursor{},
+        &blockchain.NonceReservation{},
         
         // Payment models
         &payments.PaymentRecord{},
@@ -43,7 +55,7 @@ func Migrate(db *gorm.DB) {
         &kyc.Document{},
         &kyc.Verification{},
         
-        // Accounting models
+        // Accounting models; their float amount columns become exact NUMERIC display values in place
         &accounting.Account{},
         &accounting.Transaction{},
         &accounting.JournalEntry{},
@@ -61,5 +73,133 @@ func Migrate(db *gorm.DB) {
         }
     }
 
+    // Wallet addresses used to be unique across chains; HD derivation gives a user the same
+    // address on every EVM network, so uniqueness is now per address and chain
+    if db.Migrator().HasIndex(&wallet.Wallet{}, "idx_wallets_address") {
+        if err := db.Migrator().DropIndex(&wallet.Wallet{}, "idx_wallets_address"); err != nil {
+            log.Fatalf("Failed to drop legacy wallet address index: %v", err)
+        }
+    }
+
+    // Transaction hashes used to be unique; one transaction can now carry several token
+    // transfers, so uniqueness is per chain, hash, token and log index
+    if db.Migrator().HasIndex(&wallet.Transaction{}, "idx_transactions_tx_hash") {
+        if err := db.Migrator().DropIndex(&wallet.Transaction{}, "idx_transactions_tx_hash"); err != nil {
+            log.Fatalf("Failed to drop legacy transaction hash index: %v", err)
+        }
+    }
+
+    // The transfer index used to cover every transaction, so withdrawals collided with deposits of
+    // the same hash; it is replaced by separate partial indexes for deposits and withdrawals
+    if db.Migrator().HasIndex(&wallet.Transaction{}, "idx_transaction_transfer") {
+        if err := db.Migrator().DropIndex(&wallet.Transaction{}, "idx_transaction_transfer"); err != nil {
+            log.Fatalf("Failed to drop legacy transaction transfer index: %v", err)
+        }
+    }
+
     log.Println("Database migration completed successfully")
-}
\ No newline at end of file
+}
+
+// legacyAmountColumns lists the amount columns that used to hold float display values: the
+// table, the amount column, the column naming the row's chain and, for amounts that may be of a
+// token, the column naming the token contract
+var legacyAmountColumns = []struct {
+    table, amount, chain, token string
+}{
+    {"wallets", "balance", "chain", ""},
+    {"custodial_wallets", "balance", "chain", ""},
+    {"transactions", "amount", "chain", "token_contract"},
+    {"transactions", "fee", "chain", ""},
+}
+
+// convertLegacyAmounts rewrites float amount columns as NUMERIC(78,0) base units, scaling
+// each row by the decimals of its chain's native coin. Columns already converted are skipped.
+// A row that cannot be scaled that way, on a chain missing from the registry or holding a
+// token amount whose decimals were never recorded, aborts the migration before anything is
+// changed, naming the offending values.
+func convertLegacyAmounts(db *gorm.DB) {
+    for _, column := range legacyAmountColumns {
+        var dataType string
+        err := db.Raw(
+            "SELECT data_type FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ? AND column_name = ?",
+            column.table, column.amount,
+        ).Scan(&dataType).Error
+        if err != nil {
+            log.Fatalf("Failed to inspect %s.%s: %v", column.table, column.amount, err)
+        }
+        if dataType != "double precision" && dataType != "real" {
+            continue
+        }
+
+        var unknown []string
+        err = db.Raw(fmt.Sprintf(
+            "SELECT DISTINCT %s FROM %s WHERE %s <> 0 AND lower(%s) NOT IN (%s) ORDER BY 1",
+            column.chain, column.table, column.amount, column.chain, knownChainsSQL(),
+        )).Scan(&unknown).Error
+        if err != nil {
+            log.Fatalf("Failed to check chains of %s.%s: %v", column.table, column.amount, err)
+        }
+        if len(unknown) > 0 {
+            log.Fatalf("Cannot convert %s.%s to base units: rows on chains missing from the chain registry: %s", column.table, column.amount, strings.Join(unknown, ", "))
+        }
+
+        if column.token != "" && db.Migrator().HasColumn(column.table, column.token) {
+            var tokens []string
+            err = db.Raw(fmt.Sprintf(
+                "SELECT DISTINCT %s || ':' || %s FROM %s WHERE %s <> 0 AND coalesce(%s, '') <> '' ORDER BY 1",
+                column.chain, column.token, column.table, column.amount, column.token,
+            )).Scan(&tokens).Error
+            if err != nil {
+                log.Fatalf("Failed to check tokens of %s.%s: %v", column.table, column.amount, err)
+            }
+            if len(tokens) > 0 {
+                log.Fatalf("Cannot convert %s.%s to base units: token amounts whose decimals were never recorded, on %s", column.table, column.amount, strings.Join(tokens, ", "))
+            }
+        }
+
+        statement := fmt.Sprintf(
+            "ALTER TABLE %s ALTER COLUMN %s TYPE numeric(78,0) USING round(%s::numeric * power(10::numeric, %s))",
+            column.table, column.amount, column.amount, decimalsByChainSQL(column.chain),
+        )
+        if err := db.Exec(statement).Error; err != nil {
+            log.Fatalf("Failed to convert %s.%s to base units: %v", column.table, column.amount, err)
+        }
+
+        log.Printf("Converted %s.%s to base units", column.table, column.amount)
+    }
+}
+
+// registryChainNames returns every chain name and alias in the registry, lowercased
+func registryChainNames() []string {
+    var names []string
+    for _, config := range blockchain.Chains().Chains() {
+        for _, name := range append([]string{config.Name}, config.Aliases...) {
+            names = append(names, strings.ToLower(name))
+        }
+    }
+    return names
+}
+
+// knownChainsSQL returns the registry's chain names and aliases as a list of SQL literals
+func knownChainsSQL() string {
+    names := registryChainNames()
+    for i, name := range names {
+        names[i] = "'" + strings.ReplaceAll(name, "'", "''") + "'"
+    }
+    return strings.Join(names, ", ")
+}
+
+// decimalsByChainSQL returns a SQL CASE expression mapping a chain column, including
+// registry aliases, to the decimals of the chain's native coin. Other chains map to NULL,
+// which fails the conversion rather than scaling their rows by a guess.
+func decimalsByChainSQL(column string) string {
+    var b strings.Builder
+    fmt.Fprintf(&b, "CASE lower(%s)", column)
+    for _, config := range blockchain.Chains().Chains() {
+        for _, name := range append([]string{config.Name}, config.Aliases...) {
+            fmt.Fprintf(&b, " WHEN '%s' THEN %d", strings.ReplaceAll(strings.ToLower(name), "'", "''"), config.Decimals)
+        }
+    }
+    b.WriteString(" END")
+    return b.String()
+}
@@ -0,0 +1,202 @@
+package units
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "math/big"
+    "strings"
+)
+
+// Amount is an exact integer quantity of an asset's smallest unit: wei, satoshi, lamport,
+// sun, or a token's base unit. The zero value is zero. Amounts are stored in Postgres as
+// NUMERIC(78,0), wide enough for any uint256, and travel through JSON as decimal strings.
+type Amount struct {
+    v *big.Int
+}
+
+// NewAmount creates an amount from a big integer of base units. The integer is copied.
+func NewAmount(v *big.Int) Amount {
+    if v == nil {
+        return Amount{}
+    }
+    return Amount{v: new(big.Int).Set(v)}
+}
+
+// FromInt64 creates an amount from an int64 of base units
+func FromInt64(v int64) Amount {
+    return Amount{v: big.NewInt(v)}
+}
+
+// FromUint64 creates an amount from a uint64 of base units
+func FromUint64(v uint64) Amount {
+    return Amount{v: new(big.Int).SetUint64(v)}
+}
+
+// ParseBaseUnits parses an integer string of base units
+func ParseBaseUnits(s string) (Amount, error) {
+    v, ok := new(big.Int).SetString(strings.TrimSpace(s), 10)
+    if !ok {
+        return Amount{}, fmt.Errorf("invalid base unit amount %q", s)
+    }
+    return Amount{v: v}, nil
+}
+
+// Parse parses a display amount such as "1.5" into base units for the given decimals.
+// It fails rather than rounds when the amount has more fractional digits than the asset,
+// and rejects empty, signed and negative amounts.
+func Parse(display string, decimals uint8) (Amount, error) {
+    s := strings.TrimSpace(display)
+    if strings.HasPrefix(s, "-") {
+        return Amount{}, fmt.Errorf("amount %q is negative", display)
+    }
+
+    whole, frac, _ := strings.Cut(s, ".")
+    if whole+frac == "" || !isDigits(whole) || !isDigits(frac) {
+        return Amount{}, fmt.Errorf("invalid amount %q", display)
+    }
+    if len(frac) > int(decimals) {
+        return Amount{}, fmt.Errorf("amount %q has more than %d decimal places", display, decimals)
+    }
+    frac += strings.Repeat("0", int(decimals)-len(frac))
+
+    v, _ := new(big.Int).SetString("0"+whole+frac, 10)
+    return Amount{v: v}, nil
+}
+
+// BigInt returns a copy of the amount as a big integer
+func (a Amount) BigInt() *big.Int {
+    if a.v == nil {
+        return new(big.Int)
+    }
+    return new(big.Int).Set(a.v)
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the amount
+func (a Amount) Sign() int {
+    if a.v == nil {
+        return 0
+    }
+    return a.v.Sign()
+}
+
+// IsZero reports whether the amount is zero
+func (a Amount) IsZero() bool {
+    return a.Sign() == 0
+}
+
+// Cmp compares two amounts and returns -1, 0 or +1
+func (a Amount) Cmp(b Amount) int {
+    return a.BigInt().Cmp(b.BigInt())
+}
+
+// Add returns a + b
+func (a Amount) Add(b Amount) Amount {
+    return Amount{v: new(big.Int).Add(a.BigInt(), b.BigInt())}
+}
+
+// Sub returns a - b
+func (a Amount) Sub(b Amount) Amount {
+    return Amount{v: new(big.Int).Sub(a.BigInt(), b.BigInt())}
+}
+
+// Mul returns the amount multiplied by an integer, for example gas price times gas
+func (a Amount) Mul(n uint64) Amount {
+    return Amount{v: new(big.Int).Mul(a.BigInt(), new(big.Int).SetUint64(n))}
+}
+
+// String returns the amount in base units
+func (a Amount) String() string {
+    return a.BigInt().String()
+}
+
+// Format returns the amount in display units for the given decimals, without trailing zeros
+func (a Amount) Format(decimals uint8) string {
+    v := a.BigInt()
+    negative := v.Sign() < 0
+    digits := v.Abs(v).String()
+
+    if decimals > 0 {
+        if len(digits) <= int(decimals) {
+            digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+        }
+        point := len(digits) - int(decimals)
+        whole, frac := digits[:point], strings.TrimRight(digits[point:], "0")
+        digits = whole
+        if frac != "" {
+            digits += "." + frac
+        }
+    }
+
+    if negative {
+        return "-" + digits
+    }
+    return digits
+}
+
+// Float64 returns an approximate display value, for metrics and logs only
+func (a Amount) Float64(decimals uint8) float64 {
+    scale := new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+    f, _ := new(big.Float).Quo(new(big.Float).SetInt(a.BigInt()), scale).Float64()
+    return f
+}
+
+// GormDataType sets the column type used by migrations
+func (Amount) GormDataType() string {
+    return "numeric(78,0)"
+}
+
+// Value implements driver.Valuer
+func (a Amount) Value() (driver.Value, error) {
+    return a.String(), nil
+}
+
+// Scan implements sql.Scanner
+func (a *Amount) Scan(src interface{}) error {
+    var s string
+    switch v := src.(type) {
+    case nil:
+        *a = Amount{}
+        return nil
+    case []byte:
+        s = string(v)
+    case string:
+        s = v
+    case int64:
+        *a = FromInt64(v)
+        return nil
+    default:
+        return fmt.Errorf("cannot scan %T into an amount", src)
+    }
+
+    parsed, err := ParseBaseUnits(s)
+    if err != nil {
+        return err
+    }
+    *a = parsed
+    return nil
+}
+
+// MarshalJSON encodes the amount as a string of base units, since JSON numbers lose precision
+func (a Amount) MarshalJSON() ([]byte, error) {
+    return json.Marshal(a.String())
+}
+
+// UnmarshalJSON accepts a string or an integer number of base units
+func (a *Amount) UnmarshalJSON(data []byte) error {
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        var n json.Number
+        if err := json.Unmarshal(data, &n); err != nil {
+            return fmt.Errorf("amount must be a string or integer of base units")
+        }
+        s = n.String()
+    }
+
+    parsed, err := ParseBaseUnits(s)
+    if err != nil {
+        return err
+    }
+    *a = parsed
+    return nil
+}
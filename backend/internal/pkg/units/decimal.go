@@ -0,0 +1,178 @@
+package units
+
+import (
+    "database/sql/driver"
+    "encoding/json"
+    "fmt"
+    "math/big"
+    "strconv"
+    "strings"
+)
+
+// Decimal is an exact quantity in display units, such as 1.5 ETH or 20.25 USD, for records that
+// hold amounts of assets with different decimals side by side, like ledger entries. The zero
+// value is zero. Decimals are stored in Postgres as an unconstrained NUMERIC and travel through
+// JSON as decimal strings.
+type Decimal struct {
+    v     *big.Int // the value times 10^scale
+    scale int      // digits after the decimal point
+}
+
+// Decimal converts the amount to display units for the given decimals, exactly
+func (a Amount) Decimal(decimals uint8) Decimal {
+    return Decimal{v: a.BigInt(), scale: int(decimals)}
+}
+
+// ParseDecimal parses a decimal string such as "1.5" or "-0.001"
+func ParseDecimal(s string) (Decimal, error) {
+    digits := strings.TrimSpace(s)
+    negative := strings.HasPrefix(digits, "-")
+    digits = strings.TrimPrefix(digits, "-")
+
+    whole, frac, _ := strings.Cut(digits, ".")
+    if whole+frac == "" || !isDigits(whole) || !isDigits(frac) {
+        return Decimal{}, fmt.Errorf("invalid decimal %q", s)
+    }
+    if len(frac) > 255 {
+        return Decimal{}, fmt.Errorf("decimal %q has too many decimal places", s)
+    }
+
+    v, _ := new(big.Int).SetString("0"+whole+frac, 10)
+    if negative {
+        v.Neg(v)
+    }
+    return Decimal{v: v, scale: len(frac)}, nil
+}
+
+// isDigits reports whether a string holds nothing but ASCII digits
+func isDigits(s string) bool {
+    for _, c := range s {
+        if c < '0' || c > '9' {
+            return false
+        }
+    }
+    return true
+}
+
+// value returns the unscaled value, which is zero for the zero Decimal
+func (d Decimal) value() *big.Int {
+    if d.v == nil {
+        return new(big.Int)
+    }
+    return d.v
+}
+
+// rescale returns the unscaled value at a larger scale
+func (d Decimal) rescale(scale int) *big.Int {
+    factor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale-d.scale)), nil)
+    return factor.Mul(factor, d.value())
+}
+
+// align returns the unscaled values of two decimals at their common scale
+func align(a, b Decimal) (*big.Int, *big.Int, int) {
+    scale := a.scale
+    if b.scale > scale {
+        scale = b.scale
+    }
+    return a.rescale(scale), b.rescale(scale), scale
+}
+
+// Add returns d + e
+func (d Decimal) Add(e Decimal) Decimal {
+    x, y, scale := align(d, e)
+    return Decimal{v: x.Add(x, y), scale: scale}
+}
+
+// Sub returns d - e
+func (d Decimal) Sub(e Decimal) Decimal {
+    x, y, scale := align(d, e)
+    return Decimal{v: x.Sub(x, y), scale: scale}
+}
+
+// Neg returns -d
+func (d Decimal) Neg() Decimal {
+    return Decimal{v: new(big.Int).Neg(d.value()), scale: d.scale}
+}
+
+// Cmp compares two decimals exactly and returns -1, 0 or +1
+func (d Decimal) Cmp(e Decimal) int {
+    x, y, _ := align(d, e)
+    return x.Cmp(y)
+}
+
+// Sign returns -1, 0 or +1 depending on the sign of the decimal
+func (d Decimal) Sign() int {
+    return d.value().Sign()
+}
+
+// IsZero reports whether the decimal is zero
+func (d Decimal) IsZero() bool {
+    return d.Sign() == 0
+}
+
+// String returns the decimal without trailing fractional zeros
+func (d Decimal) String() string {
+    return Amount{v: d.value()}.Format(uint8(d.scale))
+}
+
+// GormDataType sets the column type used by migrations
+func (Decimal) GormDataType() string {
+    return "numeric"
+}
+
+// Value implements driver.Valuer
+func (d Decimal) Value() (driver.Value, error) {
+    return d.String(), nil
+}
+
+// Scan implements sql.Scanner
+func (d *Decimal) Scan(src interface{}) error {
+    var s string
+    switch v := src.(type) {
+    case nil:
+        *d = Decimal{}
+        return nil
+    case []byte:
+        s = string(v)
+    case string:
+        s = v
+    case int64:
+        s = strconv.FormatInt(v, 10)
+    case float64:
+        // Columns not yet migrated from double precision
+        s = strconv.FormatFloat(v, 'f', -1, 64)
+    default:
+        return fmt.Errorf("cannot scan %T into a decimal", src)
+    }
+
+    parsed, err := ParseDecimal(s)
+    if err != nil {
+        return err
+    }
+    *d = parsed
+    return nil
+}
+
+// MarshalJSON encodes the decimal as a string, since JSON numbers lose precision
+func (d Decimal) MarshalJSON() ([]byte, error) {
+    return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts a decimal string or number
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        var n json.Number
+        if err := json.Unmarshal(data, &n); err != nil {
+            return fmt.Errorf("decimal must be a string or number")
+        }
+        s = n.String()
+    }
+
+    parsed, err := ParseDecimal(s)
+    if err != nil {
+        return err
+    }
+    *d = parsed
+    return nil
+}
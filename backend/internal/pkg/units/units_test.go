@@ -0,0 +1,121 @@
+package units
+
+import "testing"
+
+func TestParse(t *testing.T) {
+    tests := []struct {
+        display  string
+        decimals uint8
+        want     string
+        wantErr  bool
+    }{
+        {"1.5", 18, "1500000000000000000", false},
+        {"0.00000001", 8, "1", false},
+        {" 42 ", 6, "42000000", false},
+        {".5", 1, "5", false},
+        {"5.", 2, "500", false},
+        {"7", 0, "7", false},
+        {"115792089237316195423570985008687907853269984665640564039457.584007913129639935", 18, "115792089237316195423570985008687907853269984665640564039457584007913129639935", false},
+        {"0.000000001", 8, "", true},
+        {"1.5", 0, "", true},
+        {"", 18, "", true},
+        {".", 18, "", true},
+        {"-1", 18, "", true},
+        {"+1", 18, "", true},
+        {"1e18", 18, "", true},
+        {"1,5", 18, "", true},
+        {"1.2.3", 18, "", true},
+    }
+    for _, tt := range tests {
+        got, err := Parse(tt.display, tt.decimals)
+        if tt.wantErr {
+            if err == nil {
+                t.Errorf("Parse(%q, %d) = %s, want an error", tt.display, tt.decimals, got)
+            }
+            continue
+        }
+        if err != nil {
+            t.Errorf("Parse(%q, %d) error = %v", tt.display, tt.decimals, err)
+            continue
+        }
+        if got.String() != tt.want {
+            t.Errorf("Parse(%q, %d) = %s, want %s", tt.display, tt.decimals, got, tt.want)
+        }
+    }
+}
+
+func TestFormat(t *testing.T) {
+    tests := []struct {
+        amount   Amount
+        decimals uint8
+        want     string
+    }{
+        {FromInt64(1500000000000000000), 18, "1.5"},
+        {FromInt64(1), 8, "0.00000001"},
+        {FromInt64(100000000), 8, "1"},
+        {FromInt64(123), 0, "123"},
+        {FromInt64(-25), 1, "-2.5"},
+        {Amount{}, 18, "0"},
+    }
+    for _, tt := range tests {
+        if got := tt.amount.Format(tt.decimals); got != tt.want {
+            t.Errorf("%s.Format(%d) = %q, want %q", tt.amount, tt.decimals, got, tt.want)
+        }
+    }
+}
+
+func TestParseFormatRoundTrip(t *testing.T) {
+    for _, display := range []string{"0", "1", "0.1", "12.345678", "999999999999.999999"} {
+        amount, err := Parse(display, 6)
+        if err != nil {
+            t.Fatalf("Parse(%q) error = %v", display, err)
+        }
+        if got := amount.Format(6); got != display {
+            t.Errorf("Format(Parse(%q)) = %q", display, got)
+        }
+    }
+}
+
+func TestDecimal(t *testing.T) {
+    tests := []struct {
+        name string
+        got  Decimal
+        want string
+    }{
+        {"amount in display units", FromInt64(1500000).Decimal(6), "1.5"},
+        {"add across scales", mustDecimal(t, "0.1").Add(mustDecimal(t, "0.25")), "0.35"},
+        {"sub below zero", mustDecimal(t, "1").Sub(mustDecimal(t, "1.001")), "-0.001"},
+        {"neg", mustDecimal(t, "2.5").Neg(), "-2.5"},
+        {"sums exactly", mustDecimal(t, "0.1").Add(mustDecimal(t, "0.2")), "0.3"},
+        {"zero value", Decimal{}, "0"},
+    }
+    for _, tt := range tests {
+        if s := tt.got.String(); s != tt.want {
+            t.Errorf("%s: got %s, want %s", tt.name, s, tt.want)
+        }
+    }
+
+    if mustDecimal(t, "1.50").Cmp(mustDecimal(t, "1.5")) != 0 {
+        t.Errorf("1.50 and 1.5 compare unequal")
+    }
+    if mustDecimal(t, "0.30000000000000001").Cmp(mustDecimal(t, "0.3")) <= 0 {
+        t.Errorf("0.30000000000000001 does not compare above 0.3")
+    }
+
+    for _, invalid := range []string{"", "-", "abc", "1.2.3", "1e5"} {
+        if _, err := ParseDecimal(invalid); err == nil {
+            t.Errorf("ParseDecimal(%q) succeeded, want an error", invalid)
+        }
+    }
+}
+
+// mustDecimal parses a decimal or fails the test
+func mustDecimal(t *testing.T, s string) Decimal {
+    t.Helper()
+
+    d, err := ParseDecimal(s)
+    if err != nil {
+        t.Fatalf("ParseDecimal(%q) error = %v", s, err)
+    }
+    return d
+}
@@ -0,0 +1,159 @@
+package blockchain
+
+import (
+    "errors"
+    "fmt"
+    "strings"
+
+    "github.com/blocto/solana-go-sdk/common"
+    "github.com/btcsuite/btcd/btcutil"
+    "github.com/btcsuite/btcd/chaincfg"
+    ethcommon "github.com/ethereum/go-ethereum/common"
+    "github.com/fbsobreira/gotron-sdk/pkg/address"
+    "github.com/mr-tron/base58"
+)
+
+// ErrInvalidAddress is returned when an address is not valid for a chain. The wrapped
+// message explains the reason in terms a user can act on.
+var ErrInvalidAddress = errors.New("invalid address")
+
+// invalidAddress builds an ErrInvalidAddress with a reason
+func invalidAddress(format string, args ...interface{}) error {
+    return fmt.Errorf("%w: %s", ErrInvalidAddress, fmt.Sprintf(format, args...))
+}
+
+// ValidateAddress checks that an address is well formed for a chain
+func ValidateAddress(config ChainConfig, addr string) error {
+    if strings.TrimSpace(addr) != addr {
+        return invalidAddress("address must not contain spaces")
+    }
+    if addr == "" {
+        return invalidAddress("address is required")
+    }
+
+    switch config.Family {
+    case FamilyEVM:
+        return validateEVMAddress(addr)
+    case FamilyBitcoin:
+        network := &chaincfg.MainNetParams
+        if config.Testnet {
+            network = &chaincfg.TestNet3Params
+        }
+        return validateBitcoinAddress(addr, network)
+    case FamilySolana:
+        return validateSolanaAddress(addr)
+    case FamilyTron:
+        return validateTronAddress(addr)
+    default:
+        return fmt.Errorf("no address format for chain family %s", config.Family)
+    }
+}
+
+// NormalizeAddress returns the canonical form of an address, so that every spelling of the
+// same address compares equal: EVM addresses take their EIP-55 checksum, and Bitcoin bech32
+// addresses, which are case-insensitive, are lowercased. Other formats are case-sensitive and
+// returned as they are.
+func NormalizeAddress(config ChainConfig, addr string) string {
+    switch config.Family {
+    case FamilyEVM:
+        if ethcommon.IsHexAddress(addr) {
+            return ethcommon.HexToAddress(addr).Hex()
+        }
+    case FamilyBitcoin:
+        lower := strings.ToLower(addr)
+        if strings.HasPrefix(lower, "bc1") || strings.HasPrefix(lower, "tb1") {
+            return lower
+        }
+    }
+    return addr
+}
+
+// validateEVMAddress checks a 0x-prefixed hex address and its EIP-55 checksum when it is mixed case
+func validateEVMAddress(addr string) error {
+    if !strings.HasPrefix(addr, "0x") && !strings.HasPrefix(addr, "0X") {
+        return invalidAddress("address must start with 0x")
+    }
+    if !ethcommon.IsHexAddress(addr) {
+        return invalidAddress("address must be 0x followed by 40 hexadecimal characters")
+    }
+
+    // All-lowercase and all-uppercase addresses carry no checksum
+    digits := addr[2:]
+    if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) {
+        if ethcommon.HexToAddress(addr).Hex() != addr {
+            return invalidAddress("checksum mismatch, the address was probably mistyped")
+        }
+    }
+
+    if ethcommon.HexToAddress(addr) == (ethcommon.Address{}) {
+        return invalidAddress("the zero address cannot receive funds")
+    }
+
+    return nil
+}
+
+// validateBitcoinAddress checks a base58 (P2PKH, P2SH) or bech32/bech32m (SegWit, Taproot) address
+// and that it belongs to the expected network
+func validateBitcoinAddress(addr string, network *chaincfg.Params) error {
+    decoded, err := btcutil.DecodeAddress(addr, network)
+    if err == nil && decoded.IsForNet(network) {
+        return nil
+    }
+
+    // Tell the user when the address is fine but for the other network
+    other := &chaincfg.TestNet3Params
+    if network.Net != chaincfg.MainNetParams.Net {
+        other = &chaincfg.MainNetParams
+    }
+    if decoded, otherErr := btcutil.DecodeAddress(addr, other); otherErr == nil && decoded.IsForNet(other) {
+        return invalidAddress("address is for Bitcoin %s, not %s", other.Name, network.Name)
+    }
+
+    if err != nil {
+        return invalidAddress("not a valid Bitcoin address (%v)", err)
+    }
+    return invalidAddress("address is not for Bitcoin %s", network.Name)
+}
+
+// validateSolanaAddress checks a base58 ed25519 public key that lies on the curve. Off-curve
+// addresses are program-derived and no private key can spend from them.
+func validateSolanaAddress(addr string) error {
+    key, err := parseSolanaAddress(addr)
+    if err != nil {
+        return err
+    }
+    if !common.IsOnCurve(key) {
+        return invalidAddress("address is a program-derived account, not a wallet")
+    }
+    return nil
+}
+
+// parseSolanaAddress decodes a base58 Solana address without the on-curve check,
+// for mints and token accounts
+func parseSolanaAddress(addr string) (common.PublicKey, error) {
+    decoded, err := base58.Decode(addr)
+    if err != nil {
+        return common.PublicKey{}, invalidAddress("not valid base58")
+    }
+    if len(decoded) != common.PublicKeyLength {
+        return common.PublicKey{}, invalidAddress("Solana addresses are 32 bytes, got %d", len(decoded))
+    }
+    return common.PublicKeyFromBytes(decoded), nil
+}
+
+// validateTronAddress checks a base58check address with the 0x41 mainnet prefix (T...)
+func validateTronAddress(addr string) error {
+    if !strings.HasPrefix(addr, "T") {
+        return invalidAddress("Tron addresses start with T")
+    }
+
+    decoded, err := address.Base58ToAddress(addr)
+    if err != nil {
+        return invalidAddress("checksum mismatch, the address was probably mistyped")
+    }
+    if len(decoded) != address.AddressLength || decoded[0] != address.TronBytePrefix {
+        return invalidAddress("not a Tron mainnet address")
+    }
+
+    return nil
+}
@@ -1,38 +1,101 @@
 package blockchain
 
 import (
+    "bytes"
     "context"
-    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
     "fmt"
-    "math/big"
+    "net/http"
+    "sort"
+    "time"
 
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/btcsuite/btcd/btcec/v2"
     "github.com/btcsuite/btcd/btcutil"
     "github.com/btcsuite/btcd/chaincfg"
+    "github.com/btcsuite/btcd/chaincfg/chainhash"
     "github.com/btcsuite/btcd/txscript"
     "github.com/btcsuite/btcd/wire"
 )
 
+// btcDecimals is the number of decimals of BTC (1 BTC = 100,000,000 satoshis)
+const btcDecimals = 8
+
+// Virtual sizes of a one-input, two-output transaction spending from a legacy or a segwit address
+const (
+    legacyTxVsize = 226
+    segwitTxVsize = 141
+)
+
+// Virtual sizes of the parts of a transaction spending from a legacy or a segwit address
+const (
+    legacyTxOverhead  = 10
+    legacyInputVsize  = 148
+    legacyOutputVsize = 34
+    segwitTxOverhead  = 11
+    segwitInputVsize  = 68
+    segwitOutputVsize = 31
+)
+
+// Default mempool.space APIs used for fee rates, transaction lookups and broadcasts
+const (
+    mainnetAPIURL = "https://mempool.space/api"
+    testnetAPIURL = "https://mempool.space/testnet/api"
+)
+
 // BitcoinAdapter implements the Adapter interface for Bitcoin
 type BitcoinAdapter struct {
-    network *chaincfg.Params
+    network    *chaincfg.Params
+    endpoints  *endpointPool[string] // mempool API base URLs
+    httpClient *http.Client
 }
 
-// NewBitcoinAdapter creates a new Bitcoin adapter
-func NewBitcoinAdapter(isTestnet bool) *BitcoinAdapter {
+// NewBitcoinAdapter creates a new Bitcoin adapter that uses the healthiest of the given
+// mempool.space-compatible APIs, or the public mempool.space API when none is given
+func NewBitcoinAdapter(isTestnet bool, apiURLs ...string) *BitcoinAdapter {
     network := &chaincfg.MainNetParams
+    defaultURL := mainnetAPIURL
     if isTestnet {
         network = &chaincfg.TestNet3Params
+        defaultURL = testnetAPIURL
     }
-    
-    return &BitcoinAdapter{
-        network: network,
+    if len(apiURLs) == 0 {
+        apiURLs = []string{defaultURL}
     }
+
+    b := &BitcoinAdapter{
+        network:    network,
+        httpClient: &http.Client{Timeout: 10 * time.Second},
+    }
+    b.endpoints = b.newEndpointPool(apiURLs)
+    return b
+}
+
+// newEndpointPool creates a pool of mempool APIs, health-checked by their chain tip
+func (b *BitcoinAdapter) newEndpointPool(apiURLs []string) *endpointPool[string] {
+    probe := func(ctx context.Context, apiURL string) (uint64, error) {
+        var height uint64
+        err := b.fetchJSON(ctx, apiURL, "/blocks/tip/height", &height)
+        return height, err
+    }
+    return newEndpointPool("bitcoin", apiURLs, apiURLs, probe)
+}
+
+// SetAPIURL points the adapter at another mempool.space-compatible API, such as a self-hosted instance
+func (b *BitcoinAdapter) SetAPIURL(url string) {
+    b.endpoints = b.newEndpointPool([]string{url})
+}
+
+// Endpoints returns the health of the adapter's mempool APIs
+func (b *BitcoinAdapter) Endpoints() []EndpointStatus {
+    return b.endpoints.statuses()
 }
 
 // CreateWallet creates a new Bitcoin wallet
 func (b *BitcoinAdapter) CreateWallet(ctx context.Context) (*Wallet, error) {
     // Generate a new private key
-    privKey, err := btcutil.NewPrivateKey(b.network)
+    privKey, err := btcec.NewPrivateKey()
     if err != nil {
         return nil, fmt.Errorf("failed to generate private key: %w", err)
     }
@@ -50,94 +113,352 @@ func (b *BitcoinAdapter) CreateWallet(ctx context.Context) (*Wallet, error) {
         Address:    address.EncodeAddress(),
         PublicKey:  fmt.Sprintf("%x", pubKey.SerializeCompressed()),
         PrivateKey: fmt.Sprintf("%x", privKey.Serialize()),
-        Balance:    0,
+        Balance:    units.Amount{},
     }, nil
 }
 
+// WalletFromPrivateKey builds the Bitcoin wallet controlled by a raw secp256k1 private key
+func (b *BitcoinAdapter) WalletFromPrivateKey(privateKey []byte) (*Wallet, error) {
+    if len(privateKey) != btcec.PrivKeyBytesLen {
+        return nil, fmt.Errorf("invalid private key length %d", len(privateKey))
+    }
+
+    privKey, pubKey := btcec.PrivKeyFromBytes(privateKey)
+
+    address, err := btcutil.NewAddressPubKey(pubKey.SerializeCompressed(), b.network)
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate address: %w", err)
+    }
+
+    return &Wallet{
+        Address:    address.EncodeAddress(),
+        PublicKey:  fmt.Sprintf("%x", pubKey.SerializeCompressed()),
+        PrivateKey: fmt.Sprintf("%x", privKey.Serialize()),
+        Balance:    units.Amount{},
+    }, nil
+}
+
+// ValidateAddress checks that an address is a base58 or bech32 address for the adapter's network
+func (b *BitcoinAdapter) ValidateAddress(address string) error {
+    return validateBitcoinAddress(address, b.network)
+}
+
 // GetWallet retrieves wallet information
 func (b *BitcoinAdapter) GetWallet(ctx context.Context, address string) (*Wallet, error) {
-    // In a real implementation, this would fetch wallet details from the blockchain
-    // For now, we'll just validate the address
-    _, err := btcutil.DecodeAddress(address, b.network)
+    balance, err := b.GetBalance(ctx, address)
     if err != nil {
-        return nil, fmt.Errorf("invalid address: %w", err)
+        return nil, err
     }
 
     return &Wallet{
         Address: address,
-        Balance: 0, // Would be fetched from blockchain in real implementation
+        Balance: balance,
     }, nil
 }
 
-// GetBalance retrieves the balance of an address
-func (b *BitcoinAdapter) GetBalance(ctx context.Context, address string) (float64, error) {
-    // In a real implementation, this would fetch the balance from the blockchain
-    // For now, we'll return a random balance for demonstration
-    balance, _ := rand.Int(rand.Reader, big.NewInt(100000000)) // Up to 1 BTC in satoshis
-    return float64(balance.Int64()) / 100000000, nil
+// esploraAddress is the subset of a mempool API address summary needed for its balance
+type esploraAddress struct {
+    ChainStats struct {
+        FundedTxoSum int64 `json:"funded_txo_sum"`
+        SpentTxoSum  int64 `json:"spent_txo_sum"`
+    } `json:"chain_stats"`
+}
+
+// esploraUTXO is an unspent output of an address as returned by the mempool API
+type esploraUTXO struct {
+    Txid   string `json:"txid"`
+    Vout   uint32 `json:"vout"`
+    Value  int64  `json:"value"`
+    Status struct {
+        Confirmed bool `json:"confirmed"`
+    } `json:"status"`
 }
 
-// SendTransaction sends a Bitcoin transaction
-func (b *BitcoinAdapter) SendTransaction(ctx context.Context, from, to string, amount float64, privateKey string) (*Transaction, error) {
+// GetBalance retrieves the confirmed balance of an address in satoshis
+func (b *BitcoinAdapter) GetBalance(ctx context.Context, address string) (units.Amount, error) {
+    if _, err := btcutil.DecodeAddress(address, b.network); err != nil {
+        return units.Amount{}, fmt.Errorf("invalid address: %w", err)
+    }
+
+    var summary esploraAddress
+    if err := b.getJSON(ctx, "/address/"+address, &summary); err != nil {
+        return units.Amount{}, fmt.Errorf("failed to fetch balance: %w", err)
+    }
+
+    return units.FromInt64(summary.ChainStats.FundedTxoSum - summary.ChainStats.SpentTxoSum), nil
+}
+
+// SendTransaction spends confirmed outputs of the from address, largest first, to pay the
+// amount and the fee at the estimate's rate. Change below the dust limit goes to the miner.
+// Inputs signal replaceability so the fee can be bumped later.
+func (b *BitcoinAdapter) SendTransaction(ctx context.Context, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error) {
     // Decode the addresses
     fromAddr, err := btcutil.DecodeAddress(from, b.network)
     if err != nil {
         return nil, fmt.Errorf("invalid from address: %w", err)
     }
 
+    if err := validateBitcoinAddress(to, b.network); err != nil {
+        return nil, fmt.Errorf("invalid to address: %w", err)
+    }
     toAddr, err := btcutil.DecodeAddress(to, b.network)
     if err != nil {
         return nil, fmt.Errorf("invalid to address: %w", err)
     }
 
-    // Create a new transaction
+    privKey, err := b.parseKey(privateKey, fromAddr)
+    if err != nil {
+        return nil, err
+    }
+
+    fromScript, err := txscript.PayToAddrScript(fromAddr)
+    if err != nil {
+        return nil, fmt.Errorf("failed to build script for %s: %w", from, err)
+    }
+    toScript, err := txscript.PayToAddrScript(toAddr)
+    if err != nil {
+        return nil, fmt.Errorf("failed to build script for %s: %w", to, err)
+    }
+
+    value := amount.BigInt()
+    if !value.IsInt64() || value.Int64() < dustLimit {
+        return nil, fmt.Errorf("amount %s satoshis is below the dust limit or out of range", amount.String())
+    }
+    send := value.Int64()
+
+    if fee == nil {
+        estimates, err := b.EstimateFee(ctx, from, to, amount)
+        if err != nil {
+            return nil, err
+        }
+        standard, err := estimates.Tier(FeeStandard)
+        if err != nil {
+            return nil, err
+        }
+        fee = &standard
+    }
+    rate := int64(fee.FeeRate)
+
+    var utxos []esploraUTXO
+    if err := b.getJSON(ctx, "/address/"+from+"/utxo", &utxos); err != nil {
+        return nil, fmt.Errorf("failed to fetch unspent outputs: %w", err)
+    }
+    sort.Slice(utxos, func(i, j int) bool { return utxos[i].Value > utxos[j].Value })
+
+    overhead, inputVsize, outputVsize := int64(legacyTxOverhead), int64(legacyInputVsize), int64(legacyOutputVsize)
+    if _, ok := fromAddr.(*btcutil.AddressWitnessPubKeyHash); ok {
+        overhead, inputVsize, outputVsize = segwitTxOverhead, segwitInputVsize, segwitOutputVsize
+    }
+
     tx := wire.NewMsgTx(wire.TxVersion)
+    prevOuts := txscript.NewMultiPrevOutFetcher(make(map[wire.OutPoint]*wire.TxOut))
+    var inputTotal, txFee int64
+    for _, utxo := range utxos {
+        if !utxo.Status.Confirmed {
+            continue
+        }
 
-    // Add inputs and outputs (simplified for demonstration)
-    // In a real implementation, you would need to:
-    // 1. Fetch unspent transaction outputs (UTXOs) for the from address
-    // 2. Select appropriate UTXOs to cover the amount + fees
-    // 3. Create inputs referencing those UTXOs
-    // 4. Create outputs for the recipient and change (if any)
-    // 5. Sign the transaction with the private key
+        prevHash, err := chainhash.NewHashFromStr(utxo.Txid)
+        if err != nil {
+            return nil, fmt.Errorf("invalid output txid %s: %w", utxo.Txid, err)
+        }
+        outPoint := wire.NewOutPoint(prevHash, utxo.Vout)
+        txIn := wire.NewTxIn(outPoint, nil, nil)
+        txIn.Sequence = rbfSequence
+        tx.AddTxIn(txIn)
+        prevOuts.AddPrevOut(*outPoint, wire.NewTxOut(utxo.Value, fromScript))
 
-    // For demonstration, we'll create a simple transaction structure
-    hash := fmt.Sprintf("tx_%d", rand.Int63())
+        inputTotal += utxo.Value
+        // Priced with a change output, which is dropped later if it would be dust
+        txFee = rate * (overhead + int64(len(tx.TxIn))*inputVsize + 2*outputVsize)
+        if inputTotal >= send+txFee {
+            break
+        }
+    }
+    if inputTotal < send+txFee || len(tx.TxIn) == 0 {
+        return nil, fmt.Errorf("%w: confirmed outputs of %s hold %d satoshis, %d plus a fee of %d needed", ErrInsufficientFunds, from, inputTotal, send, txFee)
+    }
+
+    tx.AddTxOut(wire.NewTxOut(send, toScript))
+    if change := inputTotal - send - txFee; change >= dustLimit {
+        tx.AddTxOut(wire.NewTxOut(change, fromScript))
+    } else {
+        txFee += change
+    }
+
+    if err := b.signInputs(tx, prevOuts, fromAddr, fromScript, privKey); err != nil {
+        return nil, err
+    }
+
+    var raw bytes.Buffer
+    if err := tx.Serialize(&raw); err != nil {
+        return nil, fmt.Errorf("failed to serialize transaction: %w", err)
+    }
+
+    txid, err := b.broadcast(ctx, tx.TxHash().String(), hex.EncodeToString(raw.Bytes()))
+    if err != nil {
+        return nil, err
+    }
 
     return &Transaction{
-        Hash:          hash,
+        Hash:          txid,
         From:          from,
         To:            to,
         Amount:        amount,
-        Fee:           0.0001, // Standard Bitcoin fee
+        Decimals:      btcDecimals,
+        Fee:           units.FromInt64(txFee),
+        FeeTier:       fee.Tier,
+        FeeRate:       fee.FeeRate,
         Confirmations: 0,
         Status:        "pending",
-        Timestamp:     0, // Would be set to current time in real implementation
+        Timestamp:     time.Now().Unix(),
     }, nil
 }
 
-// GetTransaction retrieves transaction details
+// GetTransaction retrieves a transaction from the mempool API, with confirmations counted from
+// the chain tip. The recipient is the first output not paying back to the sender.
 func (b *BitcoinAdapter) GetTransaction(ctx context.Context, hash string) (*Transaction, error) {
-    // In a real implementation, this would fetch transaction details from the blockchain
-    // For now, we'll return a mock transaction
-    return &Transaction{
-        Hash:          hash,
-        From:          "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa",
-        To:            "12c6DSiU4Rq3P4ZxziKxzrL5LmMBrzjrJX",
-        Amount:        0.1,
-        Fee:           0.0001,
-        Confirmations: 3,
-        Status:        "confirmed",
-        Timestamp:     1625097600,
-    }, nil
+    var tx esploraTx
+    if err := b.getJSON(ctx, "/tx/"+hash, &tx); err != nil {
+        return nil, fmt.Errorf("failed to fetch transaction %s: %w", hash, err)
+    }
+
+    result := &Transaction{
+        Hash:     tx.Txid,
+        Decimals: btcDecimals,
+        Fee:      units.FromInt64(tx.Fee),
+        Status:   "pending",
+    }
+    if len(tx.Vin) > 0 {
+        result.From = tx.Vin[0].Prevout.ScriptPubKeyAddress
+    }
+    for _, out := range tx.Vout {
+        if out.ScriptPubKeyAddress != result.From {
+            result.To = out.ScriptPubKeyAddress
+            result.Amount = units.FromInt64(out.Value)
+            break
+        }
+    }
+    if vsize := (tx.Weight + 3) / 4; vsize > 0 {
+        result.FeeRate = uint64(tx.Fee / vsize)
+    }
+
+    if tx.Status.Confirmed {
+        var tip int64
+        if err := b.getJSON(ctx, "/blocks/tip/height", &tip); err != nil {
+            return nil, fmt.Errorf("failed to fetch chain tip: %w", err)
+        }
+
+        result.Status = "confirmed"
+        result.Confirmations = 1
+        if tip > tx.Status.BlockHeight {
+            result.Confirmations += int(tip - tx.Status.BlockHeight)
+        }
+        result.Timestamp = tx.Status.BlockTime
+    }
+
+    return result, nil
+}
+
+// EstimateFee estimates the fee of a transfer at every tier from the mempool's recommended
+// sat/vB rates and the virtual size of a transaction spending from the sender's address
+func (b *BitcoinAdapter) EstimateFee(ctx context.Context, from, to string, amount units.Amount) (FeeEstimates, error) {
+    fromAddr, err := btcutil.DecodeAddress(from, b.network)
+    if err != nil {
+        return nil, fmt.Errorf("invalid from address: %w", err)
+    }
+
+    vsize := uint64(legacyTxVsize)
+    switch fromAddr.(type) {
+    case *btcutil.AddressWitnessPubKeyHash, *btcutil.AddressWitnessScriptHash, *btcutil.AddressTaproot:
+        vsize = segwitTxVsize
+    }
+
+    rates, err := b.recommendedFeeRates(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    tiers := []struct {
+        tier     FeeTier
+        rate     uint64
+        expected time.Duration
+    }{
+        {FeeSlow, rates.HourFee, time.Hour},
+        {FeeStandard, rates.HalfHourFee, 30 * time.Minute},
+        {FeeFast, rates.FastestFee, 10 * time.Minute},
+    }
+
+    estimates := make(FeeEstimates, 0, len(tiers))
+    for _, t := range tiers {
+        // Never go below the minimum relay fee of 1 sat/vB
+        rate := t.rate
+        if rate < 1 {
+            rate = 1
+        }
+
+        estimates = append(estimates, FeeEstimate{
+            Tier:                 t.tier,
+            Fee:                  units.FromUint64(rate * vsize),
+            ExpectedConfirmation: t.expected,
+            FeeRate:              rate,
+        })
+    }
+
+    return estimates, nil
 }
 
-// EstimateFee estimates the transaction fee
-func (b *BitcoinAdapter) EstimateFee(ctx context.Context, from, to string, amount float64) (float64, error) {
-    // In a real implementation, this would calculate the fee based on:
-    // 1. Current network fee rates
-    // 2. Transaction size (number of inputs/outputs)
-    // 3. Priority settings
-    // For now, we'll return a standard fee
-    return 0.0001, nil
-}
\ No newline at end of file
+// feeRecommendation is the response of the mempool.space recommended fees endpoint, in sat/vB
+type feeRecommendation struct {
+    FastestFee  uint64 `json:"fastestFee"`
+    HalfHourFee uint64 `json:"halfHourFee"`
+    HourFee     uint64 `json:"hourFee"`
+    EconomyFee  uint64 `json:"economyFee"`
+    MinimumFee  uint64 `json:"minimumFee"`
+}
+
+// recommendedFeeRates fetches the current fee rate recommendations from the mempool API
+func (b *BitcoinAdapter) recommendedFeeRates(ctx context.Context) (*feeRecommendation, error) {
+    var rates feeRecommendation
+    if err := b.getJSON(ctx, "/v1/fees/recommended", &rates); err != nil {
+        return nil, fmt.Errorf("failed to fetch fee rates: %w", err)
+    }
+
+    return &rates, nil
+}
+
+// errAPINotFound is returned by getJSON when the mempool API does not know the resource
+var errAPINotFound = fmt.Errorf("mempool API: %w", ErrNotFound)
+
+// getJSON fetches a path of the mempool API and decodes its JSON response, failing over to the
+// next API when one cannot be reached
+func (b *BitcoinAdapter) getJSON(ctx context.Context, path string, out interface{}) error {
+    return b.endpoints.call(ctx, func(apiURL string) error {
+        return b.fetchJSON(ctx, apiURL, path, out)
+    })
+}
+
+// fetchJSON fetches a path of one mempool API and decodes its JSON response
+func (b *BitcoinAdapter) fetchJSON(ctx context.Context, apiURL, path string, out interface{}) error {
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL+path, nil)
+    if err != nil {
+        return fmt.Errorf("failed to build request: %w", err)
+    }
+
+    resp, err := b.httpClient.Do(req)
+    if err != nil {
+        return fmt.Errorf("%w: %w", ErrChainUnavailable, err)
+    }
+    defer resp.Body.Close()
+
+    switch {
+    case resp.StatusCode == http.StatusNotFound:
+        return errAPINotFound
+    case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError:
+        return fmt.Errorf("%w: mempool API returned status %d", ErrChainUnavailable, resp.StatusCode)
+    case resp.StatusCode != http.StatusOK:
+        return fmt.Errorf("mempool API returned status %d", resp.StatusCode)
+    }
+
+    return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,121 @@
+package blockchain
+
+import (
+    "context"
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "reflect"
+    "testing"
+
+    "github.com/btcsuite/btcd/chaincfg"
+)
+
+// The fixtures are one mainnet-format block at height 100 in the shape of a bitcoind getblock
+// response with verbosity 2 and of the Esplora /block and /block/:hash/txs responses, with an
+// output of every standard script type
+const (
+    fixtureBlockHash    = "0000000000000000000e1887678a726f7c9cb6304bb33c3eb4f4f9bd40cb9511"
+    fixturePreviousHash = "0000000000000000000889d1f2191025e07499f93a84953487e718da402e6d00"
+    fixtureCoinbaseTxid = "54977171b8b8dbb8f6941781829349d11f3a42403219ae8a54286554f4c38281"
+    fixturePaymentTxid  = "f75fb9685a9b83db96f4fdfe7e7f92313dcb580b3e4f052cfd4b55203346cc57"
+)
+
+// fixtureBlock is the block both fixtures decode to
+var fixtureBlock = &BitcoinBlock{
+    Height:       100,
+    Hash:         fixtureBlockHash,
+    PreviousHash: fixturePreviousHash,
+    Time:         1700000000,
+    Transactions: []BitcoinTx{
+        {
+            Txid: fixtureCoinbaseTxid,
+            Outputs: []BitcoinOutput{
+                {Vout: 0, Address: "bc1qtu3x522kkc9r2fqfuzpv0c9wekf5wpgt55wvsf", Value: 50000000, ScriptType: "witness_v0_keyhash"},
+                {Vout: 1, Address: "", Value: 0, ScriptType: "nulldata"},
+            },
+        },
+        {
+            Txid: fixturePaymentTxid,
+            Outputs: []BitcoinOutput{
+                {Vout: 0, Address: "1KcnaKLWb2w7oiZ69xh4aC51sPztwGU29n", Value: 12345, ScriptType: "pubkeyhash"},
+                {Vout: 1, Address: "3K6qfu7HoZx81o7Qbn7nan4CyYgTVNKjBy", Value: 100000000, ScriptType: "scripthash"},
+                {Vout: 2, Address: "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0", Value: 2100000, ScriptType: "witness_v1_taproot"},
+                {Vout: 3, Address: "bc1qss3053z5ag06w7g4rcx0yd47fnxa2n97fqthudvc3trtmgqssgtq4u80e7", Value: 30000000, ScriptType: "witness_v0_scripthash"},
+            },
+        },
+    },
+}
+
+func TestBitcoindBlockFixture(t *testing.T) {
+    ctx := context.Background()
+    source, err := LoadBitcoinFixtures("testdata/bitcoin/bitcoind", &chaincfg.MainNetParams)
+    if err != nil {
+        t.Fatalf("LoadBitcoinFixtures() error = %v", err)
+    }
+
+    tip, err := source.TipHeight(ctx)
+    if err != nil || tip != 100 {
+        t.Fatalf("TipHeight() = %d, %v, want 100", tip, err)
+    }
+    hash, err := source.BlockHash(ctx, 100)
+    if err != nil || hash != fixtureBlockHash {
+        t.Fatalf("BlockHash(100) = %s, %v, want %s", hash, err, fixtureBlockHash)
+    }
+
+    block, err := source.Block(ctx, hash)
+    if err != nil {
+        t.Fatalf("Block() error = %v", err)
+    }
+    if !reflect.DeepEqual(block, fixtureBlock) {
+        t.Errorf("Block() = %+v, want %+v", block, fixtureBlock)
+    }
+}
+
+func TestEsploraBlockFixture(t *testing.T) {
+    responses := map[string]string{
+        "/block/" + fixtureBlockHash:           "testdata/bitcoin/esplora/block.json",
+        "/block/" + fixtureBlockHash + "/txs/0": "testdata/bitcoin/esplora/txs-0.json",
+    }
+    server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        switch r.URL.Path {
+        case "/blocks/tip/height":
+            w.Write([]byte("100"))
+        case "/block-height/100":
+            w.Write([]byte(fixtureBlockHash))
+        default:
+            path, ok := responses[r.URL.Path]
+            if !ok {
+                http.NotFound(w, r)
+                return
+            }
+            data, err := os.ReadFile(path)
+            if err != nil {
+                http.Error(w, err.Error(), http.StatusInternalServerError)
+                return
+            }
+            w.Write(data)
+        }
+    }))
+    defer server.Close()
+
+    ctx := context.Background()
+    source := NewEsploraBlockSource(server.URL, &chaincfg.MainNetParams)
+
+    tip, err := source.TipHeight(ctx)
+    if err != nil || tip != 100 {
+        t.Fatalf("TipHeight() = %d, %v, want 100", tip, err)
+    }
+    hash, err := source.BlockHash(ctx, 100)
+    if err != nil || hash != fixtureBlockHash {
+        t.Fatalf("BlockHash(100) = %s, %v, want %s", hash, err, fixtureBlockHash)
+    }
+
+    block, err := source.Block(ctx, hash)
+    if err != nil {
+        t.Fatalf("Block() error = %v", err)
+    }
+    if !reflect.DeepEqual(block, fixtureBlock) {
+        t.Errorf("Block() = %+v, want %+v", block, fixtureBlock)
+    }
+}
@@ -0,0 +1,94 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+)
+
+// BitcoinMempoolSource reads the transactions waiting in a Bitcoin node's mempool
+type BitcoinMempoolSource interface {
+    // MempoolTxids returns the IDs of every transaction in the mempool
+    MempoolTxids(ctx context.Context) ([]string, error)
+    // MempoolTx returns a transaction of the mempool, or ErrNotFound once it has left it
+    MempoolTx(ctx context.Context, txid string) (*BitcoinTx, error)
+    // TransactionConfirmations returns the confirmations of a transaction, zero while it is in
+    // the mempool, or ErrNotFound once it is neither in the mempool nor mined
+    TransactionConfirmations(ctx context.Context, txid string) (int, error)
+}
+
+// MempoolTxids returns the IDs of every transaction in the mempool
+func (s *EsploraBlockSource) MempoolTxids(ctx context.Context) ([]string, error) {
+    var txids []string
+    if err := s.getJSON(ctx, "/mempool/txids", &txids); err != nil {
+        return nil, fmt.Errorf("failed to fetch mempool: %w", err)
+    }
+    return txids, nil
+}
+
+// MempoolTx returns a transaction of the mempool
+func (s *EsploraBlockSource) MempoolTx(ctx context.Context, txid string) (*BitcoinTx, error) {
+    var tx esploraTx
+    if err := s.getJSON(ctx, "/tx/"+txid, &tx); err != nil {
+        return nil, fmt.Errorf("failed to fetch transaction %s: %w", txid, err)
+    }
+    if tx.Status.Confirmed {
+        return nil, fmt.Errorf("%w: transaction %s was mined", ErrNotFound, txid)
+    }
+    return decodeEsploraTx(tx, s.network)
+}
+
+// TransactionConfirmations returns the confirmations of a transaction, zero while it is in the
+// mempool
+func (s *EsploraBlockSource) TransactionConfirmations(ctx context.Context, txid string) (int, error) {
+    var status struct {
+        Confirmed   bool   `json:"confirmed"`
+        BlockHeight uint64 `json:"block_height"`
+    }
+    if err := s.getJSON(ctx, "/tx/"+txid+"/status", &status); err != nil {
+        return 0, fmt.Errorf("failed to fetch transaction %s: %w", txid, err)
+    }
+    if !status.Confirmed {
+        return 0, nil
+    }
+
+    tip, err := s.TipHeight(ctx)
+    if err != nil {
+        return 0, err
+    }
+    if tip < status.BlockHeight {
+        return 1, nil
+    }
+    return int(tip-status.BlockHeight) + 1, nil
+}
+
+// MempoolTxids returns the IDs of every transaction in the mempool
+func (s *BitcoindBlockSource) MempoolTxids(ctx context.Context) ([]string, error) {
+    var txids []string
+    if err := s.call(ctx, "getrawmempool", &txids); err != nil {
+        return nil, fmt.Errorf("failed to fetch mempool: %w", err)
+    }
+    return txids, nil
+}
+
+// MempoolTx returns a transaction of the mempool
+func (s *BitcoindBlockSource) MempoolTx(ctx context.Context, txid string) (*BitcoinTx, error) {
+    var tx bitcoindTx
+    if err := s.call(ctx, "getrawtransaction", &tx, txid, true); err != nil {
+        return nil, fmt.Errorf("failed to fetch transaction %s: %w", txid, err)
+    }
+    if tx.Confirmations > 0 {
+        return nil, fmt.Errorf("%w: transaction %s was mined", ErrNotFound, txid)
+    }
+    return decodeBitcoindTx(tx, s.network)
+}
+
+// TransactionConfirmations returns the confirmations of a transaction, zero while it is in the
+// mempool. Without -txindex, bitcoind only knows mined transactions of its own wallet, so other
+// mined ones are reported not found.
+func (s *BitcoindBlockSource) TransactionConfirmations(ctx context.Context, txid string) (int, error) {
+    var tx bitcoindTx
+    if err := s.call(ctx, "getrawtransaction", &tx, txid, true); err != nil {
+        return 0, fmt.Errorf("failed to fetch transaction %s: %w", txid, err)
+    }
+    return tx.Confirmations, nil
+}
@@ -0,0 +1,326 @@
+package blockchain
+
+import (
+    "bytes"
+    "context"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "strings"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/btcsuite/btcd/btcec/v2"
+    "github.com/btcsuite/btcd/btcutil"
+    "github.com/btcsuite/btcd/chaincfg/chainhash"
+    "github.com/btcsuite/btcd/txscript"
+    "github.com/btcsuite/btcd/wire"
+)
+
+// rbfSequence is the input sequence that signals a transaction may be replaced (BIP 125)
+const rbfSequence = wire.MaxTxInSequenceNum - 2
+
+// dustLimit is the smallest output value nodes relay, in satoshis
+const dustLimit = 546
+
+// esploraTx is the subset of a mempool API transaction needed to look it up or replace it
+type esploraTx struct {
+    Txid   string `json:"txid"`
+    Weight int64  `json:"weight"`
+    Fee    int64  `json:"fee"`
+    Status struct {
+        Confirmed   bool  `json:"confirmed"`
+        BlockHeight int64 `json:"block_height"`
+        BlockTime   int64 `json:"block_time"`
+    } `json:"status"`
+    Vin []struct {
+        Txid    string        `json:"txid"`
+        Vout    uint32        `json:"vout"`
+        Prevout esploraOutput `json:"prevout"`
+    } `json:"vin"`
+    Vout []esploraOutput `json:"vout"`
+}
+
+// esploraOutput is a transaction output as returned by the mempool API
+type esploraOutput struct {
+    ScriptPubKey        string `json:"scriptpubkey"`
+    ScriptPubKeyAddress string `json:"scriptpubkey_address"`
+    Value               int64  `json:"value"`
+}
+
+// SpeedUpTransaction replaces a pending transaction with one spending the same inputs to the
+// same recipients at a higher fee rate, paid out of the change output. A nil fee uses the fast tier.
+func (b *BitcoinAdapter) SpeedUpTransaction(ctx context.Context, hash, from, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    return b.replaceTx(ctx, hash, from, privateKey, false, fee)
+}
+
+// CancelTransaction replaces a pending transaction with one spending the same inputs back to
+// the sender at a higher fee rate. A nil fee uses the fast tier.
+func (b *BitcoinAdapter) CancelTransaction(ctx context.Context, hash, from, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    return b.replaceTx(ctx, hash, from, privateKey, true, fee)
+}
+
+// replaceTx builds, signs and broadcasts an RBF replacement of a pending transaction sent from
+// a single address controlled by the private key
+func (b *BitcoinAdapter) replaceTx(ctx context.Context, hash, from, privateKey string, cancel bool, fee *FeeEstimate) (*Transaction, error) {
+    fromAddr, err := btcutil.DecodeAddress(from, b.network)
+    if err != nil {
+        return nil, fmt.Errorf("invalid from address: %w", err)
+    }
+
+    privKey, err := b.parseKey(privateKey, fromAddr)
+    if err != nil {
+        return nil, err
+    }
+
+    fromScript, err := txscript.PayToAddrScript(fromAddr)
+    if err != nil {
+        return nil, fmt.Errorf("failed to build script for %s: %w", from, err)
+    }
+
+    var original esploraTx
+    err = b.getJSON(ctx, "/tx/"+hash, &original)
+    if errors.Is(err, errAPINotFound) {
+        return nil, fmt.Errorf("transaction %s: %w", hash, ErrTransactionNotPending)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch transaction %s: %w", hash, err)
+    }
+    if original.Status.Confirmed {
+        return nil, fmt.Errorf("transaction %s: %w", hash, ErrTransactionNotPending)
+    }
+
+    if fee == nil {
+        estimates, err := b.EstimateFee(ctx, from, from, units.Amount{})
+        if err != nil {
+            return nil, err
+        }
+        fast, err := estimates.Tier(FeeFast)
+        if err != nil {
+            return nil, err
+        }
+        fee = &fast
+    }
+
+    // The replacement must pay a higher absolute fee and cover its own relay at 1 sat/vB (BIP 125)
+    vsize := (original.Weight + 3) / 4
+    if vsize == 0 {
+        return nil, fmt.Errorf("mempool API returned no size for transaction %s", hash)
+    }
+    newFee := int64(fee.FeeRate) * vsize
+    if minFee := original.Fee + vsize; newFee < minFee {
+        newFee = minFee
+    }
+
+    tx := wire.NewMsgTx(wire.TxVersion)
+    prevOuts := txscript.NewMultiPrevOutFetcher(make(map[wire.OutPoint]*wire.TxOut))
+    var inputTotal int64
+    for _, in := range original.Vin {
+        // We can only re-sign inputs that spend from our own address
+        if in.Prevout.ScriptPubKey != hex.EncodeToString(fromScript) {
+            return nil, fmt.Errorf("transaction %s spends inputs not owned by %s", hash, from)
+        }
+
+        prevHash, err := chainhash.NewHashFromStr(in.Txid)
+        if err != nil {
+            return nil, fmt.Errorf("invalid input txid %s: %w", in.Txid, err)
+        }
+
+        outPoint := wire.NewOutPoint(prevHash, in.Vout)
+        txIn := wire.NewTxIn(outPoint, nil, nil)
+        txIn.Sequence = rbfSequence
+        tx.AddTxIn(txIn)
+
+        prevOuts.AddPrevOut(*outPoint, wire.NewTxOut(in.Prevout.Value, fromScript))
+        inputTotal += in.Prevout.Value
+    }
+
+    recipient, amount := from, int64(0)
+    if cancel {
+        // Everything but the fee goes back to the sender
+        refund := inputTotal - newFee
+        if refund < dustLimit {
+            return nil, fmt.Errorf("inputs of %s cannot cover a cancellation fee of %d satoshis", hash, newFee)
+        }
+        tx.AddTxOut(wire.NewTxOut(refund, fromScript))
+    } else {
+        // The extra fee comes out of the change; recipients still receive the same amounts
+        extra := newFee - original.Fee
+        changeFound := false
+        for _, out := range original.Vout {
+            script, err := hex.DecodeString(out.ScriptPubKey)
+            if err != nil {
+                return nil, fmt.Errorf("invalid output script in %s: %w", hash, err)
+            }
+
+            value := out.Value
+            if !changeFound && bytes.Equal(script, fromScript) {
+                changeFound = true
+                value -= extra
+                if value < dustLimit {
+                    return nil, fmt.Errorf("change of %s cannot cover a fee increase of %d satoshis", hash, extra)
+                }
+            } else if recipient == from {
+                recipient = b.scriptAddress(script)
+                amount = out.Value
+            }
+            tx.AddTxOut(wire.NewTxOut(value, script))
+        }
+        if !changeFound {
+            return nil, fmt.Errorf("transaction %s has no change output to pay a higher fee from", hash)
+        }
+    }
+
+    if err := b.signInputs(tx, prevOuts, fromAddr, fromScript, privKey); err != nil {
+        return nil, err
+    }
+
+    var raw bytes.Buffer
+    if err := tx.Serialize(&raw); err != nil {
+        return nil, fmt.Errorf("failed to serialize replacement transaction: %w", err)
+    }
+
+    txid, err := b.broadcast(ctx, tx.TxHash().String(), hex.EncodeToString(raw.Bytes()))
+    if err != nil {
+        return nil, err
+    }
+
+    return &Transaction{
+        Hash:          txid,
+        From:          from,
+        To:            recipient,
+        Amount:        units.FromInt64(amount),
+        Decimals:      btcDecimals,
+        Fee:           units.FromInt64(newFee),
+        FeeTier:       fee.Tier,
+        FeeRate:       uint64(newFee / vsize),
+        Confirmations: 0,
+        Status:        "pending",
+        Timestamp:     time.Now().Unix(),
+    }, nil
+}
+
+// parseKey decodes a hex secp256k1 private key and checks that it controls the from address
+func (b *BitcoinAdapter) parseKey(privateKey string, from btcutil.Address) (*btcec.PrivateKey, error) {
+    keyBytes, err := hex.DecodeString(privateKey)
+    if err != nil || len(keyBytes) != btcec.PrivKeyBytesLen {
+        return nil, fmt.Errorf("invalid private key")
+    }
+
+    privKey, pubKey := btcec.PrivKeyFromBytes(keyBytes)
+    pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+
+    var owned bool
+    switch addr := from.(type) {
+    case *btcutil.AddressPubKeyHash:
+        owned = bytes.Equal(addr.Hash160()[:], pubKeyHash)
+    case *btcutil.AddressWitnessPubKeyHash:
+        owned = bytes.Equal(addr.WitnessProgram(), pubKeyHash)
+    default:
+        return nil, fmt.Errorf("signing for %T addresses is not supported", from)
+    }
+    if !owned {
+        return nil, fmt.Errorf("private key does not match from address %s", from.EncodeAddress())
+    }
+
+    return privKey, nil
+}
+
+// signInputs signs every input of a transaction spending outputs of a P2PKH or P2WPKH address
+func (b *BitcoinAdapter) signInputs(tx *wire.MsgTx, prevOuts *txscript.MultiPrevOutFetcher, from btcutil.Address, fromScript []byte, privKey *btcec.PrivateKey) error {
+    sigHashes := txscript.NewTxSigHashes(tx, prevOuts)
+
+    for i, in := range tx.TxIn {
+        switch from.(type) {
+        case *btcutil.AddressWitnessPubKeyHash:
+            prevOut := prevOuts.FetchPrevOutput(in.PreviousOutPoint)
+            witness, err := txscript.WitnessSignature(tx, sigHashes, i, prevOut.Value, fromScript, txscript.SigHashAll, privKey, true)
+            if err != nil {
+                return fmt.Errorf("failed to sign input %d: %w", i, err)
+            }
+            in.Witness = witness
+        default:
+            sigScript, err := txscript.SignatureScript(tx, i, fromScript, txscript.SigHashAll, privKey, true)
+            if err != nil {
+                return fmt.Errorf("failed to sign input %d: %w", i, err)
+            }
+            in.SignatureScript = sigScript
+        }
+    }
+
+    return nil
+}
+
+// scriptAddress returns the address an output script pays to, or an empty string for non-standard scripts
+func (b *BitcoinAdapter) scriptAddress(script []byte) string {
+    _, addrs, _, err := txscript.ExtractPkScriptAddrs(script, b.network)
+    if err != nil || len(addrs) == 0 {
+        return ""
+    }
+    return addrs[0].EncodeAddress()
+}
+
+// broadcast submits a signed transaction to the mempool API, or to the next one when it fails,
+// and returns its txid. When no API says whether it took the transaction, the error is a
+// BroadcastError.
+func (b *BitcoinAdapter) broadcast(ctx context.Context, txid, rawHex string) (string, error) {
+    var id string
+    err := b.endpoints.call(ctx, func(apiURL string) error {
+        var err error
+        id, err = b.broadcastTo(ctx, apiURL, txid, rawHex)
+        return err
+    })
+    return id, err
+}
+
+// broadcastTo submits a signed transaction to one mempool API and returns its txid
+func (b *BitcoinAdapter) broadcastTo(ctx context.Context, apiURL, txid, rawHex string) (string, error) {
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL+"/tx", strings.NewReader(rawHex))
+    if err != nil {
+        return "", fmt.Errorf("failed to build broadcast request: %w", err)
+    }
+    req.Header.Set("Content-Type", "text/plain")
+
+    resp, err := b.httpClient.Do(req)
+    if err != nil {
+        // The request may have reached the API before it failed
+        return "", &BroadcastError{Hash: txid, Raw: rawHex, Err: fmt.Errorf("failed to broadcast transaction: %w", ClassifyError(err))}
+    }
+    defer resp.Body.Close()
+
+    body, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return "", &BroadcastError{Hash: txid, Raw: rawHex, Err: fmt.Errorf("failed to read broadcast response: %w", err)}
+    }
+    if resp.StatusCode != http.StatusOK {
+        err := ClassifyError(fmt.Errorf("failed to broadcast transaction: %s", strings.TrimSpace(string(body))))
+        switch {
+        case isAlreadyKnown(err):
+            return txid, nil
+        case resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests:
+            // Only a 4xx is the node refusing the transaction
+            return "", &BroadcastError{Hash: txid, Raw: rawHex, Err: err}
+        }
+        return "", err
+    }
+
+    return strings.TrimSpace(string(body)), nil
+}
+
+// RebroadcastTransaction sends a transaction signed by this adapter again, as the hex its
+// BroadcastError carried
+func (b *BitcoinAdapter) RebroadcastTransaction(ctx context.Context, raw string) error {
+    data, err := hex.DecodeString(raw)
+    if err != nil {
+        return fmt.Errorf("invalid raw transaction: %w", err)
+    }
+    tx := wire.NewMsgTx(wire.TxVersion)
+    if err := tx.Deserialize(bytes.NewReader(data)); err != nil {
+        return fmt.Errorf("invalid raw transaction: %w", err)
+    }
+
+    _, err = b.broadcast(ctx, tx.TxHash().String(), raw)
+    return err
+}
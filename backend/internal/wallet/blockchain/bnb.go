@@ -1,139 +0,0 @@
-package blockchain
-
-import (
-    "context"
-    "crypto/rand"
-    "fmt"
-    "math/big"
-
-    "github.com/ethereum/go-ethereum/common"
-    "github.com/ethereum/go-ethereum/crypto"
-)
-
-// BNBadapter implements the Adapter interface for BNB (BEP20)
-type BNBadapter struct {
-    rpcURL string
-}
-
-// NewBNBadapter creates a new BNB adapter
-func NewBNBadapter(rpcURL string) *BNBadapter {
-    return &BNBadapter{
-        rpcURL: rpcURL,
-    }
-}
-
-// CreateWallet creates a new BNB wallet
-func (b *BNBadapter) CreateWallet(ctx context.Context) (*Wallet, error) {
-    // Generate a new private key
-    privateKey, err := crypto.GenerateKey()
-    if err != nil {
-        return nil, fmt.Errorf("failed to generate private key: %w", err)
-    }
-
-    // Get the public key
-    publicKey := privateKey.Public()
-    publicKeyECDSA, ok := publicKey.(*crypto.PublicKey)
-    if !ok {
-        return nil, fmt.Errorf("error casting public key to ECDSA")
-    }
-
-    // Generate the address
-    address := crypto.PubkeyToAddress(*publicKeyECDSA)
-
-    return &Wallet{
-        Address:    address.Hex(),
-        PublicKey:  fmt.Sprintf("%x", crypto.FromECDSAPub(publicKeyECDSA)),
-        PrivateKey: fmt.Sprintf("%x", crypto.FromECDSA(privateKey)),
-        Balance:    0,
-    }, nil
-}
-
-// GetWallet retrieves wallet information
-func (b *BNBadapter) GetWallet(ctx context.Context, address string) (*Wallet, error) {
-    // Validate the address
-    if !common.IsHexAddress(address) {
-        return nil, fmt.Errorf("invalid address")
-    }
-
-    addr := common.HexToAddress(address)
-    
-    return &Wallet{
-        Address: addr.Hex(),
-        Balance: 0, // Would be fetched from blockchain in real implementation
-    }, nil
-}
-
-// GetBalance retrieves the balance of an address
-func (b *BNBadapter) GetBalance(ctx context.Context, address string) (float64, error) {
-    // Validate the address
-    if !common.IsHexAddress(address) {
-        return 0, fmt.Errorf("invalid address")
-    }
-
-    // In a real implementation, this would fetch the balance from the BNB network
-    // For now, we'll return a random balance for demonstration
-    balance, _ := rand.Int(rand.Reader, big.NewInt(1000000000000000000)) // Up to 1 BNB in wei
-    return float64(balance.Int64()) / 1000000000000000000, nil
-}
-
-// SendTransaction sends a BNB transaction
-func (b *BNBadapter) SendTransaction(ctx context.Context, from, to string, amount float64, privateKey string) (*Transaction, error) {
-    // Validate addresses
-    if !common.IsHexAddress(from) || !common.IsHexAddress(to) {
-        return nil, fmt.Errorf("invalid address")
-    }
-
-    // In a real implementation, this would:
-    // 1. Connect to a BNB node
-    // 2. Fetch the current nonce for the from address
-    // 3. Create and sign the transaction
-    // 4. Submit the transaction to the network
-    // 5. Return the transaction hash
-
-    // For demonstration, we'll create a mock transaction
-    hash := fmt.Sprintf("0x%x", make([]byte, 32))
-    rand.Read([]byte(hash)[2:])
-
-    return &Transaction{
-        Hash:          hash,
-        From:          from,
-        To:            to,
-        Amount:        amount,
-        Fee:           0.000375, // Standard BNB transaction fee
-        Confirmations: 0,
-        Status:        "pending",
-        Timestamp:     0, // Would be set to current time in real implementation
-    }, nil
-}
-
-// GetTransaction retrieves transaction details
-func (b *BNBadapter) GetTransaction(ctx context.Context, hash string) (*Transaction, error) {
-    // Validate transaction hash
-    if !common.IsHexAddress(hash) {
-        return nil, fmt.Errorf("invalid transaction hash")
-    }
-
-    // In a real implementation, this would fetch transaction details from the BNB network
-    // For now, we'll return a mock transaction
-    return &Transaction{
-        Hash:          hash,
-        From:          "0x742d35Cc6634C0532925a3b8D91D0a74b4A7D3Dc",
-        To:            "0x3f5CE5FBFe3E9af3971dD833D26bA9b5C936f0bE",
-        Amount:        1.5,
-        Fee:           0.000375,
-        Confirmations: 12,
-        Status:        "confirmed",
-        Timestamp:     1625097600,
-    }, nil
-}
-
-// EstimateFee estimates the transaction fee
-func (b *BNBadapter) EstimateFee(ctx context.Context, from, to string, amount float64) (float64, error) {
-    // In a real implementation, this would:
-    // 1. Fetch current gas price from the network
-    // 2. Estimate gas limit for the transaction
-    // 3. Calculate fee as gasPrice * gasLimit
-
-    // For now, we'll return a standard fee
-    return 0.000375, nil
-}
\ No newline at end of file
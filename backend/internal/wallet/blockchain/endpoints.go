@@ -0,0 +1,295 @@
+package blockchain
+
+import (
+    "context"
+    "errors"
+    "log"
+    "sort"
+    "sync"
+    "time"
+)
+
+// Defaults for endpoint health checks
+const (
+    defaultHealthCheckInterval = 15 * time.Second
+    defaultHealthCheckTimeout  = 5 * time.Second
+    defaultMaxHeadLag          = 5 // blocks or slots behind the best endpoint
+    defaultMaxFailures         = 3 // consecutive call failures before an endpoint is benched
+)
+
+// EndpointStatus is a snapshot of the health of one RPC endpoint
+type EndpointStatus struct {
+    URL       string        `json:"url"`
+    Healthy   bool          `json:"healthy"`
+    Head      uint64        `json:"head"`
+    Latency   time.Duration `json:"latency"`
+    Failures  int           `json:"failures"`
+    LastError string        `json:"last_error,omitempty"`
+    CheckedAt time.Time     `json:"checked_at"`
+}
+
+// endpoint is one RPC provider of a chain together with its last known health
+type endpoint[C any] struct {
+    url    string
+    client C
+
+    head      uint64
+    latency   time.Duration
+    failures  int
+    lagging   bool
+    lastError string
+    checkedAt time.Time
+}
+
+// healthy reports whether the endpoint answered its last check, is close to the best head
+// and has not failed too many calls in a row
+func (e *endpoint[C]) healthy(maxFailures int) bool {
+    return !e.checkedAt.IsZero() && e.lastError == "" && !e.lagging && e.failures < maxFailures
+}
+
+// endpointPool tracks the health of several RPC endpoints of one chain and orders them so
+// callers always try the healthiest, fastest endpoint first
+type endpointPool[C any] struct {
+    chain     string
+    endpoints []*endpoint[C]
+    probe     func(ctx context.Context, client C) (uint64, error)
+
+    interval    time.Duration
+    timeout     time.Duration
+    maxHeadLag  uint64
+    maxFailures int
+
+    mu       sync.RWMutex
+    checking bool
+    lastRun  time.Time
+}
+
+// newEndpointPool creates a pool over the given clients. The probe returns an endpoint's head
+// block or slot and is used for health checks.
+func newEndpointPool[C any](chain string, urls []string, clients []C, probe func(ctx context.Context, client C) (uint64, error)) *endpointPool[C] {
+    pool := &endpointPool[C]{
+        chain:       chain,
+        probe:       probe,
+        interval:    defaultHealthCheckInterval,
+        timeout:     defaultHealthCheckTimeout,
+        maxHeadLag:  defaultMaxHeadLag,
+        maxFailures: defaultMaxFailures,
+    }
+    for i, url := range urls {
+        pool.endpoints = append(pool.endpoints, &endpoint[C]{url: url, client: clients[i]})
+    }
+    return pool
+}
+
+// check probes every endpoint concurrently and marks the ones that fail or lag behind the best head
+func (p *endpointPool[C]) check(ctx context.Context) {
+    var wg sync.WaitGroup
+    type result struct {
+        head    uint64
+        latency time.Duration
+        err     error
+    }
+    results := make([]result, len(p.endpoints))
+
+    for i, e := range p.endpoints {
+        wg.Add(1)
+        go func(i int, e *endpoint[C]) {
+            defer wg.Done()
+
+            probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+            defer cancel()
+
+            started := time.Now()
+            head, err := p.probe(probeCtx, e.client)
+            results[i] = result{head: head, latency: time.Since(started), err: err}
+        }(i, e)
+    }
+    wg.Wait()
+
+    var best uint64
+    for _, r := range results {
+        if r.err == nil && r.head > best {
+            best = r.head
+        }
+    }
+
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    now := time.Now()
+    for i, e := range p.endpoints {
+        r := results[i]
+        wasHealthy := e.healthy(p.maxFailures)
+
+        e.checkedAt = now
+        e.latency = r.latency
+        if r.err != nil {
+            e.lastError = r.err.Error()
+        } else {
+            e.lastError = ""
+            e.head = r.head
+            e.lagging = r.head+p.maxHeadLag < best
+            // A successful probe gives a benched endpoint another chance
+            e.failures = 0
+        }
+
+        if healthy := e.healthy(p.maxFailures); healthy != wasHealthy {
+            if healthy {
+                log.Printf("RPC endpoint %s for %s is healthy again at head %d", e.url, p.chain, e.head)
+            } else {
+                log.Printf("RPC endpoint %s for %s is unhealthy (head %d, best %d, error: %s)", e.url, p.chain, e.head, best, e.lastError)
+            }
+        }
+    }
+    p.lastRun = now
+}
+
+// refresh starts a background health check when the last one is older than the check interval.
+// The first check runs synchronously so the pool never orders endpoints blindly.
+func (p *endpointPool[C]) refresh(ctx context.Context) {
+    p.mu.Lock()
+    if p.checking || time.Since(p.lastRun) < p.interval {
+        p.mu.Unlock()
+        return
+    }
+    p.checking = true
+    first := p.lastRun.IsZero()
+    p.mu.Unlock()
+
+    run := func(ctx context.Context) {
+        p.check(ctx)
+        p.mu.Lock()
+        p.checking = false
+        p.mu.Unlock()
+    }
+
+    if first {
+        run(ctx)
+        return
+    }
+    go run(context.Background())
+}
+
+// ordered returns the endpoints to try, healthy ones first by latency and the others as a last resort
+func (p *endpointPool[C]) ordered(ctx context.Context) []*endpoint[C] {
+    p.refresh(ctx)
+
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+
+    ordered := append([]*endpoint[C](nil), p.endpoints...)
+    sort.SliceStable(ordered, func(i, j int) bool {
+        hi, hj := ordered[i].healthy(p.maxFailures), ordered[j].healthy(p.maxFailures)
+        if hi != hj {
+            return hi
+        }
+        return ordered[i].latency < ordered[j].latency
+    })
+    return ordered
+}
+
+// healthyEndpoints returns only the endpoints that passed their last health check
+func (p *endpointPool[C]) healthyEndpoints(ctx context.Context) []*endpoint[C] {
+    var healthy []*endpoint[C]
+    for _, e := range p.ordered(ctx) {
+        p.mu.RLock()
+        ok := e.healthy(p.maxFailures)
+        p.mu.RUnlock()
+        if ok {
+            healthy = append(healthy, e)
+        }
+    }
+    return healthy
+}
+
+// call calls each endpoint in order of health until one answers, the way MultiClient fails over.
+// An endpoint that cannot be reached, or that took a broadcast without saying whether it accepted
+// it, is marked failed and the next one is tried; one that does not know the transaction, block
+// or account may be lagging, so the next one is asked too. Any other error is the chain's answer
+// and is returned as it is. The call must be safe to repeat: a broadcast must resend the same
+// signed transaction.
+func (p *endpointPool[C]) call(ctx context.Context, fn func(client C) error) error {
+    var lastErr error
+
+    for _, e := range p.ordered(ctx) {
+        err := fn(e.client)
+        if err == nil {
+            p.markSuccess(e)
+            return nil
+        }
+        if ctx.Err() != nil {
+            return err
+        }
+
+        var broadcastErr *BroadcastError
+        kind := ClassifyError(err)
+        switch {
+        case errors.Is(kind, ErrNotFound):
+            lastErr = err
+        case errors.Is(kind, ErrChainUnavailable), errors.As(err, &broadcastErr):
+            p.markFailure(e, err)
+            lastErr = err
+        default:
+            p.markSuccess(e)
+            return err
+        }
+    }
+
+    if lastErr == nil {
+        return errNotConnected(p.chain)
+    }
+    return lastErr
+}
+
+// safeHead returns the lowest head among healthy endpoints, a block every one of them has seen
+func (p *endpointPool[C]) safeHead(endpoints []*endpoint[C]) uint64 {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+
+    var head uint64
+    for i, e := range endpoints {
+        if i == 0 || e.head < head {
+            head = e.head
+        }
+    }
+    return head
+}
+
+// markFailure records a failed call to an endpoint
+func (p *endpointPool[C]) markFailure(e *endpoint[C], err error) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    e.failures++
+    if e.failures == p.maxFailures {
+        log.Printf("RPC endpoint %s for %s benched after %d failed calls: %v", e.url, p.chain, e.failures, err)
+    }
+}
+
+// markSuccess records a successful call to an endpoint
+func (p *endpointPool[C]) markSuccess(e *endpoint[C]) {
+    p.mu.Lock()
+    defer p.mu.Unlock()
+
+    e.failures = 0
+}
+
+// statuses returns a snapshot of every endpoint's health
+func (p *endpointPool[C]) statuses() []EndpointStatus {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+
+    statuses := make([]EndpointStatus, 0, len(p.endpoints))
+    for _, e := range p.endpoints {
+        statuses = append(statuses, EndpointStatus{
+            URL:       e.url,
+            Healthy:   e.healthy(p.maxFailures),
+            Head:      e.head,
+            Latency:   e.latency,
+            Failures:  e.failures,
+            LastError: e.lastError,
+            CheckedAt: e.checkedAt,
+        })
+    }
+    return statuses
+}
@@ -0,0 +1,226 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "strings"
+    "sync"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/accounts/abi"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+)
+
+// erc20ABIJSON is the subset of the ERC-20 standard used by the adapters
+const erc20ABIJSON = `[
+    {"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+    {"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"},
+    {"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},
+    {"constant":false,"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"type":"function"},
+    {"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Transfer","type":"event"}
+]`
+
+// erc20ABI is the parsed ERC-20 ABI
+var erc20ABI = mustParseABI(erc20ABIJSON)
+
+// mustParseABI parses a contract ABI and panics on malformed input
+func mustParseABI(definition string) abi.ABI {
+    parsed, err := abi.JSON(strings.NewReader(definition))
+    if err != nil {
+        panic(fmt.Sprintf("invalid contract ABI: %v", err))
+    }
+    return parsed
+}
+
+// tokenMetadataCache caches token metadata, which never changes for a deployed token
+type tokenMetadataCache struct {
+    mu      sync.RWMutex
+    entries map[string]*TokenMetadata
+}
+
+// newTokenMetadataCache creates an empty token metadata cache
+func newTokenMetadataCache() *tokenMetadataCache {
+    return &tokenMetadataCache{
+        entries: make(map[string]*TokenMetadata),
+    }
+}
+
+// get returns the cached metadata for a token
+func (c *tokenMetadataCache) get(token string) (*TokenMetadata, bool) {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    meta, ok := c.entries[token]
+    return meta, ok
+}
+
+// put stores the metadata for a token
+func (c *tokenMetadataCache) put(token string, meta *TokenMetadata) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.entries[token] = meta
+}
+
+// callERC20 calls a read-only ERC-20 method and unpacks its outputs
+func callERC20(ctx context.Context, client EVMClient, token common.Address, method string, args ...interface{}) ([]interface{}, error) {
+    data, err := erc20ABI.Pack(method, args...)
+    if err != nil {
+        return nil, fmt.Errorf("failed to pack %s call: %w", method, err)
+    }
+
+    out, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to call %s on %s: %w", method, token.Hex(), ClassifyError(err))
+    }
+
+    values, err := erc20ABI.Unpack(method, out)
+    if err != nil {
+        return nil, fmt.Errorf("failed to unpack %s result from %s: %w", method, token.Hex(), err)
+    }
+    if len(values) == 0 {
+        return nil, fmt.Errorf("empty %s result from %s", method, token.Hex())
+    }
+
+    return values, nil
+}
+
+// getERC20Metadata fetches the symbol and decimals of an ERC-20 token, using the cache when possible
+func getERC20Metadata(ctx context.Context, client EVMClient, cache *tokenMetadataCache, token common.Address) (*TokenMetadata, error) {
+    if meta, ok := cache.get(token.Hex()); ok {
+        return meta, nil
+    }
+
+    decimals, err := callERC20(ctx, client, token, "decimals")
+    if err != nil {
+        return nil, err
+    }
+
+    symbol, err := callERC20(ctx, client, token, "symbol")
+    if err != nil {
+        return nil, err
+    }
+
+    meta := &TokenMetadata{
+        Contract: token.Hex(),
+        Symbol:   symbol[0].(string),
+        Decimals: decimals[0].(uint8),
+    }
+    cache.put(token.Hex(), meta)
+
+    return meta, nil
+}
+
+// ERC20TransferTopic is the topic of the ERC-20 Transfer(address,address,uint256) event
+var ERC20TransferTopic = erc20ABI.Events["Transfer"].ID
+
+// ERC20Transfer is a decoded ERC-20 Transfer event
+type ERC20Transfer struct {
+    Token     common.Address
+    From      common.Address
+    To        common.Address
+    Value     *big.Int
+    TxHash    common.Hash
+    LogIndex  uint
+    Block     uint64
+    BlockHash common.Hash
+}
+
+// ParseERC20Transfer decodes a Transfer event log. Logs of other events, and ERC-721 transfers
+// whose token ID is indexed, are rejected.
+func ParseERC20Transfer(log types.Log) (*ERC20Transfer, error) {
+    if len(log.Topics) != 3 || log.Topics[0] != ERC20TransferTopic {
+        return nil, fmt.Errorf("log %d of %s is not an ERC-20 transfer", log.Index, log.TxHash.Hex())
+    }
+
+    values, err := erc20ABI.Unpack("Transfer", log.Data)
+    if err != nil {
+        return nil, fmt.Errorf("failed to decode transfer log %d of %s: %w", log.Index, log.TxHash.Hex(), err)
+    }
+
+    return &ERC20Transfer{
+        Token:     log.Address,
+        From:      common.BytesToAddress(log.Topics[1].Bytes()),
+        To:        common.BytesToAddress(log.Topics[2].Bytes()),
+        Value:     values[0].(*big.Int),
+        TxHash:    log.TxHash,
+        LogIndex:  log.Index,
+        Block:     log.BlockNumber,
+        BlockHash: log.BlockHash,
+    }, nil
+}
+
+// getERC20Balance fetches the raw token balance of an owner
+func getERC20Balance(ctx context.Context, client EVMClient, token, owner common.Address) (*big.Int, error) {
+    values, err := callERC20(ctx, client, token, "balanceOf", owner)
+    if err != nil {
+        return nil, err
+    }
+    return values[0].(*big.Int), nil
+}
+
+// sendERC20Transfer signs and broadcasts an ERC-20 transfer through the given signer
+func sendERC20Transfer(ctx context.Context, signer *evmSigner, cache *tokenMetadataCache, token, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    if !common.IsHexAddress(token) {
+        return nil, fmt.Errorf("invalid token contract address")
+    }
+    if !common.IsHexAddress(from) {
+        return nil, fmt.Errorf("invalid from address")
+    }
+    if err := validateEVMAddress(to); err != nil {
+        return nil, fmt.Errorf("invalid to address: %w", err)
+    }
+
+    key, err := parseEVMKey(privateKey, from)
+    if err != nil {
+        return nil, err
+    }
+
+    tokenAddr := common.HexToAddress(token)
+    meta, err := getERC20Metadata(ctx, signer.client, cache, tokenAddr)
+    if err != nil {
+        return nil, err
+    }
+
+    data, err := erc20ABI.Pack("transfer", common.HexToAddress(to), amount.BigInt())
+    if err != nil {
+        return nil, fmt.Errorf("failed to pack transfer call: %w", err)
+    }
+
+    // The transaction itself goes to the token contract and carries no ether
+    tx, err := signer.sendDynamicFeeTx(ctx, key, &tokenAddr, big.NewInt(0), data, fee)
+    if err != nil {
+        return nil, err
+    }
+
+    tx.To = common.HexToAddress(to).Hex()
+    tx.Amount = amount
+    tx.Decimals = meta.Decimals
+    tx.Token = meta.Contract
+    tx.TokenSymbol = meta.Symbol
+
+    return tx, nil
+}
+
+// estimateERC20Transfer estimates the fee of an ERC-20 transfer at every tier
+func estimateERC20Transfer(ctx context.Context, signer *evmSigner, token, from, to string, amount units.Amount) (FeeEstimates, error) {
+    if !common.IsHexAddress(token) {
+        return nil, fmt.Errorf("invalid token contract address")
+    }
+    if !common.IsHexAddress(from) || !common.IsHexAddress(to) {
+        return nil, fmt.Errorf("invalid address")
+    }
+
+    data, err := erc20ABI.Pack("transfer", common.HexToAddress(to), amount.BigInt())
+    if err != nil {
+        return nil, fmt.Errorf("failed to pack transfer call: %w", err)
+    }
+
+    tokenAddr := common.HexToAddress(token)
+    return signer.estimateFees(ctx, ethereum.CallMsg{
+        From: common.HexToAddress(from),
+        To:   &tokenAddr,
+        Data: data,
+    })
+}
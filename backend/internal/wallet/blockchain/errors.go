@@ -0,0 +1,171 @@
+package blockchain
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net"
+    "strings"
+
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/rpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// Errors every adapter and custodial provider maps chain failures into, so callers can decide
+// whether to retry, fail or alert without parsing node-specific messages
+var (
+    // ErrChainUnavailable means no node could be reached or none answered in time
+    ErrChainUnavailable = errors.New("chain unavailable")
+
+    // ErrNotFound means the chain does not know the requested transaction, block or account
+    ErrNotFound = errors.New("not found on chain")
+
+    // ErrInsufficientFunds means the sender cannot pay for the amount and the fee
+    ErrInsufficientFunds = errors.New("insufficient funds")
+
+    // ErrNonceTooLow means the nonce was already used by a mined transaction
+    ErrNonceTooLow = errors.New("nonce too low")
+
+    // ErrUnderpriced means the fee is below what nodes accept, or too low to replace a pending transaction
+    ErrUnderpriced = errors.New("transaction underpriced")
+
+    // ErrReverted means the transaction or call was executed and reverted
+    ErrReverted = errors.New("transaction reverted")
+
+    // ErrUnsupported means the node does not implement the method called, such as an endpoint
+    // without the debug or trace namespace; it is a configuration error that retrying cannot fix
+    ErrUnsupported = errors.New("method not supported by the node")
+)
+
+// methodNotFoundCode is the JSON-RPC error code of a method the node does not implement
+const methodNotFoundCode = -32601
+
+// chainErrors lists the sentinel errors in the order ClassifyError checks them
+var chainErrors = []error{ErrChainUnavailable, ErrNotFound, ErrInsufficientFunds, ErrNonceTooLow, ErrUnderpriced, ErrReverted, ErrUnsupported}
+
+// errorPatterns maps fragments of node and provider error messages to sentinel errors.
+// Underpriced patterns come first because some of them mention funds or balances. Not found
+// fragments name what was not found, so that a "method not found" is never taken for a
+// transaction that does not exist.
+var errorPatterns = []struct {
+    kind      error
+    fragments []string
+}{
+    {ErrUnderpriced, []string{"underpriced", "fee too low", "min relay fee not met", "insufficient fee", "less than block base fee", "fee limit too low"}},
+    {ErrNonceTooLow, []string{"nonce too low", "nonce has already been used", "already been processed"}},
+    {ErrInsufficientFunds, []string{"insufficient funds", "insufficient balance", "insufficient lamports", "balance is not sufficient", "bad-txns-inputs-missingorspent", "bad-txns-in-belowout"}},
+    {ErrReverted, []string{"execution reverted", "revert opcode"}},
+    {ErrUnsupported, []string{"method not found", "does not exist/is not available", "method not supported", "unimplemented"}},
+    {ErrNotFound, []string{"transaction not found", "transaction info not found", "header not found", "block not found", "account not found", "unknown block", "unknown transaction", "no such transaction", "block not available", "was skipped"}},
+    {ErrChainUnavailable, []string{"connection refused", "connection reset", "no such host", "i/o timeout", "too many requests", "service unavailable", "bad gateway", "gateway timeout", "unexpected eof", "server_busy", "not_enough_effective_connection"}},
+}
+
+// ClassifyError wraps an error from a node or provider with the sentinel error it matches,
+// keeping the original message. Errors that are already classified or match nothing are
+// returned unchanged.
+func ClassifyError(err error) error {
+    if err == nil {
+        return nil
+    }
+    for _, kind := range chainErrors {
+        if errors.Is(err, kind) {
+            return err
+        }
+    }
+
+    if kind := classify(err); kind != nil {
+        return fmt.Errorf("%w: %w", kind, err)
+    }
+    return err
+}
+
+// classify returns the sentinel error matching err, or nil
+func classify(err error) error {
+    switch {
+    case errors.Is(err, ethereum.NotFound):
+        return ErrNotFound
+    case errors.Is(err, ErrNoQuorum), errors.Is(err, context.DeadlineExceeded):
+        return ErrChainUnavailable
+    }
+
+    var netErr net.Error
+    if errors.As(err, &netErr) {
+        return ErrChainUnavailable
+    }
+
+    var rpcErr rpc.Error
+    if errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFoundCode {
+        return ErrUnsupported
+    }
+
+    if s, ok := status.FromError(err); ok {
+        switch s.Code() {
+        case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+            return ErrChainUnavailable
+        case codes.NotFound:
+            return ErrNotFound
+        case codes.Unimplemented:
+            return ErrUnsupported
+        }
+    }
+
+    message := strings.ToLower(err.Error())
+    for _, pattern := range errorPatterns {
+        for _, fragment := range pattern.fragments {
+            if strings.Contains(message, fragment) {
+                return pattern.kind
+            }
+        }
+    }
+    return nil
+}
+
+// BroadcastError is returned when a signed transaction was sent but no node said whether it took
+// it, such as after a timeout or a gateway error. The transaction may still be mined, so it must
+// be rebroadcast as it was signed: signing the transfer again could pay it twice.
+type BroadcastError struct {
+    Hash string // hash or ID of the signed transaction
+    Raw  string // the signed transaction, as RebroadcastTransaction takes it
+    Err  error
+}
+
+func (e *BroadcastError) Error() string {
+    return fmt.Sprintf("transaction %s may have been broadcast: %v", e.Hash, e.Err)
+}
+
+func (e *BroadcastError) Unwrap() error {
+    return e.Err
+}
+
+// IsRetryable reports whether an operation that failed with err may succeed when tried again,
+// after the network recovers, with a fresh nonce or with a higher fee. A transaction that may
+// already be on the network is never retryable; see BroadcastError.
+func IsRetryable(err error) bool {
+    var broadcastErr *BroadcastError
+    if errors.As(err, &broadcastErr) {
+        return false
+    }
+    return errors.Is(err, ErrChainUnavailable) || errors.Is(err, ErrNonceTooLow) || errors.Is(err, ErrUnderpriced)
+}
+
+// alreadyKnownFragments are node answers to a broadcast of a transaction they already have
+var alreadyKnownFragments = []string{"already known", "known transaction", "txn-already-in-mempool", "txn-already-known", "transaction already in block chain", "already been processed"}
+
+// isAlreadyKnown reports whether a broadcast failed only because the node already has the
+// transaction, in its mempool or in a block
+func isAlreadyKnown(err error) bool {
+    message := strings.ToLower(err.Error())
+    for _, fragment := range alreadyKnownFragments {
+        if strings.Contains(message, fragment) {
+            return true
+        }
+    }
+    return false
+}
+
+// errNotConnected is returned by adapters that have no client for their chain
+func errNotConnected(chain string) error {
+    return fmt.Errorf("%w: %s client is not connected", ErrChainUnavailable, chain)
+}
@@ -0,0 +1,45 @@
+package blockchain
+
+import (
+    "errors"
+    "testing"
+)
+
+// jsonRPCError is a JSON-RPC error answer, as go-ethereum's rpc package reports it
+type jsonRPCError struct {
+    code    int
+    message string
+}
+
+func (e jsonRPCError) Error() string  { return e.message }
+func (e jsonRPCError) ErrorCode() int { return e.code }
+
+func TestClassifyError(t *testing.T) {
+    tests := []struct {
+        err  error
+        want error
+    }{
+        {jsonRPCError{-32601, "the method debug_traceBlockByNumber does not exist/is not available"}, ErrUnsupported},
+        {jsonRPCError{-32601, "Method not found"}, ErrUnsupported},
+        {errors.New("Method not found"), ErrUnsupported},
+        {errors.New("transaction not found"), ErrNotFound},
+        {errors.New("transaction info not found"), ErrNotFound},
+        {errors.New("header not found"), ErrNotFound},
+        {errors.New("account not found"), ErrNotFound},
+        {errors.New("execution reverted"), ErrReverted},
+        {errors.New("dial tcp: connection refused"), ErrChainUnavailable},
+    }
+    for _, tt := range tests {
+        if got := ClassifyError(tt.err); !errors.Is(got, tt.want) {
+            t.Errorf("ClassifyError(%q) = %v, want %v", tt.err, got, tt.want)
+        }
+    }
+
+    // A method the node does not have is not a missing transaction
+    if err := ClassifyError(errors.New("Method not found")); errors.Is(err, ErrNotFound) {
+        t.Errorf("ClassifyError(method not found) = %v, want it not to be ErrNotFound", err)
+    }
+    if err := errors.New("something else failed"); ClassifyError(err) != err {
+        t.Errorf("ClassifyError() changed an error that matches nothing")
+    }
+}
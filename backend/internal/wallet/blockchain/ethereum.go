@@ -1,255 +0,0 @@
-package blockchain
-
-import (
-    "context"
-    "crypto/rand"
-    "fmt"
-    "math/big"
-    "time"
-
-    "github.com/ethereum/go-ethereum/common"
-    "github.com/ethereum/go-ethereum/core/types"
-    "github.com/ethereum/go-ethereum/crypto"
-    "github.com/ethereum/go-ethereum/ethclient"
-)
-
-// EthereumAdapter implements the Adapter interface for Ethereum
-type EthereumAdapter struct {
-    rpcURL string
-    client *ethclient.Client
-}
-
-// NewEthereumAdapter creates a new Ethereum adapter
-func NewEthereumAdapter(rpcURL string) *EthereumAdapter {
-    client, err := ethclient.Dial(rpcURL)
-    if err != nil {
-        // If we can't connect to the RPC, we'll use a nil client and fallback to mock behavior
-        fmt.Printf("Warning: Could not connect to Ethereum RPC at %s: %v\n", rpcURL, err)
-        return &EthereumAdapter{
-            rpcURL: rpcURL,
-            client: nil,
-        }
-    }
-    
-    return &EthereumAdapter{
-        rpcURL: rpcURL,
-        client: client,
-    }
-}
-
-// CreateWallet creates a new Ethereum wallet
-func (e *EthereumAdapter) CreateWallet(ctx context.Context) (*Wallet, error) {
-    // Generate a new private key
-    privateKey, err := crypto.GenerateKey()
-    if err != nil {
-        return nil, fmt.Errorf("failed to generate private key: %w", err)
-    }
-
-    // Get the public key
-    publicKey := privateKey.Public()
-    publicKeyECDSA, ok := publicKey.(*crypto.PublicKey)
-    if !ok {
-        return nil, fmt.Errorf("error casting public key to ECDSA")
-    }
-
-    // Generate the address
-    address := crypto.PubkeyToAddress(*publicKeyECDSA)
-
-    return &Wallet{
-        Address:    address.Hex(),
-        PublicKey:  fmt.Sprintf("%x", crypto.FromECDSAPub(publicKeyECDSA)),
-        PrivateKey: fmt.Sprintf("%x", crypto.FromECDSA(privateKey)),
-        Balance:    0,
-    }, nil
-}
-
-// GetWallet retrieves wallet information
-func (e *EthereumAdapter) GetWallet(ctx context.Context, address string) (*Wallet, error) {
-    // Validate the address
-    if !common.IsHexAddress(address) {
-        return nil, fmt.Errorf("invalid address")
-    }
-
-    addr := common.HexToAddress(address)
-    
-    // If we have a client, try to fetch the balance from the network
-    if e.client != nil {
-        balance, err := e.client.BalanceAt(ctx, addr, nil)
-        if err != nil {
-            fmt.Printf("Warning: Could not fetch balance from network: %v\n", err)
-        } else {
-            return &Wallet{
-                Address: addr.Hex(),
-                Balance: float64(balance.Int64()) / 1000000000000000000, // Convert wei to ETH
-            }, nil
-        }
-    }
-    
-    // Fallback to mock behavior
-    return &Wallet{
-        Address: addr.Hex(),
-        Balance: 0, // Would be fetched from blockchain in real implementation
-    }, nil
-}
-
-// GetBalance retrieves the balance of an address
-func (e *EthereumAdapter) GetBalance(ctx context.Context, address string) (float64, error) {
-    // Validate the address
-    if !common.IsHexAddress(address) {
-        return 0, fmt.Errorf("invalid address")
-    }
-
-    addr := common.HexToAddress(address)
-    
-    // If we have a client, try to fetch the balance from the network
-    if e.client != nil {
-        balance, err := e.client.BalanceAt(ctx, addr, nil)
-        if err != nil {
-            fmt.Printf("Warning: Could not fetch balance from network: %v\n", err)
-        } else {
-            return float64(balance.Int64()) / 1000000000000000000, // Convert wei to ETH
-        }
-    }
-
-    // In a real implementation, this would fetch the balance from the Ethereum network
-    // For now, we'll return a random balance for demonstration
-    balance, _ := rand.Int(rand.Reader, big.NewInt(1000000000000000000)) // Up to 1 ETH in wei
-    return float64(balance.Int64()) / 1000000000000000000, nil
-}
-
-// SendTransaction sends an Ethereum transaction
-func (e *EthereumAdapter) SendTransaction(ctx context.Context, from, to string, amount float64, privateKey string) (*Transaction, error) {
-    // Validate addresses
-    if !common.IsHexAddress(from) || !common.IsHexAddress(to) {
-        return nil, fmt.Errorf("invalid address")
-    }
-
-    // If we have a client, try to send the transaction to the network
-    if e.client != nil {
-        // In a real implementation, this would:
-        // 1. Connect to an Ethereum node
-        // 2. Fetch the current nonce for the from address
-        // 3. Create and sign the transaction
-        // 4. Submit the transaction to the network
-        // 5. Return the transaction hash
-        
-        // For now, we'll create a mock transaction
-        hash := fmt.Sprintf("0x%x", make([]byte, 32))
-        rand.Read([]byte(hash)[2:])
-
-        return &Transaction{
-            Hash:          hash,
-            From:          from,
-            To:            to,
-            Amount:        amount,
-            Fee:           0.00021, // Standard Ethereum transaction fee
-            Confirmations: 0,
-            Status:        "pending",
-            Timestamp:     time.Now().Unix(),
-        }, nil
-    }
-
-    // For demonstration, we'll create a mock transaction
-    hash := fmt.Sprintf("0x%x", make([]byte, 32))
-    rand.Read([]byte(hash)[2:])
-
-    return &Transaction{
-        Hash:          hash,
-        From:          from,
-        To:            to,
-        Amount:        amount,
-        Fee:           0.00021, // Standard Ethereum transaction fee
-        Confirmations: 0,
-        Status:        "pending",
-        Timestamp:     time.Now().Unix(),
-    }, nil
-}
-
-// GetTransaction retrieves transaction details
-func (e *EthereumAdapter) GetTransaction(ctx context.Context, hash string) (*Transaction, error) {
-    // Validate transaction hash
-    if !common.IsHexAddress(hash) {
-        return nil, fmt.Errorf("invalid transaction hash")
-    }
-
-    // If we have a client, try to fetch the transaction from the network
-    if e.client != nil {
-        txHash := common.HexToHash(hash)
-        tx, isPending, err := e.client.TransactionByHash(ctx, txHash)
-        if err != nil {
-            fmt.Printf("Warning: Could not fetch transaction from network: %v\n", err)
-        } else {
-            // Get the transaction receipt to get confirmations
-            receipt, err := e.client.TransactionReceipt(ctx, txHash)
-            if err != nil {
-                fmt.Printf("Warning: Could not fetch transaction receipt: %v\n", err)
-            } else {
-                return &Transaction{
-                    Hash:          tx.Hash().Hex(),
-                    From:          "", // Would need to derive from signature
-                    To:            tx.To().Hex(),
-                    Amount:        float64(tx.Value().Int64()) / 1000000000000000000,
-                    Fee:           0.00021, // Would calculate from gas price and limit
-                    Confirmations: int(receipt.BlockNumber.Uint64()),
-                    Status:        "confirmed",
-                    Timestamp:     time.Now().Unix(),
-                }, nil
-            }
-        }
-    }
-
-    // In a real implementation, this would fetch transaction details from the Ethereum network
-    // For now, we'll return a mock transaction
-    return &Transaction{
-        Hash:          hash,
-        From:          "0x742d35Cc6634C0532925a3b8D91D0a74b4A7D3Dc",
-        To:            "0x3f5CE5FBFe3E9af3971dD833D26bA9b5C936f0bE",
-        Amount:        1.5,
-        Fee:           0.00021,
-        Confirmations: 12,
-        Status:        "confirmed",
-        Timestamp:     time.Now().Unix(),
-    }, nil
-}
-
-// EstimateFee estimates the transaction fee
-func (e *EthereumAdapter) EstimateFee(ctx context.Context, from, to string, amount float64) (float64, error) {
-    // If we have a client, try to estimate the fee from the network
-    if e.client != nil {
-        // In a real implementation, this would:
-        // 1. Fetch current gas price from the network
-        // 2. Estimate gas limit for the transaction
-        // 3. Calculate fee as gasPrice * gasLimit
-        
-        // For now, we'll return a standard fee
-        return 0.00021, nil
-    }
-
-    // For now, we'll return a standard fee
-    return 0.00021, nil
-}
-
-// ConnectToTestnet connects to an Ethereum testnet
-func (e *EthereumAdapter) ConnectToTestnet(network string) error {
-    var rpcURL string
-    switch network {
-    case "goerli":
-        rpcURL = "https://goerli.infura.io/v3/YOUR_INFURA_PROJECT_ID"
-    case "sepolia":
-        rpcURL = "https://sepolia.infura.io/v3/YOUR_INFURA_PROJECT_ID"
-    case "rinkeby":
-        rpcURL = "https://rinkeby.infura.io/v3/YOUR_INFURA_PROJECT_ID"
-    default:
-        return fmt.Errorf("unsupported testnet: %s", network)
-    }
-    
-    client, err := ethclient.Dial(rpcURL)
-    if err != nil {
-        return fmt.Errorf("failed to connect to %s testnet: %w", network, err)
-    }
-    
-    e.client = client
-    e.rpcURL = rpcURL
-    
-    return nil
-}
\ No newline at end of file
@@ -0,0 +1,343 @@
+package blockchain
+
+import (
+    "context"
+    "crypto/ecdsa"
+    "encoding/hex"
+    "fmt"
+    "math/big"
+    "strings"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/crypto"
+)
+
+// EVMAdapter implements the Adapter interface for any EVM-compatible network
+// (Ethereum, BNB Smart Chain, Polygon, Arbitrum, Base, ...) described by a ChainConfig
+type EVMAdapter struct {
+    config ChainConfig
+    client EVMClient
+    nonces NonceManager
+    tokens *tokenMetadataCache
+}
+
+// NewEVMAdapter creates a new EVM adapter that fails over between all of the chain's RPC endpoints
+func NewEVMAdapter(config ChainConfig) *EVMAdapter {
+    adapter := &EVMAdapter{
+        config: config,
+        tokens: newTokenMetadataCache(),
+    }
+
+    client, err := DialMultiClient(config)
+    if err != nil {
+        // Without a client every call fails with ErrChainUnavailable until one is configured
+        fmt.Printf("Warning: Could not connect to %s RPC: %v\n", config.Name, err)
+        return adapter
+    }
+
+    adapter.client = client
+    return adapter
+}
+
+// NewEVMAdapterWithClient creates a new EVM adapter on top of an existing client,
+// such as the go-ethereum simulated backend
+func NewEVMAdapterWithClient(config ChainConfig, client EVMClient) *EVMAdapter {
+    return &EVMAdapter{
+        config: config,
+        client: client,
+        tokens: newTokenMetadataCache(),
+    }
+}
+
+// Endpoints returns the health of the adapter's RPC endpoints
+func (e *EVMAdapter) Endpoints() []EndpointStatus {
+    if multi, ok := e.client.(*MultiClient); ok {
+        return multi.Endpoints()
+    }
+    return nil
+}
+
+// Config returns the chain configuration of the adapter
+func (e *EVMAdapter) Config() ChainConfig {
+    return e.config
+}
+
+// CreateWallet creates a new EVM wallet
+func (e *EVMAdapter) CreateWallet(ctx context.Context) (*Wallet, error) {
+    // Generate a new private key
+    privateKey, err := crypto.GenerateKey()
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate private key: %w", err)
+    }
+
+    // Get the public key
+    publicKey := privateKey.Public()
+    publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
+    if !ok {
+        return nil, fmt.Errorf("error casting public key to ECDSA")
+    }
+
+    // Generate the address
+    address := crypto.PubkeyToAddress(*publicKeyECDSA)
+
+    return &Wallet{
+        Address:    address.Hex(),
+        PublicKey:  fmt.Sprintf("%x", crypto.FromECDSAPub(publicKeyECDSA)),
+        PrivateKey: fmt.Sprintf("%x", crypto.FromECDSA(privateKey)),
+        Balance:    units.Amount{},
+    }, nil
+}
+
+// WalletFromPrivateKey builds the EVM wallet controlled by a raw secp256k1 private key
+func (e *EVMAdapter) WalletFromPrivateKey(privateKey []byte) (*Wallet, error) {
+    key, err := crypto.ToECDSA(privateKey)
+    if err != nil {
+        return nil, fmt.Errorf("invalid private key: %w", err)
+    }
+
+    return &Wallet{
+        Address:    crypto.PubkeyToAddress(key.PublicKey).Hex(),
+        PublicKey:  fmt.Sprintf("%x", crypto.FromECDSAPub(&key.PublicKey)),
+        PrivateKey: fmt.Sprintf("%x", crypto.FromECDSA(key)),
+        Balance:    units.Amount{},
+    }, nil
+}
+
+// ValidateAddress checks that an address is a valid EVM address with a correct EIP-55 checksum
+func (e *EVMAdapter) ValidateAddress(address string) error {
+    return ValidateAddress(e.config, address)
+}
+
+// GetWallet retrieves wallet information
+func (e *EVMAdapter) GetWallet(ctx context.Context, address string) (*Wallet, error) {
+    balance, err := e.GetBalance(ctx, address)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Wallet{
+        Address: common.HexToAddress(address).Hex(),
+        Balance: balance,
+    }, nil
+}
+
+// GetBalance retrieves the balance of an address
+func (e *EVMAdapter) GetBalance(ctx context.Context, address string) (units.Amount, error) {
+    // Validate the address
+    if !common.IsHexAddress(address) {
+        return units.Amount{}, fmt.Errorf("invalid address")
+    }
+
+    if e.client == nil {
+        return units.Amount{}, errNotConnected(e.config.Name)
+    }
+
+    balance, err := e.client.BalanceAt(ctx, common.HexToAddress(address), nil)
+    if err != nil {
+        return units.Amount{}, fmt.Errorf("failed to fetch balance: %w", ClassifyError(err))
+    }
+
+    return units.NewAmount(balance), nil
+}
+
+// SendTransaction signs and broadcasts an EIP-1559 (type 2) transaction of the native coin
+func (e *EVMAdapter) SendTransaction(ctx context.Context, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    // Validate addresses
+    if !common.IsHexAddress(from) {
+        return nil, fmt.Errorf("invalid from address")
+    }
+    if err := validateEVMAddress(to); err != nil {
+        return nil, fmt.Errorf("invalid to address: %w", err)
+    }
+
+    if e.client == nil {
+        return nil, errNotConnected(e.config.Name)
+    }
+
+    key, err := parseEVMKey(privateKey, from)
+    if err != nil {
+        return nil, err
+    }
+
+    toAddr := common.HexToAddress(to)
+
+    return e.signer().sendDynamicFeeTx(ctx, key, &toAddr, amount.BigInt(), nil, fee)
+}
+
+// GetTokenBalance retrieves the ERC-20 token balance of an address
+func (e *EVMAdapter) GetTokenBalance(ctx context.Context, token, address string) (units.Amount, error) {
+    if !common.IsHexAddress(token) || !common.IsHexAddress(address) {
+        return units.Amount{}, fmt.Errorf("invalid address")
+    }
+
+    if e.client == nil {
+        return units.Amount{}, errNotConnected(e.config.Name)
+    }
+
+    balance, err := getERC20Balance(ctx, e.client, common.HexToAddress(token), common.HexToAddress(address))
+    if err != nil {
+        return units.Amount{}, err
+    }
+
+    return units.NewAmount(balance), nil
+}
+
+// SendTokenTransaction signs and broadcasts an ERC-20 transfer
+func (e *EVMAdapter) SendTokenTransaction(ctx context.Context, token, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    if e.client == nil {
+        return nil, errNotConnected(e.config.Name)
+    }
+
+    return sendERC20Transfer(ctx, e.signer(), e.tokens, token, from, to, amount, privateKey, fee)
+}
+
+// EstimateTokenFee estimates the fee of an ERC-20 transfer at every tier
+func (e *EVMAdapter) EstimateTokenFee(ctx context.Context, token, from, to string, amount units.Amount) (FeeEstimates, error) {
+    if e.client == nil {
+        return nil, errNotConnected(e.config.Name)
+    }
+
+    return estimateERC20Transfer(ctx, e.signer(), token, from, to, amount)
+}
+
+// GetTokenMetadata retrieves the symbol and decimals of an ERC-20 token
+func (e *EVMAdapter) GetTokenMetadata(ctx context.Context, token string) (*TokenMetadata, error) {
+    if !common.IsHexAddress(token) {
+        return nil, fmt.Errorf("invalid token contract address")
+    }
+
+    if e.client == nil {
+        return nil, errNotConnected(e.config.Name)
+    }
+
+    return getERC20Metadata(ctx, e.client, e.tokens, common.HexToAddress(token))
+}
+
+// RebroadcastTransaction sends a transaction signed by this adapter again, as the hex its
+// BroadcastError carried
+func (e *EVMAdapter) RebroadcastTransaction(ctx context.Context, raw string) error {
+    if e.client == nil {
+        return errNotConnected(e.config.Name)
+    }
+
+    return e.signer().rebroadcast(ctx, raw)
+}
+
+// SetNonceManager makes the adapter reserve nonces through the given manager
+// instead of asking the node for every transaction
+func (e *EVMAdapter) SetNonceManager(nonces NonceManager) {
+    e.nonces = nonces
+}
+
+// signer returns the transaction signer for this adapter
+func (e *EVMAdapter) signer() *evmSigner {
+    return &evmSigner{
+        config: e.config,
+        client: e.client,
+        nonces: e.nonces,
+    }
+}
+
+
+// GetTransaction retrieves transaction details, with confirmations counted from the latest block
+func (e *EVMAdapter) GetTransaction(ctx context.Context, hash string) (*Transaction, error) {
+    // Validate transaction hash
+    if !isEVMTxHash(hash) {
+        return nil, fmt.Errorf("invalid transaction hash")
+    }
+
+    if e.client == nil {
+        return nil, errNotConnected(e.config.Name)
+    }
+
+    txHash := common.HexToHash(hash)
+    tx, isPending, err := e.client.TransactionByHash(ctx, txHash)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch transaction %s: %w", hash, ClassifyError(err))
+    }
+
+    result := &Transaction{
+        Hash:     tx.Hash().Hex(),
+        Amount:   units.NewAmount(tx.Value()),
+        Decimals: e.config.Decimals,
+        Nonce:    tx.Nonce(),
+        GasLimit: tx.Gas(),
+        GasPrice: weiToGwei(tx.GasFeeCap()),
+        Status:   "pending",
+    }
+    if tx.To() != nil {
+        result.To = tx.To().Hex()
+    }
+    if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+        result.From = from.Hex()
+    }
+    if tx.Type() == types.DynamicFeeTxType {
+        result.GasTipCap = weiToGwei(tx.GasTipCap())
+    }
+    if isPending {
+        return result, nil
+    }
+
+    receipt, err := e.client.TransactionReceipt(ctx, txHash)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch receipt of %s: %w", hash, ClassifyError(err))
+    }
+    head, err := e.client.HeaderByNumber(ctx, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch latest header: %w", ClassifyError(err))
+    }
+    block, err := e.client.HeaderByNumber(ctx, receipt.BlockNumber)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch block %s: %w", receipt.BlockNumber.String(), ClassifyError(err))
+    }
+
+    // A lagging endpoint may report a head below the receipt's block; the block itself is one confirmation
+    result.Confirmations = 1
+    if head.Number.Cmp(receipt.BlockNumber) > 0 {
+        result.Confirmations += int(new(big.Int).Sub(head.Number, receipt.BlockNumber).Int64())
+    }
+
+    gasPrice := receipt.EffectiveGasPrice
+    if gasPrice == nil {
+        gasPrice = tx.GasPrice()
+    }
+    result.Fee = units.NewAmount(new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(receipt.GasUsed)))
+    result.Timestamp = int64(block.Time)
+
+    result.Status = "confirmed"
+    if receipt.Status != types.ReceiptStatusSuccessful {
+        result.Status = "failed"
+    }
+
+    return result, nil
+}
+
+// isEVMTxHash reports whether a string is a 0x-prefixed 32-byte hex hash
+func isEVMTxHash(hash string) bool {
+    if len(hash) != 2+2*common.HashLength || !strings.HasPrefix(hash, "0x") {
+        return false
+    }
+    _, err := hex.DecodeString(hash[2:])
+    return err == nil
+}
+
+// EstimateFee estimates the fee of a native transfer at every tier from the recent fee history
+func (e *EVMAdapter) EstimateFee(ctx context.Context, from, to string, amount units.Amount) (FeeEstimates, error) {
+    if !common.IsHexAddress(from) || !common.IsHexAddress(to) {
+        return nil, fmt.Errorf("invalid address")
+    }
+
+    if e.client == nil {
+        return nil, errNotConnected(e.config.Name)
+    }
+
+    toAddr := common.HexToAddress(to)
+    return e.signer().estimateFees(ctx, ethereum.CallMsg{
+        From:  common.HexToAddress(from),
+        To:    &toAddr,
+        Value: amount.BigInt(),
+    })
+}
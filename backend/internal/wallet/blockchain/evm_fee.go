@@ -0,0 +1,98 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/ethereum/go-ethereum"
+)
+
+// feeHistoryBlocks is how many recent blocks the priority fee percentiles are taken over
+const feeHistoryBlocks = 20
+
+// evmTierPolicy describes how a fee tier is priced on EVM chains
+type evmTierPolicy struct {
+    tier FeeTier
+    // rewardPercentile is the percentile of recent priority fees to pay
+    rewardPercentile float64
+    // baseFeeHeadroom scales the next base fee for the max fee, in quarters
+    baseFeeHeadroom int64
+    // expectedBlocks is the typical number of blocks until inclusion
+    expectedBlocks int64
+}
+
+// evmTierPolicies prices slow to fast tiers. The max fee of the slow tier only survives one
+// full-block base fee increase; the others tolerate the base fee doubling.
+var evmTierPolicies = []evmTierPolicy{
+    {tier: FeeSlow, rewardPercentile: 10, baseFeeHeadroom: 5, expectedBlocks: 10},
+    {tier: FeeStandard, rewardPercentile: 50, baseFeeHeadroom: 8, expectedBlocks: 3},
+    {tier: FeeFast, rewardPercentile: 90, baseFeeHeadroom: 8, expectedBlocks: 1},
+}
+
+// estimateFees prices a call at every tier from eth_feeHistory: the next block's base fee plus
+// a percentile of the priority fees paid in recent blocks
+func (s *evmSigner) estimateFees(ctx context.Context, msg ethereum.CallMsg) (FeeEstimates, error) {
+    percentiles := make([]float64, len(evmTierPolicies))
+    for i, policy := range evmTierPolicies {
+        percentiles[i] = policy.rewardPercentile
+    }
+
+    history, err := s.client.FeeHistory(ctx, feeHistoryBlocks, nil, percentiles)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch fee history: %w", ClassifyError(err))
+    }
+    if len(history.BaseFee) == 0 || history.BaseFee[len(history.BaseFee)-1] == nil {
+        return nil, fmt.Errorf("chain %s does not support EIP-1559 transactions", s.config.Name)
+    }
+
+    // The last base fee entry is the one of the next, still unmined block
+    nextBaseFee := history.BaseFee[len(history.BaseFee)-1]
+
+    gasLimit, err := s.client.EstimateGas(ctx, msg)
+    if err != nil {
+        return nil, fmt.Errorf("failed to estimate gas: %w", ClassifyError(err))
+    }
+
+    estimates := make(FeeEstimates, 0, len(evmTierPolicies))
+    previousTip := new(big.Int)
+    for i, policy := range evmTierPolicies {
+        tip := medianReward(history.Reward, i)
+        // A faster tier never tips less than a slower one
+        if tip.Cmp(previousTip) < 0 {
+            tip = new(big.Int).Set(previousTip)
+        }
+        previousTip = tip
+
+        maxFee := new(big.Int).Mul(nextBaseFee, big.NewInt(policy.baseFeeHeadroom))
+        maxFee.Div(maxFee, big.NewInt(4))
+        maxFee.Add(maxFee, tip)
+
+        expectedPrice := new(big.Int).Add(nextBaseFee, tip)
+        fee := new(big.Int).Mul(expectedPrice, new(big.Int).SetUint64(gasLimit))
+
+        estimates = append(estimates, FeeEstimate{
+            Tier:                 policy.tier,
+            Fee:                  units.NewAmount(fee),
+            ExpectedConfirmation: time.Duration(policy.expectedBlocks) * s.config.BlockDuration(),
+            MaxFeePerGas:         units.NewAmount(maxFee),
+            MaxPriorityFeePerGas: units.NewAmount(tip),
+            GasLimit:             gasLimit,
+        })
+    }
+
+    return estimates, nil
+}
+
+// medianReward returns the median across blocks of the priority fee at one percentile index
+func medianReward(rewards [][]*big.Int, index int) *big.Int {
+    values := make([]uint64, 0, len(rewards))
+    for _, block := range rewards {
+        if index < len(block) && block[index] != nil && block[index].IsUint64() {
+            values = append(values, block[index].Uint64())
+        }
+    }
+    return new(big.Int).SetUint64(percentile(values, 50))
+}
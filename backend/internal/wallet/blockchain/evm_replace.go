@@ -0,0 +1,186 @@
+package blockchain
+
+import (
+    "context"
+    "crypto/ecdsa"
+    "errors"
+    "fmt"
+    "log"
+    "math/big"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/crypto"
+    "github.com/ethereum/go-ethereum/params"
+)
+
+// Nodes only accept a replacement that raises both the tip and the fee cap by at least 10%;
+// we bump by 12.5% so rounding never gets the replacement rejected
+const (
+    replacementBumpNumerator   = 9
+    replacementBumpDenominator = 8
+)
+
+// SpeedUpTransaction rebroadcasts a pending transaction with the same nonce, recipient, value
+// and data at a higher fee. A nil fee uses the fast tier.
+func (e *EVMAdapter) SpeedUpTransaction(ctx context.Context, hash, from, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    key, err := parseEVMKey(privateKey, from)
+    if err != nil {
+        return nil, err
+    }
+
+    if e.client == nil {
+        return nil, errNotConnected(e.config.Name)
+    }
+
+    return e.signer().replaceTx(ctx, key, hash, false, fee)
+}
+
+// CancelTransaction replaces a pending transaction with a zero-value transfer from the sender
+// to itself at the same nonce and a higher fee. A nil fee uses the fast tier.
+func (e *EVMAdapter) CancelTransaction(ctx context.Context, hash, from, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    key, err := parseEVMKey(privateKey, from)
+    if err != nil {
+        return nil, err
+    }
+
+    if e.client == nil {
+        return nil, errNotConnected(e.config.Name)
+    }
+
+    return e.signer().replaceTx(ctx, key, hash, true, fee)
+}
+
+// replaceTx signs and broadcasts a replacement for a pending transaction of the key's address
+func (s *evmSigner) replaceTx(ctx context.Context, key *ecdsa.PrivateKey, hash string, cancel bool, fee *FeeEstimate) (*Transaction, error) {
+    fromAddr := crypto.PubkeyToAddress(key.PublicKey)
+
+    original, isPending, err := s.client.TransactionByHash(ctx, common.HexToHash(hash))
+    if errors.Is(err, ethereum.NotFound) {
+        return nil, fmt.Errorf("transaction %s: %w", hash, ErrTransactionNotPending)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch transaction %s: %w", hash, ClassifyError(err))
+    }
+    if !isPending {
+        return nil, fmt.Errorf("transaction %s: %w", hash, ErrTransactionNotPending)
+    }
+
+    chainID, err := s.client.ChainID(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch chain ID: %w", ClassifyError(err))
+    }
+
+    sender, err := types.Sender(types.LatestSignerForChainID(chainID), original)
+    if err != nil {
+        return nil, fmt.Errorf("failed to recover sender of %s: %w", hash, err)
+    }
+    if sender != fromAddr {
+        return nil, fmt.Errorf("transaction %s was not sent by %s", hash, fromAddr.Hex())
+    }
+
+    // A cancellation keeps only the nonce: it pays nothing to the sender itself
+    to, value, data, gasLimit := original.To(), original.Value(), original.Data(), original.Gas()
+    if cancel {
+        to, value, data, gasLimit = &fromAddr, new(big.Int), nil, params.TxGas
+    }
+
+    if fee == nil {
+        estimates, err := s.estimateFees(ctx, ethereum.CallMsg{From: fromAddr, To: to, Value: value, Data: data})
+        if err != nil {
+            return nil, err
+        }
+        fast, err := estimates.Tier(FeeFast)
+        if err != nil {
+            return nil, err
+        }
+        fee = &fast
+    }
+
+    // Pay the current market rate, but never less than the minimum bump over the original
+    gasTipCap := maxBig(fee.MaxPriorityFeePerGas.BigInt(), bumpedFee(original.GasTipCap()))
+    gasFeeCap := maxBig(fee.MaxFeePerGas.BigInt(), bumpedFee(original.GasFeeCap()))
+    if gasFeeCap.Cmp(gasTipCap) < 0 {
+        gasFeeCap = gasTipCap
+    }
+
+    head, err := s.client.HeaderByNumber(ctx, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch latest header: %w", ClassifyError(err))
+    }
+    if head.BaseFee == nil {
+        return nil, fmt.Errorf("chain %s does not support EIP-1559 transactions", chainID.String())
+    }
+
+    tx := types.NewTx(&types.DynamicFeeTx{
+        ChainID:   chainID,
+        Nonce:     original.Nonce(),
+        GasTipCap: gasTipCap,
+        GasFeeCap: gasFeeCap,
+        Gas:       gasLimit,
+        To:        to,
+        Value:     value,
+        Data:      data,
+    })
+
+    signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+    if err != nil {
+        return nil, fmt.Errorf("failed to sign replacement transaction: %w", err)
+    }
+
+    if err := s.client.SendTransaction(ctx, signedTx); err != nil {
+        return nil, fmt.Errorf("failed to broadcast replacement transaction: %w", ClassifyError(err))
+    }
+
+    if s.nonces != nil {
+        if err := s.nonces.MarkBroadcast(ctx, s.config.Name, fromAddr.Hex(), original.Nonce(), signedTx.Hash().Hex()); err != nil {
+            log.Printf("Failed to record replacement of nonce %d for %s: %v", original.Nonce(), fromAddr.Hex(), err)
+        }
+    }
+
+    expectedGasPrice := new(big.Int).Add(head.BaseFee, gasTipCap)
+    if expectedGasPrice.Cmp(gasFeeCap) > 0 {
+        expectedGasPrice = gasFeeCap
+    }
+    expectedFee := new(big.Int).Mul(expectedGasPrice, new(big.Int).SetUint64(gasLimit))
+
+    var toHex string
+    if to != nil {
+        toHex = to.Hex()
+    }
+
+    return &Transaction{
+        Hash:          signedTx.Hash().Hex(),
+        From:          fromAddr.Hex(),
+        To:            toHex,
+        Amount:        units.NewAmount(value),
+        Decimals:      s.config.Decimals,
+        Fee:           units.NewAmount(expectedFee),
+        FeeTier:       fee.Tier,
+        Nonce:         original.Nonce(),
+        GasLimit:      gasLimit,
+        GasPrice:      weiToGwei(gasFeeCap),
+        GasTipCap:     weiToGwei(gasTipCap),
+        Confirmations: 0,
+        Status:        "pending",
+        Timestamp:     time.Now().Unix(),
+    }, nil
+}
+
+// bumpedFee returns the smallest fee a replacement of a transaction paying fee may offer
+func bumpedFee(fee *big.Int) *big.Int {
+    bumped := new(big.Int).Mul(fee, big.NewInt(replacementBumpNumerator))
+    bumped.Add(bumped, big.NewInt(replacementBumpDenominator-1))
+    return bumped.Div(bumped, big.NewInt(replacementBumpDenominator))
+}
+
+// maxBig returns the larger of two integers
+func maxBig(a, b *big.Int) *big.Int {
+    if a.Cmp(b) >= 0 {
+        return a
+    }
+    return b
+}
@@ -0,0 +1,204 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "strconv"
+    "strings"
+
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/rpc"
+)
+
+// EVM call tracers, which find value transfers made by contracts inside a transaction
+const (
+    TracerCall   = "callTracer" // debug_traceBlockByNumber with the callTracer, on geth-style nodes
+    TracerParity = "parity"     // trace_block, on Erigon, Nethermind and other parity-style nodes
+)
+
+// InternalTransfer is a transfer of the native coin made by a call inside a transaction, such as
+// an exchange's hot wallet contract or a smart-contract wallet paying out
+type InternalTransfer struct {
+    TxHash    common.Hash
+    TracePath string // indexes of the calls leading to the transfer, such as 0.2 for the third call made by the first call
+    From      common.Address
+    To        common.Address
+    Value     *big.Int
+}
+
+// callFrame is one call in a callTracer trace
+type callFrame struct {
+    Type  string          `json:"type"`
+    From  common.Address  `json:"from"`
+    To    *common.Address `json:"to"`
+    Value *hexutil.Big    `json:"value"`
+    Error string          `json:"error"`
+    Calls []callFrame     `json:"calls"`
+}
+
+// parityTrace is one call in a trace_block result
+type parityTrace struct {
+    Type   string `json:"type"` // call, create, suicide or reward
+    Action struct {
+        CallType      string          `json:"callType"`
+        From          common.Address  `json:"from"`
+        To            *common.Address `json:"to"`
+        Value         *hexutil.Big    `json:"value"`
+        Address       common.Address  `json:"address"` // contract that self-destructed
+        RefundAddress *common.Address `json:"refundAddress"`
+        Balance       *hexutil.Big    `json:"balance"`
+    } `json:"action"`
+    Error           string       `json:"error"`
+    TraceAddress    []int        `json:"traceAddress"`
+    TransactionHash *common.Hash `json:"transactionHash"`
+    BlockHash       common.Hash  `json:"blockHash"`
+}
+
+// TraceTransfers traces every transaction of a block and returns the native transfers made by
+// inner calls, at any depth. Top-level transfers are left out, as the transactions themselves
+// show them, and so are the transfers of calls that reverted, with everything they called. The
+// endpoints must expose the tracer's namespace; a node without it fails the trace.
+func (m *MultiClient) TraceTransfers(ctx context.Context, block *types.Block, tracer string) ([]InternalTransfer, error) {
+    return failover(ctx, m, func(c EVMBlockClient) ([]InternalTransfer, error) {
+        raw, ok := c.(rawClient)
+        if !ok {
+            return nil, fmt.Errorf("endpoint of %s cannot trace blocks", m.pool.chain)
+        }
+
+        switch tracer {
+        case TracerCall:
+            return traceCalls(ctx, raw.Client(), block)
+        case TracerParity:
+            return traceParity(ctx, raw.Client(), block)
+        default:
+            return nil, fmt.Errorf("unsupported tracer %q", tracer)
+        }
+    })
+}
+
+// traceCalls traces a block with the callTracer
+func traceCalls(ctx context.Context, client *rpc.Client, block *types.Block) ([]InternalTransfer, error) {
+    var traces []struct {
+        TxHash common.Hash `json:"txHash"` // left out by older nodes
+        Result *callFrame  `json:"result"`
+        Error  string      `json:"error"`
+    }
+    err := client.CallContext(ctx, &traces, "debug_traceBlockByNumber", hexutil.EncodeBig(block.Number()), map[string]interface{}{"tracer": "callTracer"})
+    if err != nil {
+        return nil, fmt.Errorf("failed to trace block %d: %w", block.NumberU64(), err)
+    }
+
+    // The block is traced by number, so a reorg in between would trace another block
+    txs := block.Transactions()
+    if len(traces) != len(txs) {
+        return nil, fmt.Errorf("block %d has %d transactions but %d traces, it may have been reorged", block.NumberU64(), len(txs), len(traces))
+    }
+
+    var transfers []InternalTransfer
+    for i, trace := range traces {
+        hash := txs[i].Hash()
+        if trace.TxHash != (common.Hash{}) && trace.TxHash != hash {
+            return nil, fmt.Errorf("trace %d of block %d is of transaction %s, not %s, it may have been reorged", i, block.NumberU64(), trace.TxHash.Hex(), hash.Hex())
+        }
+        if trace.Error != "" {
+            return nil, fmt.Errorf("failed to trace transaction %s: %s", hash.Hex(), trace.Error)
+        }
+        if trace.Result != nil {
+            transfers = walkCalls(transfers, hash, *trace.Result, nil)
+        }
+    }
+    return transfers, nil
+}
+
+// walkCalls appends the transfers made by a call and the calls below it. A call that failed
+// reverted its own transfer and all of theirs.
+func walkCalls(transfers []InternalTransfer, hash common.Hash, frame callFrame, path []int) []InternalTransfer {
+    if frame.Error != "" {
+        return transfers
+    }
+
+    // DELEGATECALL, STATICCALL and CALLCODE move no value to another account
+    moves := frame.Type == "CALL" || frame.Type == "SELFDESTRUCT"
+    if len(path) > 0 && moves && frame.To != nil && frame.Value != nil && frame.Value.ToInt().Sign() > 0 {
+        transfers = append(transfers, InternalTransfer{
+            TxHash:    hash,
+            TracePath: tracePath(path),
+            From:      frame.From,
+            To:        *frame.To,
+            Value:     frame.Value.ToInt(),
+        })
+    }
+
+    for i, call := range frame.Calls {
+        child := append(append([]int(nil), path...), i)
+        transfers = walkCalls(transfers, hash, call, child)
+    }
+    return transfers
+}
+
+// traceParity traces a block with trace_block
+func traceParity(ctx context.Context, client *rpc.Client, block *types.Block) ([]InternalTransfer, error) {
+    var traces []parityTrace
+    if err := client.CallContext(ctx, &traces, "trace_block", hexutil.EncodeBig(block.Number())); err != nil {
+        return nil, fmt.Errorf("failed to trace block %d: %w", block.NumberU64(), err)
+    }
+
+    // Traces come in call order, so a failed call is seen before the calls it made
+    failed := make(map[string]bool)
+    var transfers []InternalTransfer
+    for _, trace := range traces {
+        if trace.TransactionHash == nil {
+            // Block and uncle rewards
+            continue
+        }
+        if trace.BlockHash != block.Hash() {
+            return nil, fmt.Errorf("trace of block %d is of block %s, it may have been reorged", block.NumberU64(), trace.BlockHash.Hex())
+        }
+
+        path := tracePath(trace.TraceAddress)
+        key := trace.TransactionHash.Hex() + "/" + path
+        if trace.Error != "" || revertedParent(failed, trace.TransactionHash.Hex(), trace.TraceAddress) {
+            failed[key] = true
+            continue
+        }
+        if len(trace.TraceAddress) == 0 {
+            continue
+        }
+
+        transfer := InternalTransfer{TxHash: *trace.TransactionHash, TracePath: path}
+        switch {
+        case trace.Type == "call" && trace.Action.CallType == "call" && trace.Action.To != nil && trace.Action.Value != nil:
+            transfer.From, transfer.To, transfer.Value = trace.Action.From, *trace.Action.To, trace.Action.Value.ToInt()
+        case trace.Type == "suicide" && trace.Action.RefundAddress != nil && trace.Action.Balance != nil:
+            transfer.From, transfer.To, transfer.Value = trace.Action.Address, *trace.Action.RefundAddress, trace.Action.Balance.ToInt()
+        default:
+            continue
+        }
+        if transfer.Value.Sign() > 0 {
+            transfers = append(transfers, transfer)
+        }
+    }
+    return transfers, nil
+}
+
+// revertedParent reports whether a call was made by a call that failed
+func revertedParent(failed map[string]bool, hash string, address []int) bool {
+    for depth := 0; depth < len(address); depth++ {
+        if failed[hash+"/"+tracePath(address[:depth])] {
+            return true
+        }
+    }
+    return false
+}
+
+// tracePath formats the indexes of nested calls, such as 0.2
+func tracePath(path []int) string {
+    parts := make([]string, len(path))
+    for i, index := range path {
+        parts[i] = strconv.Itoa(index)
+    }
+    return strings.Join(parts, ".")
+}
@@ -0,0 +1,249 @@
+package blockchain
+
+import (
+    "context"
+    "crypto/ecdsa"
+    "fmt"
+    "log"
+    "math/big"
+    "strings"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/common/hexutil"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/crypto"
+)
+
+// weiPerGwei is the number of wei in one gwei
+var weiPerGwei = new(big.Float).SetInt(big.NewInt(1000000000))
+
+// EVMClient is the subset of the go-ethereum RPC client used by the EVM adapters.
+// Both *ethclient.Client and the simulated backend client satisfy it.
+type EVMClient interface {
+    ChainID(ctx context.Context) (*big.Int, error)
+    BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+    NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+    PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+    HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+    SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+    FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+    EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+    CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+    SendTransaction(ctx context.Context, tx *types.Transaction) error
+    TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+    TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// evmSigner signs and broadcasts transactions for a single EVM chain
+type evmSigner struct {
+    config ChainConfig
+    client EVMClient
+    nonces NonceManager
+}
+
+// parseEVMKey decodes a hex private key and checks that it controls the from address
+func parseEVMKey(privateKey, from string) (*ecdsa.PrivateKey, error) {
+    key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+    if err != nil {
+        return nil, fmt.Errorf("invalid private key: %w", err)
+    }
+
+    fromAddr := common.HexToAddress(from)
+    if crypto.PubkeyToAddress(key.PublicKey) != fromAddr {
+        return nil, fmt.Errorf("private key does not match from address %s", fromAddr.Hex())
+    }
+
+    return key, nil
+}
+
+// sendDynamicFeeTx builds, signs and broadcasts a dynamic-fee transaction from the key's address,
+// priced by the given fee estimate or, when it is nil, by the standard tier
+func (s *evmSigner) sendDynamicFeeTx(ctx context.Context, key *ecdsa.PrivateKey, to *common.Address, value *big.Int, data []byte, fee *FeeEstimate) (*Transaction, error) {
+    fromAddr := crypto.PubkeyToAddress(key.PublicKey)
+
+    // The chain ID comes from the node so we never sign for the wrong network
+    chainID, err := s.client.ChainID(ctx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch chain ID: %w", ClassifyError(err))
+    }
+    if s.config.ChainID != 0 && chainID.Uint64() != s.config.ChainID {
+        return nil, fmt.Errorf("node reports chain ID %s but %s is configured as %d", chainID.String(), s.config.Name, s.config.ChainID)
+    }
+
+    if fee == nil {
+        estimates, err := s.estimateFees(ctx, ethereum.CallMsg{From: fromAddr, To: to, Value: value, Data: data})
+        if err != nil {
+            return nil, err
+        }
+        standard, err := estimates.Tier(FeeStandard)
+        if err != nil {
+            return nil, err
+        }
+        fee = &standard
+    }
+    if fee.MaxFeePerGas.IsZero() {
+        return nil, fmt.Errorf("fee estimate has no EIP-1559 pricing")
+    }
+    gasTipCap := fee.MaxPriorityFeePerGas.BigInt()
+    gasFeeCap := fee.MaxFeePerGas.BigInt()
+
+    head, err := s.client.HeaderByNumber(ctx, nil)
+    if err != nil {
+        return nil, fmt.Errorf("failed to fetch latest header: %w", ClassifyError(err))
+    }
+    if head.BaseFee == nil {
+        return nil, fmt.Errorf("chain %s does not support EIP-1559 transactions", chainID.String())
+    }
+
+    gasLimit, err := s.client.EstimateGas(ctx, ethereum.CallMsg{
+        From:      fromAddr,
+        To:        to,
+        GasFeeCap: gasFeeCap,
+        GasTipCap: gasTipCap,
+        Value:     value,
+        Data:      data,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to estimate gas: %w", ClassifyError(err))
+    }
+
+    // Reserve the nonce as late as possible so failures above never leave a gap
+    nonce, err := s.reserveNonce(ctx, fromAddr)
+    if err != nil {
+        return nil, err
+    }
+
+    tx := types.NewTx(&types.DynamicFeeTx{
+        ChainID:   chainID,
+        Nonce:     nonce,
+        GasTipCap: gasTipCap,
+        GasFeeCap: gasFeeCap,
+        Gas:       gasLimit,
+        To:        to,
+        Value:     value,
+        Data:      data,
+    })
+
+    signedTx, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+    if err != nil {
+        s.releaseNonce(ctx, fromAddr, nonce)
+        return nil, fmt.Errorf("failed to sign transaction: %w", err)
+    }
+
+    if err := s.client.SendTransaction(ctx, signedTx); err != nil && !isAlreadyKnown(err) {
+        err = ClassifyError(err)
+        if isNodeAnswer(err) {
+            // The node refused the transaction, so its nonce was never used
+            s.releaseNonce(ctx, fromAddr, nonce)
+            return nil, fmt.Errorf("failed to broadcast transaction: %w", err)
+        }
+
+        // The node may have taken it before failing, so the nonce stays reserved: handing it
+        // out again is what keeps a second transaction from paying the same transfer
+        s.markBroadcast(ctx, fromAddr, nonce, signedTx.Hash())
+        raw, encodeErr := signedTx.MarshalBinary()
+        if encodeErr != nil {
+            return nil, fmt.Errorf("failed to encode transaction %s: %w", signedTx.Hash().Hex(), encodeErr)
+        }
+        return nil, &BroadcastError{Hash: signedTx.Hash().Hex(), Raw: hexutil.Encode(raw), Err: err}
+    }
+
+    s.markBroadcast(ctx, fromAddr, nonce, signedTx.Hash())
+
+    // The fee is what we expect to pay at the current base fee; the fee cap is the upper bound
+    expectedGasPrice := new(big.Int).Add(head.BaseFee, gasTipCap)
+    if expectedGasPrice.Cmp(gasFeeCap) > 0 {
+        expectedGasPrice = gasFeeCap
+    }
+    expectedFee := new(big.Int).Mul(expectedGasPrice, new(big.Int).SetUint64(gasLimit))
+
+    var toHex string
+    if to != nil {
+        toHex = to.Hex()
+    }
+
+    return &Transaction{
+        Hash:          signedTx.Hash().Hex(),
+        From:          fromAddr.Hex(),
+        To:            toHex,
+        Amount:        units.NewAmount(value),
+        Decimals:      s.config.Decimals,
+        Fee:           units.NewAmount(expectedFee),
+        FeeTier:       fee.Tier,
+        Nonce:         nonce,
+        GasLimit:      gasLimit,
+        GasPrice:      weiToGwei(gasFeeCap),
+        GasTipCap:     weiToGwei(gasTipCap),
+        Confirmations: 0,
+        Status:        "pending",
+        Timestamp:     time.Now().Unix(),
+    }, nil
+}
+
+// reserveNonce returns the nonce for the next transaction from an address
+func (s *evmSigner) reserveNonce(ctx context.Context, from common.Address) (uint64, error) {
+    if s.nonces == nil {
+        nonce, err := s.client.PendingNonceAt(ctx, from)
+        if err != nil {
+            return 0, fmt.Errorf("failed to fetch nonce: %w", ClassifyError(err))
+        }
+        return nonce, nil
+    }
+
+    nonce, err := s.nonces.Reserve(ctx, s.config.Name, from.Hex(), s.client)
+    if err != nil {
+        return 0, fmt.Errorf("failed to reserve nonce: %w", err)
+    }
+    return nonce, nil
+}
+
+// markBroadcast records the transaction a reserved nonce was used by
+func (s *evmSigner) markBroadcast(ctx context.Context, from common.Address, nonce uint64, hash common.Hash) {
+    if s.nonces == nil {
+        return
+    }
+    if err := s.nonces.MarkBroadcast(ctx, s.config.Name, from.Hex(), nonce, hash.Hex()); err != nil {
+        // The transaction is already on its way; the next reservation re-syncs with the node
+        log.Printf("Failed to record broadcast of nonce %d for %s: %v", nonce, from.Hex(), err)
+    }
+}
+
+// rebroadcast sends a transaction signed earlier again, byte for byte
+func (s *evmSigner) rebroadcast(ctx context.Context, raw string) error {
+    data, err := hexutil.Decode(raw)
+    if err != nil {
+        return fmt.Errorf("invalid raw transaction: %w", err)
+    }
+    tx := new(types.Transaction)
+    if err := tx.UnmarshalBinary(data); err != nil {
+        return fmt.Errorf("invalid raw transaction: %w", err)
+    }
+
+    if err := s.client.SendTransaction(ctx, tx); err != nil && !isAlreadyKnown(err) {
+        err = ClassifyError(err)
+        if !isNodeAnswer(err) {
+            return &BroadcastError{Hash: tx.Hash().Hex(), Raw: raw, Err: err}
+        }
+        return fmt.Errorf("failed to rebroadcast transaction %s: %w", tx.Hash().Hex(), err)
+    }
+    return nil
+}
+
+// releaseNonce hands a reserved nonce back when its transaction never left the process
+func (s *evmSigner) releaseNonce(ctx context.Context, from common.Address, nonce uint64) {
+    if s.nonces == nil {
+        return
+    }
+    if err := s.nonces.Release(ctx, s.config.Name, from.Hex(), nonce); err != nil {
+        log.Printf("Failed to release nonce %d for %s: %v", nonce, from.Hex(), err)
+    }
+}
+
+// weiToGwei converts a wei amount to gwei
+func weiToGwei(wei *big.Int) float64 {
+    gwei, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), weiPerGwei).Float64()
+    return gwei
+}
@@ -0,0 +1,138 @@
+package blockchain
+
+import (
+    "context"
+    "encoding/hex"
+    "math/big"
+    "strings"
+    "testing"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/crypto"
+    "github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// simulatedChainID is the chain ID of go-ethereum's simulated backend
+const simulatedChainID = 1337
+
+// gwei is one gwei in wei
+const gwei = 1000000000
+
+// newSimulatedAdapter starts a simulated backend funding a new key, and an adapter on it
+func newSimulatedAdapter(t *testing.T, chainID uint64) (*EVMAdapter, *simulated.Backend, string, common.Address) {
+    t.Helper()
+
+    key, err := crypto.GenerateKey()
+    if err != nil {
+        t.Fatal(err)
+    }
+    from := crypto.PubkeyToAddress(key.PublicKey)
+
+    backend := simulated.NewBackend(types.GenesisAlloc{
+        from: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))},
+    })
+    t.Cleanup(func() { backend.Close() })
+
+    config := ChainConfig{
+        Name:          "simulated",
+        Family:        FamilyEVM,
+        ChainID:       chainID,
+        NativeSymbol:  "ETH",
+        Decimals:      18,
+        Confirmations: 1,
+        BlockTime:     12,
+    }
+    return NewEVMAdapterWithClient(config, backend.Client()), backend, hex.EncodeToString(crypto.FromECDSA(key)), from
+}
+
+func TestSendDynamicFeeTx(t *testing.T) {
+    ctx := context.Background()
+    adapter, backend, privateKey, from := newSimulatedAdapter(t, simulatedChainID)
+    client := backend.Client()
+    to := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+
+    fee := &FeeEstimate{
+        Tier:                 FeeFast,
+        MaxFeePerGas:         units.FromInt64(100 * gwei),
+        MaxPriorityFeePerGas: units.FromInt64(2 * gwei),
+    }
+
+    for nonce := uint64(0); nonce < 2; nonce++ {
+        head, err := client.HeaderByNumber(ctx, nil)
+        if err != nil {
+            t.Fatal(err)
+        }
+
+        sent, err := adapter.SendTransaction(ctx, from.Hex(), to.Hex(), units.FromInt64(1000), privateKey, fee)
+        if err != nil {
+            t.Fatalf("SendTransaction() error = %v", err)
+        }
+        backend.Commit()
+
+        // The returned fields describe the transaction that was sent
+        if sent.Nonce != nonce {
+            t.Errorf("nonce = %d, want %d", sent.Nonce, nonce)
+        }
+        if sent.GasLimit != 21000 {
+            t.Errorf("gas limit = %d, want 21000 for a plain transfer", sent.GasLimit)
+        }
+        if sent.GasPrice != 100 || sent.GasTipCap != 2 {
+            t.Errorf("gas price and tip = %v and %v gwei, want 100 and 2", sent.GasPrice, sent.GasTipCap)
+        }
+        expectedFee := new(big.Int).Mul(new(big.Int).Add(head.BaseFee, big.NewInt(2*gwei)), big.NewInt(21000))
+        if sent.Fee.BigInt().Cmp(expectedFee) != 0 {
+            t.Errorf("fee = %s, want (base fee + tip) * gas = %s", sent.Fee, expectedFee)
+        }
+        if sent.FeeTier != FeeFast || sent.Status != "pending" || sent.From != from.Hex() || sent.To != to.Hex() {
+            t.Errorf("transaction = %+v, want a pending fast-tier transfer from %s to %s", sent, from.Hex(), to.Hex())
+        }
+
+        // The node has a signed type-2 transaction with the same parameters
+        tx, pending, err := client.TransactionByHash(ctx, common.HexToHash(sent.Hash))
+        if err != nil {
+            t.Fatalf("TransactionByHash() error = %v", err)
+        }
+        if pending {
+            t.Errorf("transaction %s is still pending after a commit", sent.Hash)
+        }
+        if tx.Type() != types.DynamicFeeTxType {
+            t.Errorf("transaction type = %d, want %d", tx.Type(), types.DynamicFeeTxType)
+        }
+        if tx.ChainId().Uint64() != simulatedChainID {
+            t.Errorf("chain ID = %s, want %d", tx.ChainId(), simulatedChainID)
+        }
+        if tx.Nonce() != nonce || tx.Gas() != sent.GasLimit {
+            t.Errorf("nonce and gas = %d and %d, want %d and %d", tx.Nonce(), tx.Gas(), nonce, sent.GasLimit)
+        }
+        if tx.GasFeeCap().Cmp(big.NewInt(100*gwei)) != 0 || tx.GasTipCap().Cmp(big.NewInt(2*gwei)) != 0 {
+            t.Errorf("fee cap and tip cap = %s and %s, want %d and %d", tx.GasFeeCap(), tx.GasTipCap(), 100*gwei, 2*gwei)
+        }
+        if tx.To() == nil || *tx.To() != to || tx.Value().Cmp(big.NewInt(1000)) != 0 {
+            t.Errorf("transfer = %s to %v, want 1000 to %s", tx.Value(), tx.To(), to.Hex())
+        }
+
+        sender, err := types.Sender(types.LatestSignerForChainID(big.NewInt(simulatedChainID)), tx)
+        if err != nil || sender != from {
+            t.Errorf("signer = %s (%v), want %s", sender.Hex(), err, from.Hex())
+        }
+
+        receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+        if err != nil {
+            t.Fatalf("TransactionReceipt() error = %v", err)
+        }
+        if receipt.Status != types.ReceiptStatusSuccessful || receipt.GasUsed != sent.GasLimit {
+            t.Errorf("receipt status and gas used = %d and %d, want success and %d", receipt.Status, receipt.GasUsed, sent.GasLimit)
+        }
+    }
+}
+
+func TestSendDynamicFeeTxRejectsWrongChainID(t *testing.T) {
+    adapter, _, privateKey, from := newSimulatedAdapter(t, 1)
+
+    _, err := adapter.SendTransaction(context.Background(), from.Hex(), "0x00000000000000000000000000000000000000aa", units.FromInt64(1000), privateKey, nil)
+    if err == nil || !strings.Contains(err.Error(), "chain ID") {
+        t.Fatalf("SendTransaction() on the wrong chain error = %v, want a chain ID mismatch", err)
+    }
+}
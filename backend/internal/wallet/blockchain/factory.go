@@ -4,35 +4,65 @@ import "fmt"
 
 // AdapterFactory creates blockchain adapters
 type AdapterFactory struct {
-    config map[string]interface{}
+    registry *Registry
+    nonces   NonceManager
 }
 
-// NewAdapterFactory creates a new adapter factory
-func NewAdapterFactory(config map[string]interface{}) *AdapterFactory {
+// NewAdapterFactory creates a new adapter factory for the chains in the registry
+func NewAdapterFactory(registry *Registry) *AdapterFactory {
     return &AdapterFactory{
-        config: config,
+        registry: registry,
     }
 }
 
+// UseNonceManager makes every EVM adapter the factory creates reserve nonces through the given
+// manager, such as a DBNonceManager shared by every replica
+func (f *AdapterFactory) UseNonceManager(nonces NonceManager) {
+    f.nonces = nonces
+}
+
 // CreateAdapter creates a blockchain adapter for the specified chain
 func (f *AdapterFactory) CreateAdapter(chain string) (Adapter, error) {
-    switch chain {
-    case "bitcoin":
-        isTestnet, _ := f.config["bitcoin_testnet"].(bool)
-        return NewBitcoinAdapter(isTestnet), nil
-    case "ethereum":
-        rpcURL, _ := f.config["ethereum_rpc_url"].(string)
-        return NewEthereumAdapter(rpcURL), nil
-    case "solana":
-        rpcURL, _ := f.config["solana_rpc_url"].(string)
-        return NewSolanaAdapter(rpcURL), nil
-    case "tron":
-        grpcURL, _ := f.config["tron_grpc_url"].(string)
-        return NewTronAdapter(grpcURL), nil
-    case "bnb":
-        rpcURL, _ := f.config["bnb_rpc_url"].(string)
-        return NewBNBadapter(rpcURL), nil
+    config, err := f.registry.Lookup(chain)
+    if err != nil {
+        return nil, err
+    }
+
+    switch config.Family {
+    case FamilyEVM:
+        adapter := NewEVMAdapter(config)
+        if f.nonces != nil {
+            adapter.SetNonceManager(f.nonces)
+        }
+        return adapter, nil
+    case FamilyBitcoin:
+        // With a bitcoind block source, rpc_urls are JSON-RPC endpoints and the Esplora APIs come
+        // from api_urls, which the registry requires then
+        if config.BlockSource == BitcoinSourceBitcoind {
+            if len(config.APIURLs) == 0 {
+                return nil, fmt.Errorf("chain %s: api_urls is required for a bitcoind block_source", config.Name)
+            }
+            return NewBitcoinAdapter(config.Testnet, config.APIURLs...), nil
+        }
+        return NewBitcoinAdapter(config.Testnet, config.RPCURLs...), nil
+    case FamilySolana:
+        return NewSolanaAdapter(config.RPCURLs...), nil
+    case FamilyTron:
+        return NewTronAdapter(config.RPCURLs...), nil
     default:
-        return nil, fmt.Errorf("unsupported blockchain: %s", chain)
+        return nil, fmt.Errorf("unsupported blockchain family %s for chain %s", config.Family, chain)
     }
-}
\ No newline at end of file
+}
+
+// CreateAdapters creates an adapter for every chain in the registry, keyed by chain name
+func (f *AdapterFactory) CreateAdapters() (map[string]Adapter, error) {
+    adapters := make(map[string]Adapter)
+    for _, config := range f.registry.Chains() {
+        adapter, err := f.CreateAdapter(config.Name)
+        if err != nil {
+            return nil, err
+        }
+        adapters[config.Name] = adapter
+    }
+    return adapters, nil
+}
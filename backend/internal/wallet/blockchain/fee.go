@@ -0,0 +1,83 @@
+package blockchain
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+)
+
+// FeeTier is a speed and price trade-off offered to users when they withdraw
+type FeeTier string
+
+const (
+    FeeSlow     FeeTier = "slow"
+    FeeStandard FeeTier = "standard"
+    FeeFast     FeeTier = "fast"
+)
+
+// feeTiers lists the tiers from cheapest to fastest
+var feeTiers = []FeeTier{FeeSlow, FeeStandard, FeeFast}
+
+// ParseFeeTier parses a tier name; an empty name selects the standard tier
+func ParseFeeTier(name string) (FeeTier, error) {
+    if name == "" {
+        return FeeStandard, nil
+    }
+
+    tier := FeeTier(strings.ToLower(name))
+    for _, known := range feeTiers {
+        if tier == known {
+            return tier, nil
+        }
+    }
+
+    return "", fmt.Errorf("unknown fee tier %q, expected slow, standard or fast", name)
+}
+
+// FeeEstimate is the expected cost of a transaction at one fee tier, together with the
+// chain-specific pricing parameters to sign it with
+type FeeEstimate struct {
+    Tier                 FeeTier
+    Fee                  units.Amount  // expected fee in base units of the native coin
+    ExpectedConfirmation time.Duration // typical time until the first confirmation
+
+    // EVM EIP-1559 pricing, in wei per gas
+    MaxFeePerGas         units.Amount
+    MaxPriorityFeePerGas units.Amount
+    GasLimit             uint64
+
+    // Bitcoin fee rate in sat/vB, or Solana compute unit price in micro-lamports
+    FeeRate uint64
+
+    // Solana compute unit limit
+    ComputeUnitLimit uint32
+}
+
+// FeeEstimates holds the estimates for every tier, ordered from slow to fast
+type FeeEstimates []FeeEstimate
+
+// Tier returns the estimate for a tier
+func (e FeeEstimates) Tier(tier FeeTier) (FeeEstimate, error) {
+    for _, estimate := range e {
+        if estimate.Tier == tier {
+            return estimate, nil
+        }
+    }
+    return FeeEstimate{}, fmt.Errorf("no %s fee estimate available", tier)
+}
+
+// percentile returns the p-th percentile (0-100) of a list of values
+func percentile(values []uint64, p int) uint64 {
+    if len(values) == 0 {
+        return 0
+    }
+
+    sorted := append([]uint64(nil), values...)
+    sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+    index := (len(sorted) - 1) * p / 100
+    return sorted[index]
+}
@@ -1,13 +1,21 @@
 package blockchain
 
-import "context"
+import (
+    "context"
+    "errors"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+)
+
+// ErrTransactionNotPending is returned when replacing a transaction that was already mined or dropped
+var ErrTransactionNotPending = errors.New("transaction is no longer pending")
 
 // Wallet represents a blockchain wallet
 type Wallet struct {
     Address    string
     PublicKey  string
     PrivateKey string
-    Balance    float64
+    Balance    units.Amount // in base units of the native coin
 }
 
 // Transaction represents a blockchain transaction
@@ -15,11 +23,28 @@ type Transaction struct {
     Hash          string
     From          string
     To            string
-    Amount        float64
-    Fee           float64
+    Amount        units.Amount // in base units of the token, or of the native coin
+    Decimals      uint8        // decimals of Amount's asset
+    Fee           units.Amount // in base units of the native coin
     Confirmations int
     Status        string // pending, confirmed, failed
     Timestamp     int64
+
+    // Token movements, empty for transfers of the native coin
+    Token       string // token contract address
+    TokenSymbol string
+
+    // Fee tier the transaction was priced with
+    FeeTier FeeTier
+
+    // EVM gas parameters, zero for chains without an account nonce or gas market
+    Nonce     uint64
+    GasLimit  uint64
+    GasPrice  float64 // max fee per gas in gwei
+    GasTipCap float64 // max priority fee per gas in gwei
+
+    // Bitcoin fee rate in sat/vB, or Solana compute unit price in micro-lamports
+    FeeRate uint64
 }
 
 // Adapter defines the interface for blockchain adapters
@@ -27,18 +52,76 @@ type Adapter interface {
     // CreateWallet creates a new wallet
     CreateWallet(ctx context.Context) (*Wallet, error)
     
+    // WalletFromPrivateKey builds the wallet controlled by a raw private key, such as one derived from an HD seed
+    WalletFromPrivateKey(privateKey []byte) (*Wallet, error)
+    
+    // ValidateAddress checks that an address is well formed for the chain, returning an
+    // ErrInvalidAddress that explains the problem
+    ValidateAddress(address string) error
+    
     // GetWallet retrieves wallet information
     GetWallet(ctx context.Context, address string) (*Wallet, error)
     
-    // GetBalance retrieves the balance of an address
-    GetBalance(ctx context.Context, address string) (float64, error)
+    // GetBalance retrieves the balance of an address in base units
+    GetBalance(ctx context.Context, address string) (units.Amount, error)
     
-    // SendTransaction sends a transaction
-    SendTransaction(ctx context.Context, from, to string, amount float64, privateKey string) (*Transaction, error)
+    // SendTransaction sends an amount of the native coin, in base units, priced by a fee
+    // estimate from EstimateFee; a nil fee uses the standard tier
+    SendTransaction(ctx context.Context, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error)
     
     // GetTransaction retrieves transaction details
     GetTransaction(ctx context.Context, hash string) (*Transaction, error)
     
-    // EstimateFee estimates the transaction fee
-    EstimateFee(ctx context.Context, from, to string, amount float64) (float64, error)
-}
\ No newline at end of file
+    // EstimateFee estimates the fee of sending the native coin at every fee tier
+    EstimateFee(ctx context.Context, from, to string, amount units.Amount) (FeeEstimates, error)
+}
+
+// TokenMetadata describes a fungible token contract
+type TokenMetadata struct {
+    Contract string
+    Symbol   string
+    Decimals uint8
+}
+
+// TokenAdapter is implemented by adapters that support fungible tokens on top of the native coin
+type TokenAdapter interface {
+    Adapter
+
+    // GetTokenBalance retrieves the token balance of an address in the token's base units
+    GetTokenBalance(ctx context.Context, token, address string) (units.Amount, error)
+
+    // SendTokenTransaction sends a token transfer of an amount in the token's base units,
+    // priced by a fee estimate from EstimateTokenFee; a nil fee uses the standard tier
+    SendTokenTransaction(ctx context.Context, token, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error)
+
+    // EstimateTokenFee estimates the fee of a token transfer at every fee tier
+    EstimateTokenFee(ctx context.Context, token, from, to string, amount units.Amount) (FeeEstimates, error)
+
+    // GetTokenMetadata retrieves the symbol and decimals of a token
+    GetTokenMetadata(ctx context.Context, token string) (*TokenMetadata, error)
+}
+
+// TokenTransferLister is implemented by adapters that can list incoming token transfers
+// for an address directly from the chain
+type TokenTransferLister interface {
+    // ListTokenTransfers lists recent incoming transfers of a token to an address
+    ListTokenTransfers(ctx context.Context, token, address string, limit int) ([]*Transaction, error)
+}
+
+// ReplaceableAdapter is implemented by adapters that can replace a stuck pending transaction
+// with one paying a higher fee. A nil fee uses the fast tier.
+type ReplaceableAdapter interface {
+    // SpeedUpTransaction rebroadcasts a pending transaction with the same effect and a higher fee
+    SpeedUpTransaction(ctx context.Context, hash, from, privateKey string, fee *FeeEstimate) (*Transaction, error)
+
+    // CancelTransaction replaces a pending transaction with one that sends its funds back to the sender
+    CancelTransaction(ctx context.Context, hash, from, privateKey string, fee *FeeEstimate) (*Transaction, error)
+}
+
+// RebroadcastAdapter is implemented by adapters that can send a transaction they signed earlier
+// again, byte for byte, after its first broadcast ended with a BroadcastError
+type RebroadcastAdapter interface {
+    // RebroadcastTransaction sends the raw transaction of a BroadcastError; it fails with
+    // another BroadcastError while the outcome is still unknown
+    RebroadcastTransaction(ctx context.Context, raw string) error
+}
@@ -0,0 +1,481 @@
+package blockchain
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "math/big"
+    "sync"
+
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/ethclient"
+    "github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrNoQuorum is returned when too few RPC endpoints agree on the result of a read
+var ErrNoQuorum = errors.New("RPC endpoints did not reach quorum")
+
+// ErrSubscriptionsUnsupported is returned when none of a chain's endpoints can push notifications,
+// as HTTP endpoints cannot; WebSocket (ws:// or wss://) and IPC endpoints can
+var ErrSubscriptionsUnsupported = errors.New("no RPC endpoint supports subscriptions")
+
+// EVMBlockClient is an EVMClient that can also fetch whole blocks and logs, as block watchers need
+type EVMBlockClient interface {
+    EVMClient
+    BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+    FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error)
+}
+
+// MultiClient is an EVM client over several RPC endpoints of one chain. Calls go to the
+// healthiest endpoint and fail over to the next one on transport errors. With a quorum above
+// one, balance and receipt reads are only trusted when that many endpoints return the same answer.
+type MultiClient struct {
+    pool   *endpointPool[EVMBlockClient]
+    quorum int
+}
+
+// DialMultiClient dials every RPC endpoint of a chain. Endpoints that cannot be dialed are
+// skipped; it fails only when none can.
+func DialMultiClient(config ChainConfig) (*MultiClient, error) {
+    var urls []string
+    var clients []EVMBlockClient
+    for _, url := range config.RPCURLs {
+        client, err := ethclient.Dial(url)
+        if err != nil {
+            log.Printf("Skipping %s RPC endpoint %s: %v", config.Name, url, err)
+            continue
+        }
+        urls = append(urls, url)
+        clients = append(clients, client)
+    }
+
+    if len(clients) == 0 {
+        return nil, fmt.Errorf("no reachable RPC endpoints configured for %s", config.Name)
+    }
+
+    return NewMultiClient(config.Name, urls, clients, config.RPCQuorum)
+}
+
+// NewMultiClient creates a client over already connected endpoints, such as simulated backends
+func NewMultiClient(chain string, urls []string, clients []EVMBlockClient, quorum int) (*MultiClient, error) {
+    if len(urls) != len(clients) {
+        return nil, fmt.Errorf("got %d URLs for %d clients", len(urls), len(clients))
+    }
+    if quorum > len(clients) {
+        return nil, fmt.Errorf("quorum of %d needs at least as many endpoints, %s has %d", quorum, chain, len(clients))
+    }
+
+    probe := func(ctx context.Context, client EVMBlockClient) (uint64, error) {
+        header, err := client.HeaderByNumber(ctx, nil)
+        if err != nil {
+            return 0, err
+        }
+        return header.Number.Uint64(), nil
+    }
+
+    return &MultiClient{
+        pool:   newEndpointPool(chain, urls, clients, probe),
+        quorum: quorum,
+    }, nil
+}
+
+// Endpoints returns the health of every endpoint
+func (m *MultiClient) Endpoints() []EndpointStatus {
+    return m.pool.statuses()
+}
+
+// CheckHealth probes every endpoint now instead of waiting for the next periodic check
+func (m *MultiClient) CheckHealth(ctx context.Context) {
+    m.pool.check(ctx)
+}
+
+// isNodeAnswer reports whether an error is a definitive answer from a working node, such as
+// an execution revert, which another endpoint would return as well
+func isNodeAnswer(err error) bool {
+    var rpcErr rpc.Error
+    return errors.As(err, &rpcErr)
+}
+
+// failover calls each endpoint in order of health until one answers
+func failover[T any](ctx context.Context, m *MultiClient, call func(client EVMBlockClient) (T, error)) (T, error) {
+    var zero T
+    var lastErr error
+
+    for _, e := range m.pool.ordered(ctx) {
+        result, err := call(e.client)
+        if err == nil {
+            m.pool.markSuccess(e)
+            return result, nil
+        }
+        if ctx.Err() != nil {
+            return zero, err
+        }
+
+        switch {
+        case errors.Is(err, ethereum.NotFound):
+            // A lagging endpoint may not have seen the block or transaction yet
+            lastErr = err
+        case isNodeAnswer(err):
+            m.pool.markSuccess(e)
+            return zero, err
+        default:
+            m.pool.markFailure(e, err)
+            lastErr = err
+        }
+    }
+
+    if lastErr == nil {
+        lastErr = fmt.Errorf("no RPC endpoints configured for %s", m.pool.chain)
+    }
+    return zero, lastErr
+}
+
+// quorumRead asks every healthy endpoint concurrently and returns the answer at least quorum of
+// them agree on. Answers are compared by the given key.
+func quorumRead[T any](ctx context.Context, m *MultiClient, endpoints []*endpoint[EVMBlockClient], call func(client EVMBlockClient) (T, error), key func(T) string) (T, error) {
+    var zero T
+    if len(endpoints) < m.quorum {
+        return zero, fmt.Errorf("%w: %d of %d endpoints of %s are healthy", ErrNoQuorum, len(endpoints), m.quorum, m.pool.chain)
+    }
+
+    type answer struct {
+        value T
+        err   error
+    }
+    answers := make([]answer, len(endpoints))
+
+    var wg sync.WaitGroup
+    for i, e := range endpoints {
+        wg.Add(1)
+        go func(i int, e *endpoint[EVMBlockClient]) {
+            defer wg.Done()
+            value, err := call(e.client)
+            answers[i] = answer{value: value, err: err}
+        }(i, e)
+    }
+    wg.Wait()
+
+    votes := make(map[string]int)
+    notFound := 0
+    for i, a := range answers {
+        switch {
+        case a.err == nil:
+            m.pool.markSuccess(endpoints[i])
+            k := key(a.value)
+            votes[k]++
+            if votes[k] >= m.quorum {
+                return a.value, nil
+            }
+        case errors.Is(a.err, ethereum.NotFound):
+            notFound++
+            if notFound >= m.quorum {
+                return zero, a.err
+            }
+        default:
+            m.pool.markFailure(endpoints[i], a.err)
+        }
+    }
+
+    return zero, fmt.Errorf("%w: no answer was given by %d endpoints of %s", ErrNoQuorum, m.quorum, m.pool.chain)
+}
+
+// ChainID retrieves the chain ID
+func (m *MultiClient) ChainID(ctx context.Context) (*big.Int, error) {
+    return failover(ctx, m, func(c EVMBlockClient) (*big.Int, error) { return c.ChainID(ctx) })
+}
+
+// BalanceAt returns the balance of an account. With a quorum, reads of the latest state are
+// pinned to the highest block every healthy endpoint has, so endpoints a block apart still agree.
+func (m *MultiClient) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+    call := func(number *big.Int) func(c EVMBlockClient) (*big.Int, error) {
+        return func(c EVMBlockClient) (*big.Int, error) { return c.BalanceAt(ctx, account, number) }
+    }
+    if m.quorum <= 1 {
+        return failover(ctx, m, call(blockNumber))
+    }
+
+    endpoints := m.pool.healthyEndpoints(ctx)
+    if blockNumber == nil && len(endpoints) > 0 {
+        blockNumber = new(big.Int).SetUint64(m.pool.safeHead(endpoints))
+    }
+    return quorumRead(ctx, m, endpoints, call(blockNumber), func(b *big.Int) string { return b.String() })
+}
+
+// NonceAt returns the nonce of an account at a block
+func (m *MultiClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+    return failover(ctx, m, func(c EVMBlockClient) (uint64, error) { return c.NonceAt(ctx, account, blockNumber) })
+}
+
+// PendingNonceAt returns the next nonce of an account including pending transactions
+func (m *MultiClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+    return failover(ctx, m, func(c EVMBlockClient) (uint64, error) { return c.PendingNonceAt(ctx, account) })
+}
+
+// HeaderByNumber returns a block header, or the latest one for a nil number
+func (m *MultiClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+    return failover(ctx, m, func(c EVMBlockClient) (*types.Header, error) { return c.HeaderByNumber(ctx, number) })
+}
+
+// BlockByNumber returns a block, or the latest one for a nil number
+func (m *MultiClient) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+    return failover(ctx, m, func(c EVMBlockClient) (*types.Block, error) { return c.BlockByNumber(ctx, number) })
+}
+
+// FilterLogs returns the logs matching a filter query
+func (m *MultiClient) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+    return failover(ctx, m, func(c EVMBlockClient) ([]types.Log, error) { return c.FilterLogs(ctx, q) })
+}
+
+// headSubscriber is a client that can push new block headers, such as an ethclient over WebSocket
+type headSubscriber interface {
+    SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// SubscribeNewHead subscribes to new block headers on the healthiest endpoint that supports
+// subscriptions. The subscription stays on that endpoint; when it fails, its error is delivered
+// on the subscription's Err channel and the caller subscribes again.
+func (m *MultiClient) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+    var lastErr error
+    for _, e := range m.pool.ordered(ctx) {
+        subscriber, ok := e.client.(headSubscriber)
+        if !ok {
+            continue
+        }
+
+        sub, err := subscriber.SubscribeNewHead(ctx, ch)
+        if err == nil {
+            m.pool.markSuccess(e)
+            return sub, nil
+        }
+        if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+            continue
+        }
+        if ctx.Err() != nil {
+            return nil, err
+        }
+        m.pool.markFailure(e, err)
+        lastErr = err
+    }
+
+    if lastErr == nil {
+        return nil, ErrSubscriptionsUnsupported
+    }
+    return nil, lastErr
+}
+
+// SubscribePendingTransactions subscribes to the full transactions entering the mempool of the
+// healthiest endpoint that can stream them, which needs a WebSocket or IPC endpoint of a node that
+// supports full pending transactions, such as geth 1.11 or later
+func (m *MultiClient) SubscribePendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (ethereum.Subscription, error) {
+    var lastErr error
+    for _, e := range m.pool.ordered(ctx) {
+        raw, ok := e.client.(rawClient)
+        if !ok {
+            continue
+        }
+
+        sub, err := raw.Client().EthSubscribe(ctx, ch, "newPendingTransactions", true)
+        if err == nil {
+            m.pool.markSuccess(e)
+            return sub, nil
+        }
+        if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+            continue
+        }
+        if ctx.Err() != nil {
+            return nil, err
+        }
+        if isNodeAnswer(err) {
+            // The node is up but cannot stream full transactions
+            lastErr = err
+            continue
+        }
+        m.pool.markFailure(e, err)
+        lastErr = err
+    }
+
+    if lastErr == nil {
+        return nil, ErrSubscriptionsUnsupported
+    }
+    return nil, lastErr
+}
+
+// SuggestGasTipCap suggests a priority fee per gas
+func (m *MultiClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+    return failover(ctx, m, func(c EVMBlockClient) (*big.Int, error) { return c.SuggestGasTipCap(ctx) })
+}
+
+// FeeHistory returns base fees and priority fee percentiles of recent blocks
+func (m *MultiClient) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+    return failover(ctx, m, func(c EVMBlockClient) (*ethereum.FeeHistory, error) {
+        return c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+    })
+}
+
+// EstimateGas estimates the gas a call needs
+func (m *MultiClient) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+    return failover(ctx, m, func(c EVMBlockClient) (uint64, error) { return c.EstimateGas(ctx, msg) })
+}
+
+// CallContract executes a read-only contract call
+func (m *MultiClient) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+    return failover(ctx, m, func(c EVMBlockClient) ([]byte, error) { return c.CallContract(ctx, msg, blockNumber) })
+}
+
+// TransactionByHash returns a transaction and whether it is still pending
+func (m *MultiClient) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+    type result struct {
+        tx      *types.Transaction
+        pending bool
+    }
+    r, err := failover(ctx, m, func(c EVMBlockClient) (result, error) {
+        tx, pending, err := c.TransactionByHash(ctx, hash)
+        return result{tx: tx, pending: pending}, err
+    })
+    return r.tx, r.pending, err
+}
+
+// TransactionReceipt returns the receipt of a mined transaction. With a quorum, endpoints must
+// agree on the block, status and logs of the receipt.
+func (m *MultiClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+    call := func(c EVMBlockClient) (*types.Receipt, error) { return c.TransactionReceipt(ctx, txHash) }
+    if m.quorum <= 1 {
+        return failover(ctx, m, call)
+    }
+
+    return quorumRead(ctx, m, m.pool.healthyEndpoints(ctx), call, func(r *types.Receipt) string {
+        return fmt.Sprintf("%s:%d:%d", r.BlockHash.Hex(), r.Status, len(r.Logs))
+    })
+}
+
+// receiptBatch is the most receipts asked for in one batch request, below the batch limits of
+// common providers
+const receiptBatch = 100
+
+// rawClient is a client that exposes its RPC connection, for batch requests, traces and
+// subscriptions go-ethereum has no method for, such as an ethclient
+type rawClient interface {
+    Client() *rpc.Client
+}
+
+// TransactionReceipts returns the receipts of several mined transactions, in the order of the
+// hashes. The receipts are asked for in batch requests where the endpoint allows it, so a block's
+// transactions cost one round trip instead of one each. With a quorum, every receipt is read
+// through TransactionReceipt so endpoints must still agree on it.
+func (m *MultiClient) TransactionReceipts(ctx context.Context, hashes []common.Hash) ([]*types.Receipt, error) {
+    receipts := make([]*types.Receipt, 0, len(hashes))
+    if m.quorum > 1 {
+        for _, hash := range hashes {
+            receipt, err := m.TransactionReceipt(ctx, hash)
+            if err != nil {
+                return nil, err
+            }
+            receipts = append(receipts, receipt)
+        }
+        return receipts, nil
+    }
+
+    for start := 0; start < len(hashes); start += receiptBatch {
+        end := start + receiptBatch
+        if end > len(hashes) {
+            end = len(hashes)
+        }
+
+        batch, err := failover(ctx, m, func(c EVMBlockClient) ([]*types.Receipt, error) {
+            return batchReceipts(ctx, c, hashes[start:end])
+        })
+        if err != nil {
+            return nil, err
+        }
+        receipts = append(receipts, batch...)
+    }
+    return receipts, nil
+}
+
+// batchReceipts reads receipts from one endpoint, in a single batch request if the client supports
+// it. A missing receipt fails the whole batch with ethereum.NotFound, so a lagging endpoint is
+// passed over.
+func batchReceipts(ctx context.Context, client EVMBlockClient, hashes []common.Hash) ([]*types.Receipt, error) {
+    receipts := make([]*types.Receipt, len(hashes))
+
+    raw, ok := client.(rawClient)
+    if !ok {
+        for i, hash := range hashes {
+            receipt, err := client.TransactionReceipt(ctx, hash)
+            if err != nil {
+                return nil, err
+            }
+            receipts[i] = receipt
+        }
+        return receipts, nil
+    }
+
+    batch := make([]rpc.BatchElem, len(hashes))
+    for i, hash := range hashes {
+        batch[i] = rpc.BatchElem{
+            Method: "eth_getTransactionReceipt",
+            Args:   []interface{}{hash},
+            Result: &receipts[i],
+        }
+    }
+    if err := raw.Client().BatchCallContext(ctx, batch); err != nil {
+        return nil, err
+    }
+
+    for i, elem := range batch {
+        if elem.Error != nil {
+            return nil, elem.Error
+        }
+        if receipts[i] == nil {
+            return nil, fmt.Errorf("receipt of transaction %s: %w", hashes[i].Hex(), ethereum.NotFound)
+        }
+    }
+    return receipts, nil
+}
+
+// SendTransaction broadcasts a signed transaction through every endpoint, so it propagates even
+// when some providers are down. It succeeds when any endpoint accepts it.
+func (m *MultiClient) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+    endpoints := m.pool.ordered(ctx)
+    errs := make([]error, len(endpoints))
+
+    var wg sync.WaitGroup
+    for i, e := range endpoints {
+        wg.Add(1)
+        go func(i int, e *endpoint[EVMBlockClient]) {
+            defer wg.Done()
+            errs[i] = e.client.SendTransaction(ctx, tx)
+        }(i, e)
+    }
+    wg.Wait()
+
+    for i, err := range errs {
+        if err == nil || isAlreadyKnown(err) {
+            m.pool.markSuccess(endpoints[i])
+            return nil
+        }
+    }
+
+    if len(errs) == 0 {
+        return fmt.Errorf("no RPC endpoints configured for %s", m.pool.chain)
+    }
+    // An endpoint that failed without answering may have taken the transaction, so the
+    // broadcast only counts as refused when every endpoint refused it
+    var unanswered error
+    for i, err := range errs {
+        if !isNodeAnswer(err) {
+            m.pool.markFailure(endpoints[i], err)
+            if unanswered == nil {
+                unanswered = err
+            }
+        }
+    }
+    if unanswered != nil {
+        return unanswered
+    }
+    // Nodes reject an invalid transaction the same way, so the healthiest one's reason is enough
+    return errs[0]
+}
@@ -0,0 +1,244 @@
+package blockchain
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "math/big"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// Nonce reservation statuses
+const (
+    NonceStatusReserved  = "reserved"  // handed to a worker, not yet broadcast
+    NonceStatusBroadcast = "broadcast" // used by a transaction that was sent, even if no node answered
+    NonceStatusReleased  = "released"  // returned unused, free to hand out again
+    NonceStatusConfirmed = "confirmed" // below the account's mined nonce
+)
+
+// defaultNonceStaleAfter is how long a reservation may sit unconfirmed before its nonce is treated as a gap
+const defaultNonceStaleAfter = 10 * time.Minute
+
+// NonceReader reads account nonces from an EVM node
+type NonceReader interface {
+    NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+    PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// NonceManager allocates account nonces for EVM transactions so that concurrent
+// senders from the same address never reuse or skip a nonce
+type NonceManager interface {
+    // Reserve reserves the next usable nonce for an address
+    Reserve(ctx context.Context, chain, address string, reader NonceReader) (uint64, error)
+
+    // MarkBroadcast records that a reserved nonce was used by a broadcast transaction
+    MarkBroadcast(ctx context.Context, chain, address string, nonce uint64, txHash string) error
+
+    // Release returns a reserved nonce that was never broadcast so it can be reused
+    Release(ctx context.Context, chain, address string, nonce uint64) error
+}
+
+// NonceManagedAdapter is implemented by adapters that can use a NonceManager
+type NonceManagedAdapter interface {
+    SetNonceManager(nonces NonceManager)
+}
+
+// NonceCursor stores the next fresh nonce for an address
+type NonceCursor struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    Chain     string    `gorm:"not null;uniqueIndex:idx_nonce_cursor_chain_address" json:"chain"`
+    Address   string    `gorm:"not null;uniqueIndex:idx_nonce_cursor_chain_address" json:"address"`
+    NextNonce uint64    `gorm:"not null" json:"next_nonce"`
+    SyncedAt  time.Time `json:"synced_at"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NonceReservation records a nonce handed out for an address
+type NonceReservation struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    Chain     string    `gorm:"not null;uniqueIndex:idx_nonce_reservation" json:"chain"`
+    Address   string    `gorm:"not null;uniqueIndex:idx_nonce_reservation" json:"address"`
+    Nonce     uint64    `gorm:"not null;uniqueIndex:idx_nonce_reservation" json:"nonce"`
+    Status    string    `gorm:"not null;index" json:"status"` // reserved, broadcast, released, confirmed
+    TxHash    string    `json:"tx_hash,omitempty"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// DBNonceManager is a NonceManager backed by Postgres row locks. Every replica sending from the
+// same address shares its reservations, so none of them keeps state of its own.
+type DBNonceManager struct {
+    db         *gorm.DB
+    staleAfter time.Duration
+}
+
+// NewDBNonceManager creates a new database-backed nonce manager
+func NewDBNonceManager(db *gorm.DB) *DBNonceManager {
+    return &DBNonceManager{
+        db:         db,
+        staleAfter: defaultNonceStaleAfter,
+    }
+}
+
+// SetStaleAfter sets how long a reserved or broadcast nonce may stay unseen by the node
+// before it is considered dropped and handed out again
+func (m *DBNonceManager) SetStaleAfter(d time.Duration) {
+    m.staleAfter = d
+}
+
+// Reserve reserves the next usable nonce for an address. It fills gaps left by
+// dropped or abandoned transactions before handing out a fresh nonce. A reservation is only
+// taken over once it is stale: it may belong to another replica that is still signing, and a
+// process that crashed while holding one leaves it to expire like a dropped transaction.
+func (m *DBNonceManager) Reserve(ctx context.Context, chain, address string, reader NonceReader) (uint64, error) {
+    addr := common.HexToAddress(address)
+    address = addr.Hex()
+
+    // Read the node's view before taking any locks
+    pending, err := reader.PendingNonceAt(ctx, addr)
+    if err != nil {
+        return 0, fmt.Errorf("failed to fetch pending nonce: %w", err)
+    }
+    mined, err := reader.NonceAt(ctx, addr, nil)
+    if err != nil {
+        return 0, fmt.Errorf("failed to fetch mined nonce: %w", err)
+    }
+
+    var nonce uint64
+    err = m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        cursor, err := m.lockCursor(tx, chain, address, pending)
+        if err != nil {
+            return err
+        }
+
+        // Everything below the mined nonce is final
+        if err := tx.Model(&NonceReservation{}).
+            Where("chain = ? AND address = ? AND nonce < ? AND status <> ?", chain, address, mined, NonceStatusConfirmed).
+            Update("status", NonceStatusConfirmed).Error; err != nil {
+            return fmt.Errorf("failed to confirm mined nonces: %w", err)
+        }
+
+        // Transactions sent from outside this service move the account forward
+        if pending > cursor.NextNonce {
+            cursor.NextNonce = pending
+        }
+
+        gap, found, err := m.findGap(tx, chain, address, pending, cursor.NextNonce)
+        if err != nil {
+            return err
+        }
+
+        if found {
+            nonce = gap
+        } else {
+            nonce = cursor.NextNonce
+            cursor.NextNonce++
+        }
+
+        cursor.SyncedAt = time.Now()
+        if err := tx.Save(cursor).Error; err != nil {
+            return fmt.Errorf("failed to update nonce cursor: %w", err)
+        }
+
+        reservation := NonceReservation{
+            Chain:   chain,
+            Address: address,
+            Nonce:   nonce,
+            Status:  NonceStatusReserved,
+        }
+        return tx.Clauses(clause.OnConflict{
+            Columns:   []clause.Column{{Name: "chain"}, {Name: "address"}, {Name: "nonce"}},
+            DoUpdates: clause.Assignments(map[string]interface{}{"status": NonceStatusReserved, "tx_hash": "", "updated_at": time.Now()}),
+        }).Create(&reservation).Error
+    })
+    if err != nil {
+        return 0, err
+    }
+
+    return nonce, nil
+}
+
+// lockCursor loads the cursor for an address with a row lock, creating it from the node's pending nonce if needed
+func (m *DBNonceManager) lockCursor(tx *gorm.DB, chain, address string, pending uint64) (*NonceCursor, error) {
+    cursor := &NonceCursor{Chain: chain, Address: address, NextNonce: pending, SyncedAt: time.Now()}
+    if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(cursor).Error; err != nil {
+        return nil, fmt.Errorf("failed to create nonce cursor: %w", err)
+    }
+
+    cursor = &NonceCursor{}
+    err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+        Where("chain = ? AND address = ?", chain, address).
+        First(cursor).Error
+    if err != nil {
+        return nil, fmt.Errorf("failed to lock nonce cursor: %w", err)
+    }
+
+    return cursor, nil
+}
+
+// findGap returns the lowest nonce in [pending, next) that is not held by a live transaction
+func (m *DBNonceManager) findGap(tx *gorm.DB, chain, address string, pending, next uint64) (uint64, bool, error) {
+    if pending >= next {
+        return 0, false, nil
+    }
+
+    var reservations []NonceReservation
+    err := tx.Where("chain = ? AND address = ? AND nonce >= ? AND nonce < ?", chain, address, pending, next).
+        Find(&reservations).Error
+    if err != nil {
+        return 0, false, fmt.Errorf("failed to load nonce reservations: %w", err)
+    }
+
+    byNonce := make(map[uint64]NonceReservation, len(reservations))
+    for _, r := range reservations {
+        byNonce[r.Nonce] = r
+    }
+
+    staleBefore := time.Now().Add(-m.staleAfter)
+    for n := pending; n < next; n++ {
+        r, ok := byNonce[n]
+        if !ok {
+            // Nobody recorded this nonce, so the node will wait on it forever
+            return n, true, nil
+        }
+        switch r.Status {
+        case NonceStatusReleased:
+            return n, true, nil
+        case NonceStatusReserved, NonceStatusBroadcast:
+            // The node does not know this nonce; once stale it has been dropped
+            if r.UpdatedAt.Before(staleBefore) {
+                return n, true, nil
+            }
+        }
+    }
+
+    return 0, false, nil
+}
+
+// MarkBroadcast records that a reserved nonce was used by a broadcast transaction
+func (m *DBNonceManager) MarkBroadcast(ctx context.Context, chain, address string, nonce uint64, txHash string) error {
+    address = common.HexToAddress(address).Hex()
+    result := m.db.WithContext(ctx).Model(&NonceReservation{}).
+        Where("chain = ? AND address = ? AND nonce = ?", chain, address, nonce).
+        Updates(map[string]interface{}{"status": NonceStatusBroadcast, "tx_hash": txHash})
+    if result.Error != nil {
+        return fmt.Errorf("failed to mark nonce as broadcast: %w", result.Error)
+    }
+    if result.RowsAffected == 0 {
+        return errors.New("nonce reservation not found")
+    }
+    return nil
+}
+
+// Release returns a reserved nonce that was never broadcast so it can be reused
+func (m *DBNonceManager) Release(ctx context.Context, chain, address string, nonce uint64) error {
+    address = common.HexToAddress(address).Hex()
+    return m.db.WithContext(ctx).Model(&NonceReservation{}).
+        Where("chain = ? AND address = ? AND nonce = ? AND status = ?", chain, address, nonce, NonceStatusReserved).
+        Update("status", NonceStatusReleased).Error
+}
@@ -0,0 +1,165 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "testing"
+    "time"
+
+    "github.com/ethereum/go-ethereum/common"
+    "gorm.io/driver/sqlite"
+    "gorm.io/gorm"
+    "gorm.io/gorm/logger"
+)
+
+// nonceTestAddress is the account the nonce tests reserve for
+const nonceTestAddress = "0x00000000000000000000000000000000000000aa"
+
+// fixedNonces is a NonceReader that reports fixed mined and pending nonces
+type fixedNonces struct {
+    mined   uint64
+    pending uint64
+}
+
+func (f *fixedNonces) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+    return f.mined, nil
+}
+
+func (f *fixedNonces) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+    return f.pending, nil
+}
+
+// newTestNonceManager creates a nonce manager on an in-memory database
+func newTestNonceManager(t *testing.T) (*DBNonceManager, *gorm.DB) {
+    t.Helper()
+
+    db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{
+        Logger: logger.Default.LogMode(logger.Silent),
+    })
+    if err != nil {
+        t.Fatalf("failed to open database: %v", err)
+    }
+    sqlDB, err := db.DB()
+    if err != nil {
+        t.Fatalf("failed to open database: %v", err)
+    }
+    sqlDB.SetMaxOpenConns(1)
+    t.Cleanup(func() { sqlDB.Close() })
+
+    if err := db.AutoMigrate(&NonceCursor{}, &NonceReservation{}); err != nil {
+        t.Fatalf("failed to migrate nonce tables: %v", err)
+    }
+
+    return NewDBNonceManager(db), db
+}
+
+func TestDBNonceManager(t *testing.T) {
+    ctx := context.Background()
+
+    // A step is one call against the manager; reserve steps expect the nonce handed out
+    type step struct {
+        op      string // reserve, broadcast, release, age
+        mined   uint64
+        pending uint64
+        nonce   uint64
+    }
+    tests := []struct {
+        name  string
+        steps []step
+    }{
+        {
+            name: "fresh nonces follow the node",
+            steps: []step{
+                {op: "reserve", mined: 5, pending: 5, nonce: 5},
+                {op: "reserve", mined: 5, pending: 5, nonce: 6},
+                {op: "reserve", mined: 5, pending: 5, nonce: 7},
+            },
+        },
+        {
+            name: "released nonce is handed out again",
+            steps: []step{
+                {op: "reserve", nonce: 0},
+                {op: "reserve", nonce: 1},
+                {op: "release", nonce: 0},
+                {op: "reserve", nonce: 0},
+                {op: "reserve", nonce: 2},
+            },
+        },
+        {
+            name: "broadcast nonce is not reused while fresh",
+            steps: []step{
+                {op: "reserve", nonce: 0},
+                {op: "broadcast", nonce: 0},
+                {op: "release", nonce: 0},
+                {op: "reserve", nonce: 1},
+            },
+        },
+        {
+            name: "stale reservation is treated as dropped",
+            steps: []step{
+                {op: "reserve", nonce: 0},
+                {op: "reserve", nonce: 1},
+                {op: "broadcast", nonce: 0},
+                {op: "age", nonce: 0},
+                {op: "reserve", nonce: 0},
+                {op: "reserve", nonce: 2},
+            },
+        },
+        {
+            name: "outside sends move the cursor forward",
+            steps: []step{
+                {op: "reserve", nonce: 0},
+                {op: "broadcast", nonce: 0},
+                {op: "reserve", mined: 1, pending: 4, nonce: 4},
+            },
+        },
+        {
+            name: "mined nonces are never handed out",
+            steps: []step{
+                {op: "reserve", nonce: 0},
+                {op: "release", nonce: 0},
+                {op: "reserve", mined: 1, pending: 1, nonce: 1},
+            },
+        },
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            nonces, db := newTestNonceManager(t)
+            for i, s := range tt.steps {
+                switch s.op {
+                case "reserve":
+                    got, err := nonces.Reserve(ctx, "ethereum", nonceTestAddress, &fixedNonces{mined: s.mined, pending: s.pending})
+                    if err != nil {
+                        t.Fatalf("step %d: Reserve error = %v", i, err)
+                    }
+                    if got != s.nonce {
+                        t.Fatalf("step %d: Reserve = %d, want %d", i, got, s.nonce)
+                    }
+                case "broadcast":
+                    if err := nonces.MarkBroadcast(ctx, "ethereum", nonceTestAddress, s.nonce, fmt.Sprintf("0x%x", s.nonce)); err != nil {
+                        t.Fatalf("step %d: MarkBroadcast error = %v", i, err)
+                    }
+                case "release":
+                    if err := nonces.Release(ctx, "ethereum", nonceTestAddress, s.nonce); err != nil {
+                        t.Fatalf("step %d: Release error = %v", i, err)
+                    }
+                case "age":
+                    err := db.Model(&NonceReservation{}).Where("nonce = ?", s.nonce).
+                        UpdateColumn("updated_at", time.Now().Add(-2*defaultNonceStaleAfter)).Error
+                    if err != nil {
+                        t.Fatalf("step %d: failed to age reservation: %v", i, err)
+                    }
+                }
+            }
+        })
+    }
+}
+
+func TestDBNonceManagerMarkBroadcastUnknownNonce(t *testing.T) {
+    nonces, _ := newTestNonceManager(t)
+    if err := nonces.MarkBroadcast(context.Background(), "ethereum", nonceTestAddress, 3, "0x3"); err == nil {
+        t.Errorf("MarkBroadcast of a nonce that was never reserved succeeded")
+    }
+}
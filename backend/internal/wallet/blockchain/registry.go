@@ -0,0 +1,368 @@
+package blockchain
+
+import (
+    "encoding/json"
+    "fmt"
+    "os"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+)
+
+// ChainFamily groups networks that share an address format and transaction model
+type ChainFamily string
+
+const (
+    FamilyEVM     ChainFamily = "evm"
+    FamilyBitcoin ChainFamily = "bitcoin"
+    FamilySolana  ChainFamily = "solana"
+    FamilyTron    ChainFamily = "tron"
+)
+
+// ChainConfig describes a supported network
+type ChainConfig struct {
+    Name          string      `json:"name"`
+    Family        ChainFamily `json:"family"`
+    ChainID       uint64      `json:"chain_id,omitempty"` // EVM chain ID
+    RPCURLs       []string    `json:"rpc_urls,omitempty"` // EVM WebSocket endpoints (ws://, wss://) also push new heads to block watchers
+    RPCQuorum     int         `json:"rpc_quorum,omitempty"` // endpoints that must agree on balance and receipt reads, 0 or 1 to trust any
+    NativeSymbol  string      `json:"native_symbol"`
+    Decimals      uint8       `json:"decimals"`
+    Confirmations int         `json:"confirmations"` // blocks before a deposit is considered final
+    BlockTime     float64     `json:"block_time"`    // average seconds between blocks
+    ExplorerURL   string      `json:"explorer_url,omitempty"` // transaction link template, %s is the hash
+    DepositTokens []string    `json:"deposit_tokens,omitempty"` // token contracts whose transfers to deposit addresses are detected
+    BlockSource   string      `json:"block_source,omitempty"`   // Bitcoin blocks API, esplora (default) or bitcoind at the first rpc_url
+    APIURLs       []string    `json:"api_urls,omitempty"`       // Esplora APIs a Bitcoin adapter reads UTXOs from and broadcasts through when block_source is bitcoind
+    Tracing       string      `json:"tracing,omitempty"`        // EVM tracer that finds deposits sent by contract calls, callTracer or parity; empty to see only top-level transfers
+    Testnet       bool        `json:"testnet,omitempty"`
+    Aliases       []string    `json:"aliases,omitempty"`
+}
+
+// PrimaryRPCURL returns the first configured RPC endpoint, or an empty string
+func (c ChainConfig) PrimaryRPCURL() string {
+    if len(c.RPCURLs) == 0 {
+        return ""
+    }
+    return c.RPCURLs[0]
+}
+
+// BlockDuration returns the average time between blocks
+func (c ChainConfig) BlockDuration() time.Duration {
+    return time.Duration(c.BlockTime * float64(time.Second))
+}
+
+// TransactionURL returns the block explorer link for a transaction
+func (c ChainConfig) TransactionURL(hash string) string {
+    if c.ExplorerURL == "" {
+        return ""
+    }
+    return fmt.Sprintf(c.ExplorerURL, hash)
+}
+
+// validate checks that a chain entry is complete
+func (c ChainConfig) validate() error {
+    if c.Name == "" {
+        return fmt.Errorf("chain name is required")
+    }
+
+    switch c.Family {
+    case FamilyEVM:
+        if c.ChainID == 0 {
+            return fmt.Errorf("chain %s: chain_id is required for EVM networks", c.Name)
+        }
+        if c.Tracing != "" && c.Tracing != TracerCall && c.Tracing != TracerParity {
+            return fmt.Errorf("chain %s: unsupported tracing %q", c.Name, c.Tracing)
+        }
+    case FamilyBitcoin:
+        if c.BlockSource != "" && c.BlockSource != BitcoinSourceEsplora && c.BlockSource != BitcoinSourceBitcoind {
+            return fmt.Errorf("chain %s: unsupported block_source %q", c.Name, c.BlockSource)
+        }
+        if c.BlockSource == BitcoinSourceBitcoind && len(c.RPCURLs) == 0 {
+            return fmt.Errorf("chain %s: rpc_urls is required for a bitcoind block_source", c.Name)
+        }
+        if c.BlockSource == BitcoinSourceBitcoind && len(c.APIURLs) == 0 {
+            return fmt.Errorf("chain %s: api_urls is required for a bitcoind block_source", c.Name)
+        }
+    case FamilySolana, FamilyTron:
+    default:
+        return fmt.Errorf("chain %s: unsupported family %q", c.Name, c.Family)
+    }
+
+    if c.NativeSymbol == "" {
+        return fmt.Errorf("chain %s: native_symbol is required", c.Name)
+    }
+    if c.Confirmations < 0 {
+        return fmt.Errorf("chain %s: confirmations must not be negative", c.Name)
+    }
+    if c.BlockTime <= 0 {
+        return fmt.Errorf("chain %s: block_time must be positive", c.Name)
+    }
+    if c.RPCQuorum < 0 || c.RPCQuorum > len(c.RPCURLs) {
+        return fmt.Errorf("chain %s: rpc_quorum must be between 0 and the number of rpc_urls", c.Name)
+    }
+    for _, token := range c.DepositTokens {
+        if err := ValidateAddress(c, token); err != nil {
+            return fmt.Errorf("chain %s: deposit token %s: %w", c.Name, token, err)
+        }
+    }
+
+    return nil
+}
+
+// Registry holds the networks the wallet service supports, keyed by name.
+// Adding an EVM network only needs a new entry, for example:
+//
+//     {"name": "optimism", "family": "evm", "chain_id": 10, "rpc_urls": ["https://mainnet.optimism.io"],
+//      "native_symbol": "ETH", "decimals": 18, "confirmations": 20, "block_time": 2, "explorer_url": "https://optimistic.etherscan.io/tx/%s",
+//      "deposit_tokens": ["0x0b2C639c533813f4Aa9D7837CAf62653d097Ff85"]}
+type Registry struct {
+    mu      sync.RWMutex
+    chains  map[string]ChainConfig
+    aliases map[string]string
+}
+
+// NewRegistry creates a registry from a list of chain entries
+func NewRegistry(chains ...ChainConfig) (*Registry, error) {
+    r := &Registry{
+        chains:  make(map[string]ChainConfig),
+        aliases: make(map[string]string),
+    }
+
+    for _, chain := range chains {
+        if err := r.Register(chain); err != nil {
+            return nil, err
+        }
+    }
+
+    return r, nil
+}
+
+// DefaultRegistry returns a registry with the built-in mainnet networks
+func DefaultRegistry() *Registry {
+    r, err := NewRegistry(defaultChains()...)
+    if err != nil {
+        panic(fmt.Sprintf("invalid built-in chain registry: %v", err))
+    }
+    return r
+}
+
+// LoadRegistry reads a JSON array of chain entries from a file on top of the built-in networks.
+// Entries with the name of a built-in network replace it.
+func LoadRegistry(path string) (*Registry, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read chain registry: %w", err)
+    }
+
+    var chains []ChainConfig
+    if err := json.Unmarshal(data, &chains); err != nil {
+        return nil, fmt.Errorf("failed to parse chain registry: %w", err)
+    }
+
+    r := DefaultRegistry()
+    for _, chain := range chains {
+        if err := r.Register(chain); err != nil {
+            return nil, err
+        }
+    }
+
+    return r, nil
+}
+
+// Register adds or replaces a chain entry. Its name and aliases are checked against the other
+// chains before anything is written, so a rejected entry leaves the registry as it was, and a
+// replaced entry's aliases are those of the new entry only.
+func (r *Registry) Register(chain ChainConfig) error {
+    chain.Name = strings.ToLower(chain.Name)
+    if err := chain.validate(); err != nil {
+        return err
+    }
+
+    r.mu.Lock()
+    defer r.mu.Unlock()
+
+    if owner, ok := r.aliases[chain.Name]; ok && owner != chain.Name {
+        return fmt.Errorf("chain %s: name is already an alias of %s", chain.Name, owner)
+    }
+
+    aliases := make([]string, 0, len(chain.Aliases))
+    for _, alias := range chain.Aliases {
+        alias = strings.ToLower(alias)
+        if owner, ok := r.aliases[alias]; ok && owner != chain.Name {
+            return fmt.Errorf("chain %s: alias %q is already used by %s", chain.Name, alias, owner)
+        }
+        if _, ok := r.chains[alias]; ok || alias == chain.Name {
+            return fmt.Errorf("chain %s: alias %q is already a chain name", chain.Name, alias)
+        }
+        aliases = append(aliases, alias)
+    }
+
+    for alias, owner := range r.aliases {
+        if owner == chain.Name {
+            delete(r.aliases, alias)
+        }
+    }
+    for _, alias := range aliases {
+        r.aliases[alias] = chain.Name
+    }
+
+    r.chains[chain.Name] = chain
+    return nil
+}
+
+// Get returns the entry for a chain name or alias
+func (r *Registry) Get(name string) (ChainConfig, bool) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    name = strings.ToLower(name)
+    if canonical, ok := r.aliases[name]; ok {
+        name = canonical
+    }
+
+    chain, ok := r.chains[name]
+    return chain, ok
+}
+
+// Lookup returns the entry for a chain name or alias, or an error if it is not supported
+func (r *Registry) Lookup(name string) (ChainConfig, error) {
+    chain, ok := r.Get(name)
+    if !ok {
+        return ChainConfig{}, fmt.Errorf("unsupported blockchain: %s", name)
+    }
+    return chain, nil
+}
+
+// Has reports whether a chain name or alias is registered
+func (r *Registry) Has(name string) bool {
+    _, ok := r.Get(name)
+    return ok
+}
+
+// Chains returns all registered chains sorted by name
+func (r *Registry) Chains() []ChainConfig {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+
+    chains := make([]ChainConfig, 0, len(r.chains))
+    for _, chain := range r.chains {
+        chains = append(chains, chain)
+    }
+    sort.Slice(chains, func(i, j int) bool { return chains[i].Name < chains[j].Name })
+
+    return chains
+}
+
+// activeRegistry is the registry used to validate chain names across the wallet packages
+var (
+    activeRegistry   = DefaultRegistry()
+    activeRegistryMu sync.RWMutex
+)
+
+// SetRegistry replaces the registry used to validate chain names, typically once at startup
+func SetRegistry(r *Registry) {
+    activeRegistryMu.Lock()
+    defer activeRegistryMu.Unlock()
+    activeRegistry = r
+}
+
+// Chains returns the registry used to validate chain names
+func Chains() *Registry {
+    activeRegistryMu.RLock()
+    defer activeRegistryMu.RUnlock()
+    return activeRegistry
+}
+
+// defaultChains returns the built-in network entries
+func defaultChains() []ChainConfig {
+    return []ChainConfig{
+        {
+            Name:          "bitcoin",
+            Family:        FamilyBitcoin,
+            NativeSymbol:  "BTC",
+            Decimals:      8,
+            Confirmations: 3,
+            BlockTime:     600,
+            ExplorerURL:   "https://mempool.space/tx/%s",
+        },
+        {
+            Name:          "ethereum",
+            Family:        FamilyEVM,
+            ChainID:       1,
+            RPCURLs:       []string{"https://cloudflare-eth.com"},
+            NativeSymbol:  "ETH",
+            Decimals:      18,
+            Confirmations: 12,
+            BlockTime:     12,
+            ExplorerURL:   "https://etherscan.io/tx/%s",
+        },
+        {
+            Name:          "bnb", // BNB Smart Chain
+            Family:        FamilyEVM,
+            ChainID:       56,
+            RPCURLs:       []string{"https://bsc-dataseed.binance.org"},
+            NativeSymbol:  "BNB",
+            Decimals:      18,
+            Confirmations: 15,
+            BlockTime:     3,
+            ExplorerURL:   "https://bscscan.com/tx/%s",
+            Aliases:       []string{"bsc"},
+        },
+        {
+            Name:          "polygon",
+            Family:        FamilyEVM,
+            ChainID:       137,
+            RPCURLs:       []string{"https://polygon-rpc.com"},
+            NativeSymbol:  "POL",
+            Decimals:      18,
+            Confirmations: 64,
+            BlockTime:     2,
+            ExplorerURL:   "https://polygonscan.com/tx/%s",
+            Aliases:       []string{"matic"},
+        },
+        {
+            Name:          "arbitrum",
+            Family:        FamilyEVM,
+            ChainID:       42161,
+            RPCURLs:       []string{"https://arb1.arbitrum.io/rpc"},
+            NativeSymbol:  "ETH",
+            Decimals:      18,
+            Confirmations: 20,
+            BlockTime:     0.25,
+            ExplorerURL:   "https://arbiscan.io/tx/%s",
+        },
+        {
+            Name:          "base",
+            Family:        FamilyEVM,
+            ChainID:       8453,
+            RPCURLs:       []string{"https://mainnet.base.org"},
+            NativeSymbol:  "ETH",
+            Decimals:      18,
+            Confirmations: 20,
+            BlockTime:     2,
+            ExplorerURL:   "https://basescan.org/tx/%s",
+        },
+        {
+            Name:          "solana",
+            Family:        FamilySolana,
+            RPCURLs:       []string{"https://api.mainnet-beta.solana.com"},
+            NativeSymbol:  "SOL",
+            Decimals:      9,
+            Confirmations: 32,
+            BlockTime:     0.4,
+            ExplorerURL:   "https://solscan.io/tx/%s",
+        },
+        {
+            Name:          "tron",
+            Family:        FamilyTron,
+            RPCURLs:       []string{"grpc.trongrid.io:50051"},
+            NativeSymbol:  "TRX",
+            Decimals:      6,
+            Confirmations: 19,
+            BlockTime:     3,
+            ExplorerURL:   "https://tronscan.org/#/transaction/%s",
+        },
+    }
+}
@@ -0,0 +1,389 @@
+package simulator
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "sort"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+)
+
+// Compile-time checks that the simulator can stand in for a real adapter
+var (
+    _ blockchain.Adapter             = (*Chain)(nil)
+    _ blockchain.TokenAdapter        = (*Chain)(nil)
+    _ blockchain.TokenTransferLister = (*Chain)(nil)
+    _ blockchain.ReplaceableAdapter  = (*Chain)(nil)
+)
+
+// CreateWallet creates a wallet from the next deterministic private key
+func (c *Chain) CreateWallet(ctx context.Context) (*blockchain.Wallet, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("CreateWallet"); err != nil {
+        return nil, err
+    }
+    return c.walletFromKey(c.nextKey())
+}
+
+// WalletFromPrivateKey builds the wallet controlled by a raw private key
+func (c *Chain) WalletFromPrivateKey(privateKey []byte) (*blockchain.Wallet, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.walletFromKey(privateKey)
+}
+
+// walletFromKey derives a wallet and remembers its key so sends can be authorized.
+// The caller must hold the lock.
+func (c *Chain) walletFromKey(privateKey []byte) (*blockchain.Wallet, error) {
+    w, err := c.keys.WalletFromPrivateKey(privateKey)
+    if err != nil {
+        return nil, err
+    }
+    c.owners[w.PrivateKey] = w.Address
+    w.Balance = units.NewAmount(c.balance(w.Address))
+    return w, nil
+}
+
+// ValidateAddress checks that an address is well formed for the simulated chain
+func (c *Chain) ValidateAddress(address string) error {
+    return blockchain.ValidateAddress(c.config, address)
+}
+
+// GetWallet retrieves the wallet of an address with its mined balance
+func (c *Chain) GetWallet(ctx context.Context, address string) (*blockchain.Wallet, error) {
+    balance, err := c.GetBalance(ctx, address)
+    if err != nil {
+        return nil, err
+    }
+    return &blockchain.Wallet{Address: address, Balance: balance}, nil
+}
+
+// GetBalance retrieves the mined native balance of an address
+func (c *Chain) GetBalance(ctx context.Context, address string) (units.Amount, error) {
+    if err := c.ValidateAddress(address); err != nil {
+        return units.Amount{}, err
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("GetBalance"); err != nil {
+        return units.Amount{}, err
+    }
+    return units.NewAmount(c.balance(address)), nil
+}
+
+// SendTransaction queues a native transfer. The private key must control the sender.
+func (c *Chain) SendTransaction(ctx context.Context, from, to string, amount units.Amount, privateKey string, fee *blockchain.FeeEstimate) (*blockchain.Transaction, error) {
+    return c.send(ctx, "SendTransaction", "", from, to, amount, privateKey, fee)
+}
+
+// GetTransaction retrieves a transaction with its current confirmations
+func (c *Chain) GetTransaction(ctx context.Context, hash string) (*blockchain.Transaction, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("GetTransaction"); err != nil {
+        return nil, err
+    }
+
+    tx, ok := c.txs[hash]
+    if !ok || tx.Status == StatusDropped || tx.Status == StatusReplaced {
+        return nil, fmt.Errorf("transaction %s: %w", hash, blockchain.ErrNotFound)
+    }
+    return c.toTransaction(tx), nil
+}
+
+// EstimateFee returns the configured fee schedule
+func (c *Chain) EstimateFee(ctx context.Context, from, to string, amount units.Amount) (blockchain.FeeEstimates, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("EstimateFee"); err != nil {
+        return nil, err
+    }
+    return append(blockchain.FeeEstimates(nil), c.fees...), nil
+}
+
+// GetTokenBalance retrieves the mined token balance of an address
+func (c *Chain) GetTokenBalance(ctx context.Context, token, address string) (units.Amount, error) {
+    if err := c.ValidateAddress(address); err != nil {
+        return units.Amount{}, err
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("GetTokenBalance"); err != nil {
+        return units.Amount{}, err
+    }
+    if _, ok := c.tokens[token]; !ok {
+        return units.Amount{}, fmt.Errorf("unknown token %s", token)
+    }
+    return units.NewAmount(c.tokenBalance(token, address)), nil
+}
+
+// SendTokenTransaction queues a token transfer. The sender pays the fee in the native coin.
+func (c *Chain) SendTokenTransaction(ctx context.Context, token, from, to string, amount units.Amount, privateKey string, fee *blockchain.FeeEstimate) (*blockchain.Transaction, error) {
+    return c.send(ctx, "SendTokenTransaction", token, from, to, amount, privateKey, fee)
+}
+
+// EstimateTokenFee returns the configured fee schedule
+func (c *Chain) EstimateTokenFee(ctx context.Context, token, from, to string, amount units.Amount) (blockchain.FeeEstimates, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("EstimateTokenFee"); err != nil {
+        return nil, err
+    }
+    return append(blockchain.FeeEstimates(nil), c.fees...), nil
+}
+
+// GetTokenMetadata retrieves a token registered with AddToken
+func (c *Chain) GetTokenMetadata(ctx context.Context, token string) (*blockchain.TokenMetadata, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("GetTokenMetadata"); err != nil {
+        return nil, err
+    }
+
+    meta, ok := c.tokens[token]
+    if !ok {
+        return nil, fmt.Errorf("unknown token %s", token)
+    }
+    return &meta, nil
+}
+
+// ListTokenTransfers lists mined incoming transfers of a token to an address, newest first
+func (c *Chain) ListTokenTransfers(ctx context.Context, token, address string, limit int) ([]*blockchain.Transaction, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("ListTokenTransfers"); err != nil {
+        return nil, err
+    }
+
+    var transfers []*blockchain.Transaction
+    for i := len(c.blocks) - 1; i >= 0; i-- {
+        block := c.blocks[i]
+        for j := len(block.Transactions) - 1; j >= 0; j-- {
+            tx := block.Transactions[j]
+            if tx.Token != token || tx.To != address || tx.Status != StatusConfirmed {
+                continue
+            }
+            transfers = append(transfers, c.toTransaction(tx))
+            if limit > 0 && len(transfers) == limit {
+                return transfers, nil
+            }
+        }
+    }
+    return transfers, nil
+}
+
+// SpeedUpTransaction replaces a pending transaction with the same transfer at a higher fee
+func (c *Chain) SpeedUpTransaction(ctx context.Context, hash, from, privateKey string, fee *blockchain.FeeEstimate) (*blockchain.Transaction, error) {
+    return c.replace("SpeedUpTransaction", hash, from, privateKey, fee, false)
+}
+
+// CancelTransaction replaces a pending transaction with a zero-value transfer to the sender
+func (c *Chain) CancelTransaction(ctx context.Context, hash, from, privateKey string, fee *blockchain.FeeEstimate) (*blockchain.Transaction, error) {
+    return c.replace("CancelTransaction", hash, from, privateKey, fee, true)
+}
+
+// send validates and queues a transfer of the native coin or, when token is set, of a token
+func (c *Chain) send(ctx context.Context, method, token, from, to string, amount units.Amount, privateKey string, fee *blockchain.FeeEstimate) (*blockchain.Transaction, error) {
+    if err := c.ValidateAddress(from); err != nil {
+        return nil, err
+    }
+    if err := c.ValidateAddress(to); err != nil {
+        return nil, err
+    }
+    if amount.Sign() < 0 {
+        return nil, fmt.Errorf("amount must not be negative")
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault(method); err != nil {
+        return nil, err
+    }
+    if err := c.authorize(from, privateKey); err != nil {
+        return nil, err
+    }
+    if token != "" {
+        if _, ok := c.tokens[token]; !ok {
+            return nil, fmt.Errorf("unknown token %s", token)
+        }
+    }
+
+    estimate, err := c.selectFee(fee)
+    if err != nil {
+        return nil, err
+    }
+
+    tx := &Tx{
+        Hash:    c.nextHash(),
+        From:    from,
+        To:      to,
+        Token:   token,
+        Amount:  amount.BigInt(),
+        Fee:     estimate.Fee.BigInt(),
+        FeeTier: estimate.Tier,
+        Status:  StatusPending,
+    }
+    if err := c.checkFunds(tx); err != nil {
+        return nil, err
+    }
+    c.submit(tx)
+
+    return c.toTransaction(tx), nil
+}
+
+// replace swaps a pending transaction for a new one paying a higher fee. The caller must not hold the lock.
+func (c *Chain) replace(method, hash, from, privateKey string, fee *blockchain.FeeEstimate, cancel bool) (*blockchain.Transaction, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault(method); err != nil {
+        return nil, err
+    }
+    if err := c.authorize(from, privateKey); err != nil {
+        return nil, err
+    }
+
+    index := -1
+    for i, tx := range c.mempool {
+        if tx.Hash == hash {
+            index = i
+            break
+        }
+    }
+    if index < 0 {
+        return nil, fmt.Errorf("transaction %s: %w", hash, blockchain.ErrTransactionNotPending)
+    }
+    original := c.mempool[index]
+    if original.From != from {
+        return nil, fmt.Errorf("transaction %s was not sent by %s", hash, from)
+    }
+
+    if fee == nil {
+        fast, err := c.fees.Tier(blockchain.FeeFast)
+        if err != nil {
+            return nil, err
+        }
+        fee = &fast
+    }
+
+    // Like real nodes, only accept a replacement that pays strictly more
+    newFee := fee.Fee.BigInt()
+    if newFee.Cmp(original.Fee) <= 0 {
+        return nil, fmt.Errorf("%w: replacement fee %s must exceed the original fee %s", blockchain.ErrUnderpriced, newFee, original.Fee)
+    }
+
+    replacement := &Tx{
+        Hash:    c.nextHash(),
+        From:    original.From,
+        To:      original.To,
+        Token:   original.Token,
+        Amount:  new(big.Int).Set(original.Amount),
+        Fee:     newFee,
+        FeeTier: fee.Tier,
+        Status:  StatusPending,
+    }
+    if cancel {
+        replacement.To = original.From
+        replacement.Token = ""
+        replacement.Amount = new(big.Int)
+    }
+
+    // Check funds as if the original were already gone
+    c.mempool = append(c.mempool[:index:index], c.mempool[index+1:]...)
+    if err := c.checkFunds(replacement); err != nil {
+        c.mempool = append(c.mempool[:index], append([]*Tx{original}, c.mempool[index:]...)...)
+        return nil, err
+    }
+    original.Status = StatusReplaced
+    c.submit(replacement)
+
+    return c.toTransaction(replacement), nil
+}
+
+// authorize checks that a private key controls an address. The caller must hold the lock.
+func (c *Chain) authorize(from, privateKey string) error {
+    if owner, ok := c.owners[privateKey]; !ok || owner != from {
+        return fmt.Errorf("private key does not control %s", from)
+    }
+    return nil
+}
+
+// selectFee returns the estimate to price a transaction with, the standard tier when none is
+// given. The caller must hold the lock.
+func (c *Chain) selectFee(fee *blockchain.FeeEstimate) (blockchain.FeeEstimate, error) {
+    if fee != nil {
+        return *fee, nil
+    }
+    return c.fees.Tier(blockchain.FeeStandard)
+}
+
+// checkFunds rejects a transaction its sender cannot pay for, counting what is already
+// pending. The caller must hold the lock.
+func (c *Chain) checkFunds(tx *Tx) error {
+    native := new(big.Int).Set(tx.Fee)
+    if tx.Token == "" {
+        native.Add(native, tx.Amount)
+    }
+    if available := c.spendable(tx.From); available.Cmp(native) < 0 {
+        return fmt.Errorf("%w: %s available, %s needed", blockchain.ErrInsufficientFunds, available, native)
+    }
+
+    if tx.Token != "" {
+        if available := c.spendableToken(tx.Token, tx.From); available.Cmp(tx.Amount) < 0 {
+            return fmt.Errorf("%w: %s of the token available, %s needed", blockchain.ErrInsufficientFunds, available, tx.Amount)
+        }
+    }
+    return nil
+}
+
+// toTransaction converts a simulated transaction to the adapter type. The caller must hold the lock.
+func (c *Chain) toTransaction(tx *Tx) *blockchain.Transaction {
+    transaction := &blockchain.Transaction{
+        Hash:          tx.Hash,
+        From:          tx.From,
+        To:            tx.To,
+        Amount:        units.NewAmount(tx.Amount),
+        Decimals:      c.config.Decimals,
+        Fee:           units.NewAmount(tx.Fee),
+        Confirmations: c.confirmations(tx),
+        Status:        tx.Status,
+        Token:         tx.Token,
+        FeeTier:       tx.FeeTier,
+    }
+    if !tx.Time.IsZero() {
+        transaction.Timestamp = tx.Time.Unix()
+    }
+    if meta, ok := c.tokens[tx.Token]; ok {
+        transaction.Decimals = meta.Decimals
+        transaction.TokenSymbol = meta.Symbol
+    }
+    return transaction
+}
+
+// Transactions returns every transaction the chain has seen, pending, mined, dropped or
+// replaced, ordered by hash so results are stable
+func (c *Chain) Transactions() []*Tx {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    txs := make([]*Tx, 0, len(c.txs))
+    for _, tx := range c.txs {
+        txs = append(txs, copyTx(tx))
+    }
+    sort.Slice(txs, func(i, j int) bool { return txs[i].Hash < txs[j].Hash })
+    return txs
+}
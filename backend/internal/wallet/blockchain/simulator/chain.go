@@ -0,0 +1,585 @@
+// Package simulator provides a deterministic in-memory chain that implements blockchain.Adapter,
+// so services built on the adapters can be exercised without a network.
+//
+// A Chain keeps accounts, token balances, a mempool and a list of blocks. Transactions stay
+// pending until a block is mined, either explicitly with MineBlock or automatically when
+// auto-mining is on. Hashes, keys and timestamps derive from a counter, so the same sequence
+// of calls always produces the same chain. Failures can be injected per adapter method, and
+// transactions can be dropped from the mempool or reorganized out of mined blocks.
+//
+// services.NewSimulatorChainWatcher reads a Chain's blocks, so the deposit watchers can run on
+// it as well.
+package simulator
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "fmt"
+    "math/big"
+    "sync"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+)
+
+// FaucetAddress is the sender of funds credited with Fund and FundToken. It has an unlimited balance.
+const FaucetAddress = "faucet"
+
+// ErrRPC is the error injected failures return when no specific error is given. It is a
+// blockchain.ErrChainUnavailable, like a node that cannot be reached.
+var ErrRPC = fmt.Errorf("%w: simulated RPC error", blockchain.ErrChainUnavailable)
+
+// genesisTime is the timestamp of block 0
+var genesisTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Transaction statuses
+const (
+    StatusPending   = "pending"
+    StatusConfirmed = "confirmed"
+    StatusFailed    = "failed"
+    StatusDropped   = "dropped"
+    StatusReplaced  = "replaced"
+)
+
+// Tx is a transaction of the simulated chain
+type Tx struct {
+    Hash    string
+    From    string
+    To      string
+    Token   string // token contract, empty for the native coin
+    Amount  *big.Int
+    Fee     *big.Int
+    FeeTier blockchain.FeeTier
+    Status  string
+    Block   uint64 // number of the including block, zero while pending
+    Index   int    // position in the including block
+    Time    time.Time
+}
+
+// Block is a block of the simulated chain
+type Block struct {
+    Number       uint64
+    Hash         string
+    ParentHash   string
+    Time         time.Time
+    Transactions []*Tx
+}
+
+// failure is an injected error for an adapter method
+type failure struct {
+    err       error
+    remaining int // calls left to fail, negative to fail forever
+}
+
+// Chain is a deterministic in-memory blockchain
+type Chain struct {
+    config blockchain.ChainConfig
+    keys   blockchain.Adapter // derives addresses from private keys for the chain's family
+
+    mu       sync.Mutex
+    seq      uint64
+    autoMine bool
+    minFee   *big.Int
+    fees     blockchain.FeeEstimates
+
+    blocks  []*Block
+    mempool []*Tx
+    txs     map[string]*Tx
+
+    balances      map[string]*big.Int
+    tokenBalances map[string]map[string]*big.Int
+    tokens        map[string]blockchain.TokenMetadata
+    owners        map[string]string // private key to address
+
+    failures map[string]*failure
+}
+
+// NewChain creates a simulated chain with only a genesis block. The config decides the
+// address format, decimals, block time and required confirmations.
+func NewChain(config blockchain.ChainConfig) (*Chain, error) {
+    keys, err := offlineAdapter(config)
+    if err != nil {
+        return nil, err
+    }
+
+    c := &Chain{
+        config:        config,
+        keys:          keys,
+        minFee:        new(big.Int),
+        txs:           make(map[string]*Tx),
+        balances:      make(map[string]*big.Int),
+        tokenBalances: make(map[string]map[string]*big.Int),
+        tokens:        make(map[string]blockchain.TokenMetadata),
+        owners:        make(map[string]string),
+        failures:      make(map[string]*failure),
+    }
+    c.fees = c.defaultFees()
+    c.blocks = []*Block{{Number: 0, Hash: c.nextHash(), Time: genesisTime}}
+
+    return c, nil
+}
+
+// offlineAdapter returns an adapter of the chain's family that never touches the network,
+// used only to derive wallets from private keys
+func offlineAdapter(config blockchain.ChainConfig) (blockchain.Adapter, error) {
+    switch config.Family {
+    case blockchain.FamilyEVM:
+        return blockchain.NewEVMAdapterWithClient(config, nil), nil
+    case blockchain.FamilyBitcoin:
+        return blockchain.NewBitcoinAdapter(config.Testnet), nil
+    case blockchain.FamilySolana:
+        return blockchain.NewSolanaAdapter(), nil
+    case blockchain.FamilyTron:
+        return blockchain.NewTronAdapter(), nil
+    default:
+        return nil, fmt.Errorf("unsupported blockchain family %s", config.Family)
+    }
+}
+
+// defaultFees prices the tiers at 1,000, 2,000 and 3,000 base units of the native coin
+func (c *Chain) defaultFees() blockchain.FeeEstimates {
+    blocks := []int64{6, 3, 1}
+    tiers := []blockchain.FeeTier{blockchain.FeeSlow, blockchain.FeeStandard, blockchain.FeeFast}
+
+    estimates := make(blockchain.FeeEstimates, 0, len(tiers))
+    for i, tier := range tiers {
+        estimates = append(estimates, blockchain.FeeEstimate{
+            Tier:                 tier,
+            Fee:                  units.FromInt64(int64(i+1) * 1000),
+            ExpectedConfirmation: time.Duration(blocks[i]) * c.blockDuration(),
+        })
+    }
+    return estimates
+}
+
+// Config returns the chain configuration of the simulator
+func (c *Chain) Config() blockchain.ChainConfig {
+    return c.config
+}
+
+// SetAutoMine makes every accepted transaction get mined into its own block right away
+func (c *Chain) SetAutoMine(autoMine bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.autoMine = autoMine
+}
+
+// SetFees replaces the estimates returned by EstimateFee and EstimateTokenFee
+func (c *Chain) SetFees(fees blockchain.FeeEstimates) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.fees = append(blockchain.FeeEstimates(nil), fees...)
+}
+
+// SetMinimumFee makes mining skip pending transactions paying less than the given fee,
+// leaving them stuck in the mempool as they would be during a fee spike
+func (c *Chain) SetMinimumFee(fee units.Amount) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.minFee = fee.BigInt()
+}
+
+// AddToken registers a token contract so it can be funded and transferred
+func (c *Chain) AddToken(meta blockchain.TokenMetadata) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.tokens[meta.Contract] = meta
+}
+
+// Fund sends native coins from the faucet to an address. The transfer is pending until mined.
+func (c *Chain) Fund(address string, amount units.Amount) (string, error) {
+    return c.fund("", address, amount)
+}
+
+// FundToken sends tokens from the faucet to an address. The transfer is pending until mined.
+func (c *Chain) FundToken(token, address string, amount units.Amount) (string, error) {
+    return c.fund(token, address, amount)
+}
+
+// fund queues a faucet transfer
+func (c *Chain) fund(token, address string, amount units.Amount) (string, error) {
+    if err := blockchain.ValidateAddress(c.config, address); err != nil {
+        return "", err
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if token != "" {
+        if _, ok := c.tokens[token]; !ok {
+            return "", fmt.Errorf("unknown token %s", token)
+        }
+    }
+
+    tx := &Tx{
+        Hash:   c.nextHash(),
+        From:   FaucetAddress,
+        To:     address,
+        Token:  token,
+        Amount: amount.BigInt(),
+        Fee:    new(big.Int),
+        Status: StatusPending,
+    }
+    c.submit(tx)
+
+    return tx.Hash, nil
+}
+
+// FailNext makes the next n calls of an adapter method, such as "GetBalance", return err.
+// A nil err fails with ErrRPC.
+func (c *Chain) FailNext(method string, n int, err error) {
+    if err == nil {
+        err = ErrRPC
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.failures[method] = &failure{err: err, remaining: n}
+}
+
+// FailAlways makes every call of an adapter method return err until ClearFailures
+func (c *Chain) FailAlways(method string, err error) {
+    c.FailNext(method, -1, err)
+}
+
+// ClearFailures removes every injected failure
+func (c *Chain) ClearFailures() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.failures = make(map[string]*failure)
+}
+
+// fault returns the injected error of a method, if any. The caller must hold the lock.
+func (c *Chain) fault(method string) error {
+    f, ok := c.failures[method]
+    if !ok {
+        return nil
+    }
+
+    if f.remaining > 0 {
+        f.remaining--
+        if f.remaining == 0 {
+            delete(c.failures, method)
+        }
+    }
+    return fmt.Errorf("%s: %w", method, f.err)
+}
+
+// MineBlock mines the pending transactions that pay at least the minimum fee into a new block
+func (c *Chain) MineBlock() *Block {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return copyBlock(c.mine())
+}
+
+// MineBlocks mines n blocks and returns the last one
+func (c *Chain) MineBlocks(n int) *Block {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    var block *Block
+    for i := 0; i < n; i++ {
+        block = c.mine()
+    }
+    return copyBlock(block)
+}
+
+// mine appends a block with the eligible pending transactions. The caller must hold the lock.
+func (c *Chain) mine() *Block {
+    parent := c.blocks[len(c.blocks)-1]
+    block := &Block{
+        Number:     parent.Number + 1,
+        Hash:       c.nextHash(),
+        ParentHash: parent.Hash,
+        Time:       genesisTime.Add(time.Duration(parent.Number+1) * c.blockDuration()),
+    }
+
+    var stuck []*Tx
+    for _, tx := range c.mempool {
+        if tx.Fee.Cmp(c.minFee) < 0 && tx.From != FaucetAddress {
+            stuck = append(stuck, tx)
+            continue
+        }
+
+        tx.Block = block.Number
+        tx.Index = len(block.Transactions)
+        tx.Time = block.Time
+        if c.apply(tx) {
+            tx.Status = StatusConfirmed
+        } else {
+            tx.Status = StatusFailed
+        }
+        block.Transactions = append(block.Transactions, tx)
+    }
+    c.mempool = stuck
+    c.blocks = append(c.blocks, block)
+
+    return block
+}
+
+// apply moves the funds of a transaction and reports whether it succeeded. Failed
+// transactions change nothing. The caller must hold the lock.
+func (c *Chain) apply(tx *Tx) bool {
+    if tx.From != FaucetAddress {
+        native := new(big.Int).Set(tx.Fee)
+        if tx.Token == "" {
+            native.Add(native, tx.Amount)
+        }
+        if c.balance(tx.From).Cmp(native) < 0 {
+            return false
+        }
+        if tx.Token != "" && c.tokenBalance(tx.Token, tx.From).Cmp(tx.Amount) < 0 {
+            return false
+        }
+
+        c.balances[tx.From] = new(big.Int).Sub(c.balance(tx.From), native)
+        if tx.Token != "" {
+            c.tokenBalances[tx.Token][tx.From] = new(big.Int).Sub(c.tokenBalance(tx.Token, tx.From), tx.Amount)
+        }
+    }
+
+    if tx.Token == "" {
+        c.balances[tx.To] = new(big.Int).Add(c.balance(tx.To), tx.Amount)
+    } else {
+        if c.tokenBalances[tx.Token] == nil {
+            c.tokenBalances[tx.Token] = make(map[string]*big.Int)
+        }
+        c.tokenBalances[tx.Token][tx.To] = new(big.Int).Add(c.tokenBalance(tx.Token, tx.To), tx.Amount)
+    }
+
+    return true
+}
+
+// Drop removes a pending transaction from the mempool as if nodes had evicted it
+func (c *Chain) Drop(hash string) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    for i, tx := range c.mempool {
+        if tx.Hash == hash {
+            tx.Status = StatusDropped
+            c.mempool = append(c.mempool[:i], c.mempool[i+1:]...)
+            return nil
+        }
+    }
+    return fmt.Errorf("pending transaction %s: %w", hash, blockchain.ErrNotFound)
+}
+
+// Reorg replaces the last depth blocks with depth+1 new blocks, so every height above the fork
+// point gets a different hash. Transactions of the orphaned blocks are mined again in the first
+// new block, except the ones listed in drop, which disappear as if they were double-spent.
+// It returns the hashes of the orphaned transactions.
+func (c *Chain) Reorg(depth int, drop ...string) ([]string, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if depth <= 0 || depth >= len(c.blocks) {
+        return nil, fmt.Errorf("reorg depth must be between 1 and %d", len(c.blocks)-1)
+    }
+
+    dropped := make(map[string]bool, len(drop))
+    for _, hash := range drop {
+        dropped[hash] = true
+    }
+
+    fork := len(c.blocks) - depth
+    var orphaned []*Tx
+    var hashes []string
+    for _, block := range c.blocks[fork:] {
+        for _, tx := range block.Transactions {
+            hashes = append(hashes, tx.Hash)
+            tx.Block, tx.Index, tx.Time = 0, 0, time.Time{}
+            if dropped[tx.Hash] {
+                tx.Status = StatusDropped
+                continue
+            }
+            tx.Status = StatusPending
+            orphaned = append(orphaned, tx)
+        }
+    }
+    c.blocks = c.blocks[:fork]
+    c.rebuildState()
+
+    // Orphaned transactions go first, ahead of anything that was already waiting
+    c.mempool = append(orphaned, c.mempool...)
+    for i := 0; i <= depth; i++ {
+        c.mine()
+    }
+
+    return hashes, nil
+}
+
+// rebuildState recomputes every balance by replaying the blocks. The caller must hold the lock.
+func (c *Chain) rebuildState() {
+    c.balances = make(map[string]*big.Int)
+    c.tokenBalances = make(map[string]map[string]*big.Int)
+
+    for _, block := range c.blocks {
+        for _, tx := range block.Transactions {
+            if c.apply(tx) {
+                tx.Status = StatusConfirmed
+            } else {
+                tx.Status = StatusFailed
+            }
+        }
+    }
+}
+
+// Head returns the number of the latest block
+func (c *Chain) Head() uint64 {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.blocks[len(c.blocks)-1].Number
+}
+
+// HeadBlock returns the number of the latest block, like Head, as a node would report it, so
+// failures can be injected for "HeadBlock"
+func (c *Chain) HeadBlock(ctx context.Context) (uint64, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("HeadBlock"); err != nil {
+        return 0, err
+    }
+    return c.blocks[len(c.blocks)-1].Number, nil
+}
+
+// BlockByNumber returns a block of the current chain
+func (c *Chain) BlockByNumber(number uint64) (*Block, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if err := c.fault("BlockByNumber"); err != nil {
+        return nil, err
+    }
+    if number >= uint64(len(c.blocks)) {
+        return nil, fmt.Errorf("block %d: %w", number, blockchain.ErrNotFound)
+    }
+    return copyBlock(c.blocks[number]), nil
+}
+
+// Pending returns the transactions waiting in the mempool
+func (c *Chain) Pending() []*Tx {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    pending := make([]*Tx, 0, len(c.mempool))
+    for _, tx := range c.mempool {
+        pending = append(pending, copyTx(tx))
+    }
+    return pending
+}
+
+// submit records a new transaction in the mempool, mining it right away when auto-mining.
+// The caller must hold the lock.
+func (c *Chain) submit(tx *Tx) {
+    c.txs[tx.Hash] = tx
+    c.mempool = append(c.mempool, tx)
+    if c.autoMine {
+        c.mine()
+    }
+}
+
+// spendable returns the native balance of an address minus what its pending transactions
+// will spend. The caller must hold the lock.
+func (c *Chain) spendable(address string) *big.Int {
+    available := new(big.Int).Set(c.balance(address))
+    for _, tx := range c.mempool {
+        if tx.From != address {
+            continue
+        }
+        available.Sub(available, tx.Fee)
+        if tx.Token == "" {
+            available.Sub(available, tx.Amount)
+        }
+    }
+    return available
+}
+
+// spendableToken returns the token balance of an address minus what its pending transactions
+// will spend. The caller must hold the lock.
+func (c *Chain) spendableToken(token, address string) *big.Int {
+    available := new(big.Int).Set(c.tokenBalance(token, address))
+    for _, tx := range c.mempool {
+        if tx.From == address && tx.Token == token {
+            available.Sub(available, tx.Amount)
+        }
+    }
+    return available
+}
+
+// balance returns the mined native balance of an address. The caller must hold the lock.
+func (c *Chain) balance(address string) *big.Int {
+    if balance, ok := c.balances[address]; ok {
+        return balance
+    }
+    return new(big.Int)
+}
+
+// tokenBalance returns the mined token balance of an address. The caller must hold the lock.
+func (c *Chain) tokenBalance(token, address string) *big.Int {
+    if balance, ok := c.tokenBalances[token][address]; ok {
+        return balance
+    }
+    return new(big.Int)
+}
+
+// confirmations returns how many blocks have been built on top of a transaction's block,
+// counting its own. The caller must hold the lock.
+func (c *Chain) confirmations(tx *Tx) int {
+    if tx.Status != StatusConfirmed && tx.Status != StatusFailed {
+        return 0
+    }
+    head := c.blocks[len(c.blocks)-1].Number
+    return int(head-tx.Block) + 1
+}
+
+// nextHash returns a deterministic hash in the chain's format. The caller must hold the lock.
+func (c *Chain) nextHash() string {
+    sum := sha256.Sum256([]byte(fmt.Sprintf("%s/hash/%d", c.config.Name, c.seq)))
+    c.seq++
+
+    if c.config.Family == blockchain.FamilyEVM {
+        return "0x" + hex.EncodeToString(sum[:])
+    }
+    return hex.EncodeToString(sum[:])
+}
+
+// nextKey returns a deterministic 32-byte private key. The caller must hold the lock.
+func (c *Chain) nextKey() []byte {
+    sum := sha256.Sum256([]byte(fmt.Sprintf("%s/key/%d", c.config.Name, c.seq)))
+    c.seq++
+    return sum[:]
+}
+
+// blockDuration returns the configured block time, or one second when it is not set
+func (c *Chain) blockDuration() time.Duration {
+    if d := c.config.BlockDuration(); d > 0 {
+        return d
+    }
+    return time.Second
+}
+
+// copyBlock returns a copy of a block that callers cannot use to change the chain
+func copyBlock(block *Block) *Block {
+    if block == nil {
+        return nil
+    }
+
+    cp := *block
+    cp.Transactions = make([]*Tx, 0, len(block.Transactions))
+    for _, tx := range block.Transactions {
+        cp.Transactions = append(cp.Transactions, copyTx(tx))
+    }
+    return &cp
+}
+
+// copyTx returns a copy of a transaction
+func copyTx(tx *Tx) *Tx {
+    cp := *tx
+    cp.Amount = new(big.Int).Set(tx.Amount)
+    cp.Fee = new(big.Int).Set(tx.Fee)
+    return &cp
+}
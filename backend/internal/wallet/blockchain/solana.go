@@ -2,32 +2,104 @@ package blockchain
 
 import (
     "context"
-    "crypto/rand"
+    "encoding/hex"
+    "errors"
     "fmt"
     "math/big"
+    "strconv"
     "time"
 
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
     "github.com/blocto/solana-go-sdk/client"
     "github.com/blocto/solana-go-sdk/common"
+    "github.com/blocto/solana-go-sdk/program/associated_token_account"
+    "github.com/blocto/solana-go-sdk/program/compute_budget"
+    "github.com/blocto/solana-go-sdk/program/system"
+    "github.com/blocto/solana-go-sdk/program/token"
+    "github.com/blocto/solana-go-sdk/rpc"
     "github.com/blocto/solana-go-sdk/types"
+    "github.com/mr-tron/base58"
 )
 
+// lamportsPerSignature is the base fee Solana charges per transaction signature
+const lamportsPerSignature = 5000
+
+// solDecimals is the number of decimals of SOL (1 SOL = 1,000,000,000 lamports)
+const solDecimals = 9
+
+// Compute unit limits requested for a native transfer and for an SPL transfer that may also
+// create the recipient's associated token account
+const (
+    transferComputeUnits      = 1000
+    tokenTransferComputeUnits = 60000
+)
+
+// slotDuration is the target duration of a Solana slot
+const slotDuration = 400 * time.Millisecond
+
+// solanaTierPolicies maps fee tiers to a percentile of recent prioritization fees and the
+// typical number of slots until the transaction lands
+var solanaTierPolicies = []struct {
+    tier          FeeTier
+    percentile    int
+    expectedSlots int64
+}{
+    {FeeSlow, 25, 32},
+    {FeeStandard, 50, 8},
+    {FeeFast, 75, 2},
+}
+
+// knownSPLSymbols maps well-known mainnet mints to their symbols, since SPL mints carry no symbol on chain
+var knownSPLSymbols = map[string]string{
+    "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v": "USDC",
+    "Es9vMFrzaCERmJfrF4H2FYD4KCoNkY11McCe8BenwNYB": "USDT",
+}
+
 // SolanaAdapter implements the Adapter interface for Solana
 type SolanaAdapter struct {
-    rpcURL string
-    client *client.Client
+    endpoints  *endpointPool[*client.Client]
+    commitment rpc.Commitment
+    tokens     *tokenMetadataCache
 }
 
-// NewSolanaAdapter creates a new Solana adapter
-func NewSolanaAdapter(rpcURL string) *SolanaAdapter {
-    solClient := client.NewClient(rpcURL)
-    
+// NewSolanaAdapter creates a new Solana adapter that uses the healthiest of the given RPC endpoints
+func NewSolanaAdapter(rpcURLs ...string) *SolanaAdapter {
     return &SolanaAdapter{
-        rpcURL: rpcURL,
-        client: solClient,
+        endpoints:  newSolanaEndpointPool(rpcURLs),
+        commitment: rpc.CommitmentConfirmed,
+        tokens:     newTokenMetadataCache(),
     }
 }
 
+// newSolanaEndpointPool creates a pool of Solana RPC clients, health-checked by their current slot
+func newSolanaEndpointPool(rpcURLs []string) *endpointPool[*client.Client] {
+    clients := make([]*client.Client, 0, len(rpcURLs))
+    for _, url := range rpcURLs {
+        clients = append(clients, client.NewClient(url))
+    }
+
+    probe := func(ctx context.Context, c *client.Client) (uint64, error) {
+        return c.GetSlot(ctx)
+    }
+    return newEndpointPool("solana", rpcURLs, clients, probe)
+}
+
+// call runs RPC requests on the healthiest endpoint, failing over to the next one when it cannot
+// be reached; see endpointPool.call. It returns ErrChainUnavailable when none is configured.
+func (s *SolanaAdapter) call(ctx context.Context, fn func(rpcClient *client.Client) error) error {
+    return s.endpoints.call(ctx, fn)
+}
+
+// Endpoints returns the health of the adapter's RPC endpoints
+func (s *SolanaAdapter) Endpoints() []EndpointStatus {
+    return s.endpoints.statuses()
+}
+
+// SetCommitment sets the commitment level used for reads, blockhashes and preflight checks
+func (s *SolanaAdapter) SetCommitment(commitment rpc.Commitment) {
+    s.commitment = commitment
+}
+
 // CreateWallet creates a new Solana wallet
 func (s *SolanaAdapter) CreateWallet(ctx context.Context) (*Wallet, error) {
     // Generate a new account
@@ -37,140 +109,759 @@ func (s *SolanaAdapter) CreateWallet(ctx context.Context) (*Wallet, error) {
         Address:    account.PublicKey.ToBase58(),
         PublicKey:  account.PublicKey.ToBase58(),
         PrivateKey: fmt.Sprintf("%x", account.PrivateKey),
-        Balance:    0,
+        Balance:    units.Amount{},
     }, nil
 }
 
+// WalletFromPrivateKey builds the Solana wallet controlled by a 32-byte ed25519 seed
+func (s *SolanaAdapter) WalletFromPrivateKey(privateKey []byte) (*Wallet, error) {
+    account, err := types.AccountFromSeed(privateKey)
+    if err != nil {
+        return nil, fmt.Errorf("invalid private key: %w", err)
+    }
+
+    return &Wallet{
+        Address:    account.PublicKey.ToBase58(),
+        PublicKey:  account.PublicKey.ToBase58(),
+        PrivateKey: fmt.Sprintf("%x", account.PrivateKey),
+        Balance:    units.Amount{},
+    }, nil
+}
+
+// ValidateAddress checks that an address is a base58 ed25519 public key on the curve
+func (s *SolanaAdapter) ValidateAddress(address string) error {
+    return validateSolanaAddress(address)
+}
+
 // GetWallet retrieves wallet information
 func (s *SolanaAdapter) GetWallet(ctx context.Context, address string) (*Wallet, error) {
-    // Validate the address
-    _, err := common.PublicKeyFromString(address)
+    balance, err := s.GetBalance(ctx, address)
     if err != nil {
-        return nil, fmt.Errorf("invalid address: %w", err)
-    }
-    
-    // If we have a client, try to fetch the balance from the network
-    if s.client != nil {
-        balance, err := s.client.GetBalance(ctx, address)
-        if err != nil {
-            fmt.Printf("Warning: Could not fetch balance from network: %v\n", err)
-        } else {
-            return &Wallet{
-                Address: address,
-                Balance: float64(balance) / 1000000000, // Convert lamports to SOL
-            }, nil
-        }
+        return nil, err
     }
-    
-    // Fallback to mock behavior
+
     return &Wallet{
         Address: address,
-        Balance: 0,
+        Balance: balance,
     }, nil
 }
 
-// GetBalance retrieves the balance of an address
-func (s *SolanaAdapter) GetBalance(ctx context.Context, address string) (float64, error) {
+// GetBalance retrieves the balance of an address in lamports
+func (s *SolanaAdapter) GetBalance(ctx context.Context, address string) (units.Amount, error) {
     // Validate the address
-    _, err := common.PublicKeyFromString(address)
+    _, err := parseSolanaAddress(address)
     if err != nil {
-        return 0, fmt.Errorf("invalid address: %w", err)
+        return units.Amount{}, fmt.Errorf("invalid address: %w", err)
     }
-    
-    // If we have a client, try to fetch the balance from the network
-    if s.client != nil {
-        balance, err := s.client.GetBalance(ctx, address)
+
+    var balance uint64
+    err = s.call(ctx, func(rpcClient *client.Client) error {
+        var err error
+        balance, err = rpcClient.GetBalanceWithConfig(ctx, address, client.GetBalanceConfig{
+            Commitment: s.commitment,
+        })
         if err != nil {
-            fmt.Printf("Warning: Could not fetch balance from network: %v\n", err)
-        } else {
-            return float64(balance) / 1000000000, // Convert lamports to SOL
+            return fmt.Errorf("failed to fetch balance: %w", ClassifyError(err))
         }
+        return nil
+    })
+    if err != nil {
+        return units.Amount{}, err
     }
 
-    // In a real implementation, this would fetch the balance from the Solana network
-    // For now, we'll return a random balance for demonstration
-    balance, _ := rand.Int(rand.Reader, big.NewInt(1000000000)) // Up to 1 SOL in lamports
-    return float64(balance.Int64()) / 1000000000, nil
+    return units.FromUint64(balance), nil
 }
 
-// SendTransaction sends a Solana transaction
-func (s *SolanaAdapter) SendTransaction(ctx context.Context, from, to string, amount float64, privateKey string) (*Transaction, error) {
-    // Validate addresses
-    _, err := common.PublicKeyFromString(from)
+// SendTransaction signs and sends a SOL transfer with the compute budget of the fee estimate
+func (s *SolanaAdapter) SendTransaction(ctx context.Context, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    sender, err := parseSolanaKey(privateKey, from)
     if err != nil {
-        return nil, fmt.Errorf("invalid from address: %w", err)
+        return nil, err
     }
-    
-    _, err = common.PublicKeyFromString(to)
+
+    if err := validateSolanaAddress(to); err != nil {
+        return nil, fmt.Errorf("invalid to address: %w", err)
+    }
+    toKey, err := parseSolanaAddress(to)
     if err != nil {
         return nil, fmt.Errorf("invalid to address: %w", err)
     }
 
-    // If we have a client, try to send the transaction to the network
-    if s.client != nil {
-        // In a real implementation, this would:
-        // 1. Create and sign the transaction
-        // 2. Submit the transaction to the network
-        // 3. Return the transaction signature
-        
-        // For now, we'll create a mock transaction
-        hash := fmt.Sprintf("%x", make([]byte, 32))
-        rand.Read([]byte(hash))
-
-        return &Transaction{
-            Hash:          hash,
-            From:          from,
-            To:            to,
-            Amount:        amount,
-            Fee:           0.000005, // Standard Solana transaction fee
-            Confirmations: 0,
-            Status:        "pending",
-            Timestamp:     time.Now().Unix(),
-        }, nil
-    }
-
-    // For demonstration, we'll create a mock transaction
-    hash := fmt.Sprintf("%x", make([]byte, 32))
-    rand.Read([]byte(hash))
+    value := amount.BigInt()
+    if !value.IsUint64() {
+        return nil, fmt.Errorf("amount %s is out of range", amount.String())
+    }
+
+    if fee == nil {
+        estimates, err := s.EstimateFee(ctx, from, to, amount)
+        if err != nil {
+            return nil, err
+        }
+        standard, err := estimates.Tier(FeeStandard)
+        if err != nil {
+            return nil, err
+        }
+        fee = &standard
+    }
+
+    instructions := append(computeBudgetInstructions(fee), system.Transfer(system.TransferParam{
+        From:   sender.PublicKey,
+        To:     toKey,
+        Amount: value.Uint64(),
+    }))
+
+    signature, err := s.sendInstructions(ctx, sender, instructions)
+    if err != nil {
+        return nil, err
+    }
 
     return &Transaction{
-        Hash:          hash,
+        Hash:          signature,
         From:          from,
         To:            to,
         Amount:        amount,
-        Fee:           0.000005, // Standard Solana transaction fee
+        Decimals:      solDecimals,
+        Fee:           fee.Fee,
+        FeeTier:       fee.Tier,
+        FeeRate:       fee.FeeRate,
         Confirmations: 0,
         Status:        "pending",
         Timestamp:     time.Now().Unix(),
     }, nil
 }
 
-// GetTransaction retrieves transaction details
+// GetTransaction retrieves a landed transaction, with confirmations counted in slots since it landed.
+// The amount is what the first account after the fee payer gained, the recipient of a SOL transfer.
 func (s *SolanaAdapter) GetTransaction(ctx context.Context, hash string) (*Transaction, error) {
-    // If we have a client, try to fetch the transaction from the network
-    if s.client != nil {
-        // In a real implementation, this would fetch transaction details from the Solana network
-        // For now, we'll return a mock transaction
+    var tx *client.Transaction
+    var slot uint64
+    err := s.call(ctx, func(rpcClient *client.Client) error {
+        var err error
+        tx, err = rpcClient.GetTransactionWithConfig(ctx, hash, client.GetTransactionConfig{
+            Commitment: s.commitment,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to fetch transaction %s: %w", hash, ClassifyError(err))
+        }
+        if tx == nil || tx.Meta == nil {
+            return fmt.Errorf("transaction %s: %w", hash, ErrNotFound)
+        }
+
+        slot, err = rpcClient.GetSlotWithConfig(ctx, client.GetSlotConfig{
+            Commitment: s.commitment,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to fetch current slot: %w", ClassifyError(err))
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
     }
 
-    // In a real implementation, this would fetch transaction details from the Solana network
-    // For now, we'll return a mock transaction
-    return &Transaction{
+    result := &Transaction{
         Hash:          hash,
-        From:          "6q8DkD4D3DcD5D6D7D8D9D0D1D2D3D4D5D6D7D8D9D0D1D2",
-        To:            "7q8DkD4D3DcD5D6D7D8D9D0D1D2D3D4D5D6D7D8D9D0D1D3",
-        Amount:        1.5,
-        Fee:           0.000005,
-        Confirmations: 32,
+        Decimals:      solDecimals,
+        Fee:           units.FromUint64(tx.Meta.Fee),
+        Confirmations: 1,
         Status:        "confirmed",
+    }
+    if slot > tx.Slot {
+        result.Confirmations += int(slot - tx.Slot)
+    }
+    if tx.Meta.Err != nil {
+        result.Status = "failed"
+    }
+    if tx.BlockTime != nil {
+        result.Timestamp = *tx.BlockTime
+    }
+
+    accounts := tx.Transaction.Message.Accounts
+    if len(accounts) > 0 {
+        result.From = accounts[0].ToBase58()
+    }
+    if len(accounts) > 1 && len(tx.Meta.PreBalances) > 1 && len(tx.Meta.PostBalances) > 1 {
+        result.To = accounts[1].ToBase58()
+        if received := tx.Meta.PostBalances[1] - tx.Meta.PreBalances[1]; received > 0 {
+            result.Amount = units.FromInt64(received)
+        }
+    }
+
+    return result, nil
+}
+
+// EstimateFee estimates the fee of a SOL transfer at every tier from recent prioritization fees
+func (s *SolanaAdapter) EstimateFee(ctx context.Context, from, to string, amount units.Amount) (FeeEstimates, error) {
+    fromKey, err := parseSolanaAddress(from)
+    if err != nil {
+        return nil, fmt.Errorf("invalid from address: %w", err)
+    }
+    toKey, err := parseSolanaAddress(to)
+    if err != nil {
+        return nil, fmt.Errorf("invalid to address: %w", err)
+    }
+
+    return s.estimateFees(ctx, []common.PublicKey{fromKey, toKey}, transferComputeUnits)
+}
+
+// EstimateTokenFee estimates the fee of an SPL transfer at every tier from the prioritization
+// fees recently paid to write the token accounts involved, plus the rent of the recipient's token
+// account when the transfer has to create it
+func (s *SolanaAdapter) EstimateTokenFee(ctx context.Context, mint, from, to string, amount units.Amount) (FeeEstimates, error) {
+    if _, err := parseSolanaAddress(mint); err != nil {
+        return nil, fmt.Errorf("invalid mint address: %w", err)
+    }
+
+    sourceATA, err := s.associatedTokenAccount(from, mint)
+    if err != nil {
+        return nil, err
+    }
+    destATA, err := s.associatedTokenAccount(to, mint)
+    if err != nil {
+        return nil, err
+    }
+
+    estimates, err := s.estimateFees(ctx, []common.PublicKey{sourceATA, destATA}, tokenTransferComputeUnits)
+    if err != nil {
+        return nil, err
+    }
+
+    // The sender funds the recipient's token account when it does not exist yet, which locks
+    // up its rent-exempt balance on top of the fee
+    destExists, err := s.accountExists(ctx, destATA)
+    if err != nil {
+        return nil, err
+    }
+    if destExists {
+        return estimates, nil
+    }
+
+    var rent uint64
+    err = s.call(ctx, func(rpcClient *client.Client) error {
+        var err error
+        rent, err = rpcClient.GetMinimumBalanceForRentExemption(ctx, token.TokenAccountSize)
+        if err != nil {
+            return fmt.Errorf("failed to fetch token account rent: %w", ClassifyError(err))
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    for i := range estimates {
+        estimates[i].Fee = estimates[i].Fee.Add(units.FromUint64(rent))
+    }
+    return estimates, nil
+}
+
+// estimateFees prices a single-signature transaction with a compute unit limit at every tier.
+// The priority fee is a percentile of the compute unit prices paid in recent slots by
+// transactions writing the given accounts.
+func (s *SolanaAdapter) estimateFees(ctx context.Context, accounts []common.PublicKey, computeUnits uint32) (FeeEstimates, error) {
+    var prices []uint64
+    err := s.call(ctx, func(rpcClient *client.Client) error {
+        recent, err := rpcClient.GetRecentPrioritizationFees(ctx, accounts)
+        if err != nil {
+            return fmt.Errorf("failed to fetch prioritization fees: %w", ClassifyError(err))
+        }
+
+        prices = make([]uint64, 0, len(recent))
+        for _, slot := range recent {
+            prices = append(prices, slot.PrioritizationFee)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    estimates := make(FeeEstimates, 0, len(solanaTierPolicies))
+    for _, policy := range solanaTierPolicies {
+        price := percentile(prices, policy.percentile)
+
+        // The priority fee is the compute unit price in micro-lamports times the limit, rounded up
+        priorityFee := new(big.Int).Mul(new(big.Int).SetUint64(price), big.NewInt(int64(computeUnits)))
+        priorityFee.Add(priorityFee, big.NewInt(999999))
+        priorityFee.Div(priorityFee, big.NewInt(1000000))
+
+        fee := new(big.Int).Add(priorityFee, big.NewInt(lamportsPerSignature))
+
+        estimates = append(estimates, FeeEstimate{
+            Tier:                 policy.tier,
+            Fee:                  units.NewAmount(fee),
+            ExpectedConfirmation: time.Duration(policy.expectedSlots) * slotDuration,
+            FeeRate:              price,
+            ComputeUnitLimit:     computeUnits,
+        })
+    }
+
+    return estimates, nil
+}
+
+// computeBudgetInstructions sets the compute unit limit and price of a fee estimate
+func computeBudgetInstructions(fee *FeeEstimate) []types.Instruction {
+    return []types.Instruction{
+        compute_budget.SetComputeUnitLimit(compute_budget.SetComputeUnitLimitParam{
+            Units: fee.ComputeUnitLimit,
+        }),
+        compute_budget.SetComputeUnitPrice(compute_budget.SetComputeUnitPriceParam{
+            MicroLamports: fee.FeeRate,
+        }),
+    }
+}
+
+// GetTokenBalance retrieves the SPL token balance held in an owner's associated token account
+func (s *SolanaAdapter) GetTokenBalance(ctx context.Context, mint, owner string) (units.Amount, error) {
+    ata, err := s.associatedTokenAccount(owner, mint)
+    if err != nil {
+        return units.Amount{}, err
+    }
+
+    // A wallet without a token account simply holds none of the token
+    exists, err := s.accountExists(ctx, ata)
+    if err != nil {
+        return units.Amount{}, err
+    }
+    if !exists {
+        return units.Amount{}, nil
+    }
+
+    var balance uint64
+    err = s.call(ctx, func(rpcClient *client.Client) error {
+        tokenAmount, err := rpcClient.GetTokenAccountBalanceWithConfig(ctx, ata.ToBase58(), client.GetTokenAccountBalanceConfig{
+            Commitment: s.commitment,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to fetch token balance: %w", ClassifyError(err))
+        }
+        balance = tokenAmount.Amount
+        return nil
+    })
+    if err != nil {
+        return units.Amount{}, err
+    }
+
+    return units.FromUint64(balance), nil
+}
+
+// SendTokenTransaction signs and sends an SPL token transfer between the owners' associated
+// token accounts, creating the recipient's account first when it does not exist yet
+func (s *SolanaAdapter) SendTokenTransaction(ctx context.Context, mint, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    sender, err := parseSolanaKey(privateKey, from)
+    if err != nil {
+        return nil, err
+    }
+
+    mintKey, err := parseSolanaAddress(mint)
+    if err != nil {
+        return nil, fmt.Errorf("invalid mint address: %w", err)
+    }
+
+    if err := validateSolanaAddress(to); err != nil {
+        return nil, fmt.Errorf("invalid to address: %w", err)
+    }
+    toKey, err := parseSolanaAddress(to)
+    if err != nil {
+        return nil, fmt.Errorf("invalid to address: %w", err)
+    }
+
+    meta, err := s.GetTokenMetadata(ctx, mint)
+    if err != nil {
+        return nil, err
+    }
+
+    sourceATA, err := s.associatedTokenAccount(from, mint)
+    if err != nil {
+        return nil, err
+    }
+
+    destATA, err := s.associatedTokenAccount(to, mint)
+    if err != nil {
+        return nil, err
+    }
+
+    if fee == nil {
+        estimates, err := s.EstimateTokenFee(ctx, mint, from, to, amount)
+        if err != nil {
+            return nil, err
+        }
+        standard, err := estimates.Tier(FeeStandard)
+        if err != nil {
+            return nil, err
+        }
+        fee = &standard
+    }
+
+    // The compute budget comes first so it applies to the whole transaction
+    instructions := computeBudgetInstructions(fee)
+
+    destExists, err := s.accountExists(ctx, destATA)
+    if err != nil {
+        return nil, err
+    }
+    if !destExists {
+        instructions = append(instructions, associated_token_account.CreateIdempotent(associated_token_account.CreateIdempotentParam{
+            Funder:                 sender.PublicKey,
+            Owner:                  toKey,
+            Mint:                   mintKey,
+            AssociatedTokenAccount: destATA,
+        }))
+    }
+
+    value := amount.BigInt()
+    if !value.IsUint64() {
+        return nil, fmt.Errorf("amount %s is out of range", amount.String())
+    }
+
+    instructions = append(instructions, token.TransferChecked(token.TransferCheckedParam{
+        From:     sourceATA,
+        To:       destATA,
+        Mint:     mintKey,
+        Auth:     sender.PublicKey,
+        Signers:  []common.PublicKey{},
+        Amount:   value.Uint64(),
+        Decimals: meta.Decimals,
+    }))
+
+    signature, err := s.sendInstructions(ctx, sender, instructions)
+    if err != nil {
+        return nil, err
+    }
+
+    return &Transaction{
+        Hash:          signature,
+        From:          from,
+        To:            to,
+        Amount:        amount,
+        Decimals:      meta.Decimals,
+        Fee:           fee.Fee,
+        FeeTier:       fee.Tier,
+        FeeRate:       fee.FeeRate,
+        Confirmations: 0,
+        Status:        "pending",
         Timestamp:     time.Now().Unix(),
+        Token:         meta.Contract,
+        TokenSymbol:   meta.Symbol,
     }, nil
 }
 
-// EstimateFee estimates the transaction fee
-func (s *SolanaAdapter) EstimateFee(ctx context.Context, from, to string, amount float64) (float64, error) {
-    // Solana has a fixed fee model, so we'll return the standard fee
-    return 0.000005, nil
+// GetTokenMetadata retrieves the decimals of an SPL mint, and its symbol when it is well known
+func (s *SolanaAdapter) GetTokenMetadata(ctx context.Context, mint string) (*TokenMetadata, error) {
+    if _, err := parseSolanaAddress(mint); err != nil {
+        return nil, fmt.Errorf("invalid mint address: %w", err)
+    }
+
+    if meta, ok := s.tokens.get(mint); ok {
+        return meta, nil
+    }
+
+    var decimals uint8
+    err := s.call(ctx, func(rpcClient *client.Client) error {
+        supply, err := rpcClient.GetTokenSupplyWithConfig(ctx, mint, client.GetTokenSupplyConfig{
+            Commitment: s.commitment,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to fetch mint info: %w", ClassifyError(err))
+        }
+        decimals = supply.Decimals
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    meta := &TokenMetadata{
+        Contract: mint,
+        Symbol:   knownSPLSymbols[mint],
+        Decimals: decimals,
+    }
+    s.tokens.put(mint, meta)
+
+    return meta, nil
+}
+
+// CreateTokenAccount creates the associated token account of an owner for a mint, paid for by the payer.
+// It returns the token account address and is a no-op when the account already exists.
+func (s *SolanaAdapter) CreateTokenAccount(ctx context.Context, owner, mint, payer, payerPrivateKey string) (string, error) {
+    payerAccount, err := parseSolanaKey(payerPrivateKey, payer)
+    if err != nil {
+        return "", err
+    }
+
+    ownerKey, err := parseSolanaAddress(owner)
+    if err != nil {
+        return "", fmt.Errorf("invalid owner address: %w", err)
+    }
+
+    mintKey, err := parseSolanaAddress(mint)
+    if err != nil {
+        return "", fmt.Errorf("invalid mint address: %w", err)
+    }
+
+    ata, err := s.associatedTokenAccount(owner, mint)
+    if err != nil {
+        return "", err
+    }
+
+    exists, err := s.accountExists(ctx, ata)
+    if err != nil {
+        return "", err
+    }
+    if exists {
+        return ata.ToBase58(), nil
+    }
+
+    _, err = s.sendInstructions(ctx, payerAccount, []types.Instruction{
+        associated_token_account.CreateIdempotent(associated_token_account.CreateIdempotentParam{
+            Funder:                 payerAccount.PublicKey,
+            Owner:                  ownerKey,
+            Mint:                   mintKey,
+            AssociatedTokenAccount: ata,
+        }),
+    })
+    if err != nil {
+        return "", err
+    }
+
+    return ata.ToBase58(), nil
+}
+
+// ListTokenTransfers lists recent incoming SPL transfers of a mint to an owner's associated token account
+func (s *SolanaAdapter) ListTokenTransfers(ctx context.Context, mint, owner string, limit int) ([]*Transaction, error) {
+    ata, err := s.associatedTokenAccount(owner, mint)
+    if err != nil {
+        return nil, err
+    }
+
+    meta, err := s.GetTokenMetadata(ctx, mint)
+    if err != nil {
+        return nil, err
+    }
+
+    // Signatures of the transactions that succeeded
+    var signatures []string
+    err = s.call(ctx, func(rpcClient *client.Client) error {
+        results, err := rpcClient.GetSignaturesForAddressWithConfig(ctx, ata.ToBase58(), client.GetSignaturesForAddressConfig{
+            Limit:      limit,
+            Commitment: s.commitment,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to list token account signatures: %w", ClassifyError(err))
+        }
+
+        signatures = signatures[:0]
+        for _, sig := range results {
+            if sig.Err == nil {
+                signatures = append(signatures, sig.Signature)
+            }
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    var transfers []*Transaction
+    for _, signature := range signatures {
+        var tx *client.Transaction
+        err := s.call(ctx, func(rpcClient *client.Client) error {
+            var err error
+            tx, err = rpcClient.GetTransactionWithConfig(ctx, signature, client.GetTransactionConfig{
+                Commitment: s.commitment,
+            })
+            if err != nil {
+                return fmt.Errorf("failed to fetch transaction %s: %w", signature, ClassifyError(err))
+            }
+            return nil
+        })
+        if err != nil {
+            return nil, err
+        }
+        if tx == nil || tx.Meta == nil {
+            continue
+        }
+
+        received := tokenBalanceDelta(tx.Meta.PreTokenBalances, tx.Meta.PostTokenBalances, owner, mint)
+        if received.Sign() <= 0 {
+            // Outgoing transfers and unrelated instructions touching the account
+            continue
+        }
+
+        var timestamp int64
+        if tx.BlockTime != nil {
+            timestamp = *tx.BlockTime
+        }
+
+        transfers = append(transfers, &Transaction{
+            Hash:        signature,
+            From:        tx.Transaction.Message.Accounts[0].ToBase58(),
+            To:          owner,
+            Amount:      units.NewAmount(received),
+            Decimals:    meta.Decimals,
+            Fee:         units.FromUint64(tx.Meta.Fee),
+            Status:      "confirmed",
+            Timestamp:   timestamp,
+            Token:       meta.Contract,
+            TokenSymbol: meta.Symbol,
+        })
+    }
+
+    return transfers, nil
+}
+
+// tokenBalanceDelta returns how much of a mint an owner gained in a transaction
+func tokenBalanceDelta(pre, post []rpc.TransactionMetaTokenBalance, owner, mint string) *big.Int {
+    sum := func(balances []rpc.TransactionMetaTokenBalance) *big.Int {
+        total := new(big.Int)
+        for _, b := range balances {
+            if b.Owner != owner || b.Mint != mint {
+                continue
+            }
+            amount, err := strconv.ParseUint(b.UITokenAmount.Amount, 10, 64)
+            if err != nil {
+                continue
+            }
+            total.Add(total, new(big.Int).SetUint64(amount))
+        }
+        return total
+    }
+
+    return new(big.Int).Sub(sum(post), sum(pre))
+}
+
+// associatedTokenAccount derives the associated token account of an owner for a mint
+func (s *SolanaAdapter) associatedTokenAccount(owner, mint string) (common.PublicKey, error) {
+    ownerKey, err := parseSolanaAddress(owner)
+    if err != nil {
+        return common.PublicKey{}, fmt.Errorf("invalid owner address: %w", err)
+    }
+
+    mintKey, err := parseSolanaAddress(mint)
+    if err != nil {
+        return common.PublicKey{}, fmt.Errorf("invalid mint address: %w", err)
+    }
+
+    ata, _, err := common.FindAssociatedTokenAddress(ownerKey, mintKey)
+    if err != nil {
+        return common.PublicKey{}, fmt.Errorf("failed to derive associated token account: %w", err)
+    }
+
+    return ata, nil
+}
+
+// accountExists reports whether an account has been created on chain
+func (s *SolanaAdapter) accountExists(ctx context.Context, account common.PublicKey) (bool, error) {
+    var exists bool
+    err := s.call(ctx, func(rpcClient *client.Client) error {
+        info, err := rpcClient.GetAccountInfoWithConfig(ctx, account.ToBase58(), client.GetAccountInfoConfig{
+            Commitment: s.commitment,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to fetch account %s: %w", account.ToBase58(), ClassifyError(err))
+        }
+        exists = info.Owner != (common.PublicKey{})
+        return nil
+    })
+    if err != nil {
+        return false, err
+    }
+
+    return exists, nil
+}
+
+// sendInstructions signs the instructions with a recent blockhash and submits them, returning the
+// signature. The transaction is signed once: failing over only resends it as it was signed.
+func (s *SolanaAdapter) sendInstructions(ctx context.Context, payer types.Account, instructions []types.Instruction) (string, error) {
+    var blockhash string
+    err := s.call(ctx, func(rpcClient *client.Client) error {
+        latest, err := rpcClient.GetLatestBlockhashWithConfig(ctx, client.GetLatestBlockhashConfig{
+            Commitment: s.commitment,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to fetch recent blockhash: %w", ClassifyError(err))
+        }
+        blockhash = latest.Blockhash
+        return nil
+    })
+    if err != nil {
+        return "", err
+    }
+
+    tx, err := types.NewTransaction(types.NewTransactionParam{
+        Message: types.NewMessage(types.NewMessageParam{
+            FeePayer:        payer.PublicKey,
+            RecentBlockhash: blockhash,
+            Instructions:    instructions,
+        }),
+        Signers: []types.Account{payer},
+    })
+    if err != nil {
+        return "", fmt.Errorf("failed to build transaction: %w", err)
+    }
+
+    raw, err := tx.Serialize()
+    if err != nil {
+        return "", fmt.Errorf("failed to serialize transaction: %w", err)
+    }
+    signature := base58.Encode(tx.Signatures[0])
+    if err := s.broadcast(ctx, signature, hex.EncodeToString(raw), tx); err != nil {
+        return "", err
+    }
+
+    return signature, nil
+}
+
+// broadcast sends a signed transaction, to the next endpoint when one fails. A JSON-RPC error is
+// the node refusing it, after its preflight simulation failed; any other failure leaves unknown
+// whether it was forwarded and is a BroadcastError.
+func (s *SolanaAdapter) broadcast(ctx context.Context, signature, raw string, tx types.Transaction) error {
+    return s.call(ctx, func(rpcClient *client.Client) error {
+        _, err := rpcClient.SendTransactionWithConfig(ctx, tx, client.SendTransactionConfig{
+            PreflightCommitment: s.commitment,
+        })
+        if err == nil || isAlreadyKnown(err) {
+            return nil
+        }
+
+        var rpcErr *rpc.JsonRpcError
+        if errors.As(err, &rpcErr) {
+            return fmt.Errorf("failed to send transaction: %w", ClassifyError(err))
+        }
+        return &BroadcastError{Hash: signature, Raw: raw, Err: fmt.Errorf("failed to send transaction: %w", ClassifyError(err))}
+    })
+}
+
+// RebroadcastTransaction sends a transaction signed by this adapter again, as the hex its
+// BroadcastError carried. It is refused once the transaction's blockhash has expired.
+func (s *SolanaAdapter) RebroadcastTransaction(ctx context.Context, raw string) error {
+    data, err := hex.DecodeString(raw)
+    if err != nil {
+        return fmt.Errorf("invalid raw transaction: %w", err)
+    }
+    tx, err := types.TransactionDeserialize(data)
+    if err != nil {
+        return fmt.Errorf("invalid raw transaction: %w", err)
+    }
+    if len(tx.Signatures) == 0 {
+        return fmt.Errorf("raw transaction is not signed")
+    }
+
+    return s.broadcast(ctx, base58.Encode(tx.Signatures[0]), raw, tx)
+}
+
+// parseSolanaKey decodes a hex ed25519 private key and checks that it controls the from address
+func parseSolanaKey(privateKey, from string) (types.Account, error) {
+    account, err := types.AccountFromHex(privateKey)
+    if err != nil {
+        return types.Account{}, fmt.Errorf("invalid private key: %w", err)
+    }
+
+    if account.PublicKey.ToBase58() != from {
+        return types.Account{}, fmt.Errorf("private key does not match from address %s", from)
+    }
+
+    return account, nil
 }
 
 // ConnectToTestnet connects to a Solana testnet
@@ -185,8 +876,8 @@ func (s *SolanaAdapter) ConnectToTestnet(network string) error {
         return fmt.Errorf("unsupported testnet: %s", network)
     }
     
-    s.client = client.NewClient(rpcURL)
-    s.rpcURL = rpcURL
+    s.endpoints = newSolanaEndpointPool([]string{rpcURL})
+    s.tokens = newTokenMetadataCache()
     
     return nil
 }
\ No newline at end of file
@@ -0,0 +1,186 @@
+package blockchain
+
+import (
+    "context"
+    "fmt"
+    "math/big"
+    "strconv"
+
+    "github.com/blocto/solana-go-sdk/client"
+    "github.com/blocto/solana-go-sdk/rpc"
+)
+
+// SolanaBlock is a block of a Solana slot with the balance increases of its successful transactions
+type SolanaBlock struct {
+    Slot         uint64
+    Hash         string
+    PreviousHash string
+    Time         int64
+    Transfers    []SolanaTransfer
+}
+
+// SolanaTransfer is an increase of an account's SOL balance, or of an owner's balance of an SPL
+// mint, in one transaction
+type SolanaTransfer struct {
+    Signature string
+    Index     uint   // index of the credited account, or of the credited token account
+    From      string // fee payer
+    To        string // credited account, or owner of the credited token account
+    Mint      string // empty for SOL
+    Amount    *big.Int
+    Decimals  uint8
+}
+
+// FinalizedSlot returns the latest slot the cluster finalized. Blocks at or below it cannot be
+// rolled back, so a deposit scanner reading them never sees a reorg.
+func (s *SolanaAdapter) FinalizedSlot(ctx context.Context) (uint64, error) {
+    var slot uint64
+    err := s.call(ctx, func(rpcClient *client.Client) error {
+        var err error
+        slot, err = rpcClient.GetSlotWithConfig(ctx, client.GetSlotConfig{
+            Commitment: rpc.CommitmentFinalized,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to fetch finalized slot: %w", ClassifyError(err))
+        }
+        return nil
+    })
+    if err != nil {
+        return 0, err
+    }
+    return slot, nil
+}
+
+// GetBlocks returns the finalized blocks of the slots from one to another, inclusive. Skipped
+// slots have no block, so there may be fewer blocks than slots.
+func (s *SolanaAdapter) GetBlocks(ctx context.Context, from, to uint64) ([]*SolanaBlock, error) {
+    var slots []uint64
+    err := s.call(ctx, func(rpcClient *client.Client) error {
+        var err error
+        slots, err = rpcClient.GetBlocks(ctx, from, to)
+        if err != nil {
+            return fmt.Errorf("failed to list blocks %d to %d: %w", from, to, ClassifyError(err))
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    blocks := make([]*SolanaBlock, 0, len(slots))
+    for _, slot := range slots {
+        var block *client.Block
+        err := s.call(ctx, func(rpcClient *client.Client) error {
+            var err error
+            block, err = rpcClient.GetBlockWithConfig(ctx, slot, client.GetBlockConfig{
+                Commitment:         rpc.CommitmentFinalized,
+                TransactionDetails: rpc.GetBlockConfigTransactionDetailsFull,
+            })
+            if err != nil {
+                return fmt.Errorf("failed to fetch block %d: %w", slot, ClassifyError(err))
+            }
+            return nil
+        })
+        if err != nil {
+            return nil, err
+        }
+        blocks = append(blocks, solanaBlock(slot, block))
+    }
+
+    return blocks, nil
+}
+
+// BlockHash returns the hash of the block of a slot, or ErrNotFound if the slot was skipped
+func (s *SolanaAdapter) BlockHash(ctx context.Context, slot uint64) (string, error) {
+    var hash string
+    err := s.call(ctx, func(rpcClient *client.Client) error {
+        block, err := rpcClient.GetBlockWithConfig(ctx, slot, client.GetBlockConfig{
+            Commitment:         rpc.CommitmentFinalized,
+            TransactionDetails: rpc.GetBlockConfigTransactionDetailsNone,
+        })
+        if err != nil {
+            return fmt.Errorf("failed to fetch block %d: %w", slot, ClassifyError(err))
+        }
+        if block == nil {
+            return fmt.Errorf("block %d: %w", slot, ErrNotFound)
+        }
+        hash = block.Blockhash
+        return nil
+    })
+    if err != nil {
+        return "", err
+    }
+    return hash, nil
+}
+
+// solanaBlock collects the balance increases of a block's successful transactions
+func solanaBlock(slot uint64, block *client.Block) *SolanaBlock {
+    result := &SolanaBlock{
+        Slot:         slot,
+        Hash:         block.Blockhash,
+        PreviousHash: block.PreviousBlockhash,
+    }
+    if block.BlockTime != nil {
+        result.Time = block.BlockTime.Unix()
+    }
+
+    for _, tx := range block.Transactions {
+        if tx.Meta == nil || tx.Meta.Err != nil || len(tx.Transaction.Signatures) == 0 || len(tx.AccountKeys) == 0 {
+            continue
+        }
+        signature := tx.Transaction.Signatures[0].ToBase58()
+        payer := tx.AccountKeys[0].ToBase58()
+
+        for i, key := range tx.AccountKeys {
+            if i >= len(tx.Meta.PreBalances) || i >= len(tx.Meta.PostBalances) {
+                break
+            }
+            if received := tx.Meta.PostBalances[i] - tx.Meta.PreBalances[i]; received > 0 {
+                result.Transfers = append(result.Transfers, SolanaTransfer{
+                    Signature: signature,
+                    Index:     uint(i),
+                    From:      payer,
+                    To:        key.ToBase58(),
+                    Amount:    big.NewInt(received),
+                    Decimals:  solDecimals,
+                })
+            }
+        }
+
+        // Token balances are listed per token account; an account missing before the
+        // transaction was created by it and held nothing
+        pre := make(map[uint64]*big.Int, len(tx.Meta.PreTokenBalances))
+        for _, b := range tx.Meta.PreTokenBalances {
+            pre[b.AccountIndex] = tokenAmount(b)
+        }
+        for _, b := range tx.Meta.PostTokenBalances {
+            received := tokenAmount(b)
+            if before, ok := pre[b.AccountIndex]; ok {
+                received.Sub(received, before)
+            }
+            if received.Sign() <= 0 || b.Owner == "" {
+                continue
+            }
+            result.Transfers = append(result.Transfers, SolanaTransfer{
+                Signature: signature,
+                Index:     uint(b.AccountIndex),
+                From:      payer,
+                To:        b.Owner,
+                Mint:      b.Mint,
+                Amount:    received,
+                Decimals:  b.UITokenAmount.Decimals,
+            })
+        }
+    }
+
+    return result
+}
+
+// tokenAmount returns the raw amount of a token balance, or zero if it cannot be parsed
+func tokenAmount(b rpc.TransactionMetaTokenBalance) *big.Int {
+    amount, err := strconv.ParseUint(b.UITokenAmount.Amount, 10, 64)
+    if err != nil {
+        return new(big.Int)
+    }
+    return new(big.Int).SetUint64(amount)
+}
@@ -2,211 +2,738 @@ package blockchain
 
 import (
     "context"
-    "crypto/rand"
+    "crypto/ecdsa"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
     "fmt"
     "math/big"
+    "strings"
+    "sync"
     "time"
 
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/ethereum/go-ethereum/crypto"
     "github.com/fbsobreira/gotron-sdk/pkg/address"
     "github.com/fbsobreira/gotron-sdk/pkg/client"
+    "github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+    "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/credentials/insecure"
+    "google.golang.org/protobuf/proto"
+)
+
+// trxDecimals is the number of decimals of TRX (1 TRX = 1,000,000 SUN)
+const trxDecimals = 6
+
+// defaultTRC20FeeLimit caps the TRX burnt for energy by a TRC-20 transfer (100 TRX in SUN)
+const defaultTRC20FeeLimit = 100000000
+
+// Bandwidth consumed by typical transactions, in bytes
+const (
+    trxTransferBandwidth   = 270
+    trc20TransferBandwidth = 350
+)
+
+// tronActivationFee is the TRX burnt, in SUN, by a TRX transfer that creates the recipient's account
+const tronActivationFee = 1000000
+
+// tronBlockTime is the interval between Tron blocks
+const tronBlockTime = 3 * time.Second
+
+// Default resource prices used when the chain parameters cannot be read, in SUN
+const (
+    defaultTronBandwidthPrice = 1000
+    defaultTronEnergyPrice    = 420
 )
 
 // TronAdapter implements the Adapter interface for Tron
 type TronAdapter struct {
-    grpcURL string
+    endpoints *endpointPool[*tronConn]
+    feeLimit  int64
+    tokens    *tokenMetadataCache
+}
+
+// tronConn is a gRPC connection to one Tron node, started on first use
+type tronConn struct {
+    url     string
     client  *client.GrpcClient
+    mu      sync.Mutex
+    started bool
 }
 
-// NewTronAdapter creates a new Tron adapter
-func NewTronAdapter(grpcURL string) *TronAdapter {
-    tronClient := client.NewGrpcClient(grpcURL)
-    
+// start starts the gRPC connection once and reuses it afterwards
+func (c *tronConn) start() (*client.GrpcClient, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if !c.started {
+        if err := c.client.Start(grpc.WithTransportCredentials(insecure.NewCredentials())); err != nil {
+            return nil, fmt.Errorf("failed to connect to Tron node at %s: %w", c.url, err)
+        }
+        c.started = true
+    }
+
+    return c.client, nil
+}
+
+// stop closes the connection if it was started
+func (c *tronConn) stop() {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    if c.started {
+        c.client.Stop()
+        c.started = false
+    }
+}
+
+// NewTronAdapter creates a new Tron adapter that uses the healthiest of the given gRPC endpoints
+func NewTronAdapter(grpcURLs ...string) *TronAdapter {
     return &TronAdapter{
-        grpcURL: grpcURL,
-        client:  tronClient,
+        endpoints: newTronEndpointPool(grpcURLs),
+        feeLimit:  defaultTRC20FeeLimit,
+        tokens:    newTokenMetadataCache(),
     }
 }
 
+// newTronEndpointPool creates a pool of Tron nodes, health-checked by their latest block
+func newTronEndpointPool(grpcURLs []string) *endpointPool[*tronConn] {
+    conns := make([]*tronConn, 0, len(grpcURLs))
+    for _, url := range grpcURLs {
+        conns = append(conns, &tronConn{url: url, client: client.NewGrpcClient(url)})
+    }
+
+    probe := func(ctx context.Context, conn *tronConn) (uint64, error) {
+        node, err := conn.start()
+        if err != nil {
+            return 0, err
+        }
+        block, err := node.GetNowBlock()
+        if err != nil {
+            return 0, err
+        }
+        return uint64(block.GetBlockHeader().GetRawData().GetNumber()), nil
+    }
+    return newEndpointPool("tron", grpcURLs, conns, probe)
+}
+
+// Endpoints returns the health of the adapter's gRPC endpoints
+func (t *TronAdapter) Endpoints() []EndpointStatus {
+    return t.endpoints.statuses()
+}
+
+// SetFeeLimit sets the maximum TRX, in SUN, a TRC-20 transfer may burn for energy
+func (t *TronAdapter) SetFeeLimit(feeLimit int64) {
+    t.feeLimit = feeLimit
+}
+
+// call runs requests on a started connection to the healthiest Tron node, failing over to the
+// next one when it cannot be reached; see endpointPool.call
+func (t *TronAdapter) call(ctx context.Context, fn func(node *client.GrpcClient) error) error {
+    return t.endpoints.call(ctx, func(conn *tronConn) error {
+        node, err := conn.start()
+        if err != nil {
+            return fmt.Errorf("%w: %w", ErrChainUnavailable, err)
+        }
+        return fn(node)
+    })
+}
+
 // CreateWallet creates a new Tron wallet
 func (t *TronAdapter) CreateWallet(ctx context.Context) (*Wallet, error) {
-    // Generate a new account
-    // In a real implementation, this would use the Tron SDK to generate a key pair
-    // For now, we'll generate mock values
-    
-    // Generate a random address (this is just for demonstration)
-    addrBytes := make([]byte, 20)
-    rand.Read(addrBytes)
-    
-    addr := address.Address(addrBytes)
-    
+    // Tron uses the same secp256k1 keys as Ethereum
+    privateKey, err := crypto.GenerateKey()
+    if err != nil {
+        return nil, fmt.Errorf("failed to generate private key: %w", err)
+    }
+
+    // The address is the Keccak hash of the public key with a 0x41 prefix, base58check encoded
+    addr := address.PubkeyToAddress(privateKey.PublicKey)
+
     return &Wallet{
         Address:    addr.String(),
-        PublicKey:  fmt.Sprintf("%x", addrBytes),
-        PrivateKey: fmt.Sprintf("%x", make([]byte, 32)),
-        Balance:    0,
+        PublicKey:  fmt.Sprintf("%x", crypto.FromECDSAPub(&privateKey.PublicKey)),
+        PrivateKey: fmt.Sprintf("%x", crypto.FromECDSA(privateKey)),
+        Balance:    units.Amount{},
     }, nil
 }
 
-// GetWallet retrieves wallet information
-func (t *TronAdapter) GetWallet(ctx context.Context, address string) (*Wallet, error) {
-    // Validate the address
-    _, err := address.HexToAddress(address)
+// WalletFromPrivateKey builds the Tron wallet controlled by a raw secp256k1 private key
+func (t *TronAdapter) WalletFromPrivateKey(privateKey []byte) (*Wallet, error) {
+    key, err := crypto.ToECDSA(privateKey)
     if err != nil {
-        return nil, fmt.Errorf("invalid address: %w", err)
+        return nil, fmt.Errorf("invalid private key: %w", err)
     }
-    
-    // If we have a client, try to fetch the balance from the network
-    if t.client != nil {
-        // Initialize the client
-        if err := t.client.Start(grpc.WithInsecure()); err != nil {
-            fmt.Printf("Warning: Could not connect to Tron node: %v\n", err)
-        } else {
-            defer t.client.Stop()
-            
-            // Get account info
-            account, err := t.client.GetAccount(address)
-            if err != nil {
-                fmt.Printf("Warning: Could not fetch account info: %v\n", err)
-            } else {
-                return &Wallet{
-                    Address: address,
-                    Balance: float64(account.Balance) / 1000000, // Convert SUN to TRX
-                }, nil
-            }
-        }
+
+    return &Wallet{
+        Address:    address.PubkeyToAddress(key.PublicKey).String(),
+        PublicKey:  fmt.Sprintf("%x", crypto.FromECDSAPub(&key.PublicKey)),
+        PrivateKey: fmt.Sprintf("%x", crypto.FromECDSA(key)),
+        Balance:    units.Amount{},
+    }, nil
+}
+
+// ValidateAddress checks that an address is a base58check Tron address
+func (t *TronAdapter) ValidateAddress(addr string) error {
+    return validateTronAddress(addr)
+}
+
+// GetWallet retrieves wallet information
+func (t *TronAdapter) GetWallet(ctx context.Context, addr string) (*Wallet, error) {
+    balance, err := t.GetBalance(ctx, addr)
+    if err != nil {
+        return nil, err
     }
-    
-    // Fallback to mock behavior
+
     return &Wallet{
-        Address: address,
-        Balance: 0,
+        Address: addr,
+        Balance: balance,
     }, nil
 }
 
-// GetBalance retrieves the balance of an address
-func (t *TronAdapter) GetBalance(ctx context.Context, address string) (float64, error) {
+// GetBalance retrieves the TRX balance of an address
+func (t *TronAdapter) GetBalance(ctx context.Context, addr string) (units.Amount, error) {
     // Validate the address
-    _, err := address.HexToAddress(address)
-    if err != nil {
-        return 0, fmt.Errorf("invalid address: %w", err)
+    if _, err := address.Base58ToAddress(addr); err != nil {
+        return units.Amount{}, fmt.Errorf("invalid address: %w", err)
     }
     
-    // If we have a client, try to fetch the balance from the network
-    if t.client != nil {
-        // Initialize the client
-        if err := t.client.Start(grpc.WithInsecure()); err != nil {
-            fmt.Printf("Warning: Could not connect to Tron node: %v\n", err)
-        } else {
-            defer t.client.Stop()
-            
-            // Get account info
-            account, err := t.client.GetAccount(address)
-            if err != nil {
-                fmt.Printf("Warning: Could not fetch account info: %v\n", err)
-            } else {
-                return float64(account.Balance) / 1000000, // Convert SUN to TRX
+    var balance int64
+    err := t.call(ctx, func(node *client.GrpcClient) error {
+        account, err := node.GetAccount(addr)
+        if err != nil {
+            // Addresses that never received TRX have no account yet
+            if isTronAccountNotFound(err) {
+                balance = 0
+                return nil
             }
+            return fmt.Errorf("failed to fetch account info: %w", ClassifyError(err))
         }
+        balance = account.Balance
+        return nil
+    })
+    if err != nil {
+        return units.Amount{}, err
     }
 
-    // In a real implementation, this would fetch the balance from the Tron network
-    // For now, we'll return a random balance for demonstration
-    balance, _ := rand.Int(rand.Reader, big.NewInt(1000000)) // Up to 1 TRX in SUN
-    return float64(balance.Int64()) / 1000000, nil
+    return units.FromInt64(balance), nil
 }
 
-// SendTransaction sends a Tron transaction
-func (t *TronAdapter) SendTransaction(ctx context.Context, from, to string, amount float64, privateKey string) (*Transaction, error) {
+// SendTransaction signs and broadcasts a TRX transfer
+func (t *TronAdapter) SendTransaction(ctx context.Context, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error) {
     // Validate addresses
-    _, err := address.HexToAddress(from)
-    if err != nil {
+    if _, err := address.Base58ToAddress(from); err != nil {
         return nil, fmt.Errorf("invalid from address: %w", err)
     }
-    
-    _, err = address.HexToAddress(to)
-    if err != nil {
+    if err := validateTronAddress(to); err != nil {
         return nil, fmt.Errorf("invalid to address: %w", err)
     }
 
-    // If we have a client, try to send the transaction to the network
-    if t.client != nil {
-        // Initialize the client
-        if err := t.client.Start(grpc.WithInsecure()); err != nil {
-            fmt.Printf("Warning: Could not connect to Tron node: %v\n", err)
-        } else {
-            defer t.client.Stop()
-            
-            // In a real implementation, this would:
-            // 1. Create and sign the transaction
-            // 2. Submit the transaction to the network
-            // 3. Return the transaction hash
-            
-            // For now, we'll create a mock transaction
-            hash := fmt.Sprintf("%x", make([]byte, 32))
-            rand.Read([]byte(hash))
-
-            return &Transaction{
-                Hash:          hash,
-                From:          from,
-                To:            to,
-                Amount:        amount,
-                Fee:           0.1, // Standard Tron transaction fee
-                Confirmations: 0,
-                Status:        "pending",
-                Timestamp:     time.Now().Unix(),
-            }, nil
-        }
-    }
-
-    // For demonstration, we'll create a mock transaction
-    hash := fmt.Sprintf("%x", make([]byte, 32))
-    rand.Read([]byte(hash))
+    key, err := parseTronKey(privateKey, from)
+    if err != nil {
+        return nil, err
+    }
+
+    value := amount.BigInt()
+    if !value.IsInt64() {
+        return nil, fmt.Errorf("amount %s SUN is out of range", amount.String())
+    }
+
+    if fee == nil {
+        estimates, err := t.EstimateFee(ctx, from, to, amount)
+        if err != nil {
+            return nil, err
+        }
+        standard, err := estimates.Tier(FeeStandard)
+        if err != nil {
+            return nil, err
+        }
+        fee = &standard
+    }
+
+    var txExt *api.TransactionExtention
+    err = t.call(ctx, func(node *client.GrpcClient) error {
+        var err error
+        txExt, err = node.Transfer(from, to, value.Int64())
+        if err != nil {
+            return fmt.Errorf("failed to build TRX transfer: %w", err)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    hash, err := t.signAndBroadcast(ctx, txExt, key)
+    if err != nil {
+        return nil, err
+    }
 
     return &Transaction{
         Hash:          hash,
         From:          from,
         To:            to,
         Amount:        amount,
-        Fee:           0.1, // Standard Tron transaction fee
+        Decimals:      trxDecimals,
+        Fee:           fee.Fee,
+        FeeTier:       fee.Tier,
         Confirmations: 0,
         Status:        "pending",
         Timestamp:     time.Now().Unix(),
     }, nil
 }
 
-// GetTransaction retrieves transaction details
-func (t *TronAdapter) GetTransaction(ctx context.Context, hash string) (*Transaction, error) {
-    // If we have a client, try to fetch the transaction from the network
-    if t.client != nil {
-        // Initialize the client
-        if err := t.client.Start(grpc.WithInsecure()); err != nil {
-            fmt.Printf("Warning: Could not connect to Tron node: %v\n", err)
-        } else {
-            defer t.client.Stop()
-            
-            // In a real implementation, this would fetch transaction details from the Tron network
-            // For now, we'll return a mock transaction
+// GetTokenBalance retrieves the TRC-20 token balance of an address
+func (t *TronAdapter) GetTokenBalance(ctx context.Context, token, addr string) (units.Amount, error) {
+    if _, err := address.Base58ToAddress(addr); err != nil {
+        return units.Amount{}, fmt.Errorf("invalid address: %w", err)
+    }
+    if _, err := address.Base58ToAddress(token); err != nil {
+        return units.Amount{}, fmt.Errorf("invalid token contract address: %w", err)
+    }
+
+    var balance *big.Int
+    err := t.call(ctx, func(node *client.GrpcClient) error {
+        var err error
+        balance, err = node.TRC20ContractBalance(addr, token)
+        if err != nil {
+            return fmt.Errorf("failed to fetch TRC-20 balance: %w", ClassifyError(err))
+        }
+        return nil
+    })
+    if err != nil {
+        return units.Amount{}, err
+    }
+
+    return units.NewAmount(balance), nil
+}
+
+// SendTokenTransaction signs and broadcasts a TRC-20 transfer
+func (t *TronAdapter) SendTokenTransaction(ctx context.Context, token, from, to string, amount units.Amount, privateKey string, fee *FeeEstimate) (*Transaction, error) {
+    if _, err := address.Base58ToAddress(from); err != nil {
+        return nil, fmt.Errorf("invalid from address: %w", err)
+    }
+    if err := validateTronAddress(to); err != nil {
+        return nil, fmt.Errorf("invalid to address: %w", err)
+    }
+
+    key, err := parseTronKey(privateKey, from)
+    if err != nil {
+        return nil, err
+    }
+
+    meta, err := t.GetTokenMetadata(ctx, token)
+    if err != nil {
+        return nil, err
+    }
+
+    if fee == nil {
+        estimates, err := t.EstimateTokenFee(ctx, token, from, to, amount)
+        if err != nil {
+            return nil, err
+        }
+        standard, err := estimates.Tier(FeeStandard)
+        if err != nil {
+            return nil, err
         }
+        fee = &standard
+    }
+
+    var txExt *api.TransactionExtention
+    err = t.call(ctx, func(node *client.GrpcClient) error {
+        var err error
+        txExt, err = node.TRC20Send(from, to, token, amount.BigInt(), t.feeLimit)
+        if err != nil {
+            return fmt.Errorf("failed to build TRC-20 transfer: %w", err)
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    hash, err := t.signAndBroadcast(ctx, txExt, key)
+    if err != nil {
+        return nil, err
     }
 
-    // In a real implementation, this would fetch transaction details from the Tron network
-    // For now, we'll return a mock transaction
     return &Transaction{
         Hash:          hash,
-        From:          "TQaLxh7fFz5C82PjH5zDc9qM5n3P2q7W9y8V4n6R3t",
-        To:            "TQbMxh7fFz5C82PjH5zDc9qM5n3P2q7W9y8V4n6R3u",
-        Amount:        1.5,
-        Fee:           0.1,
-        Confirmations: 12,
-        Status:        "confirmed",
+        From:          from,
+        To:            to,
+        Amount:        amount,
+        Decimals:      meta.Decimals,
+        Fee:           fee.Fee,
+        FeeTier:       fee.Tier,
+        Confirmations: 0,
+        Status:        "pending",
         Timestamp:     time.Now().Unix(),
+        Token:         meta.Contract,
+        TokenSymbol:   meta.Symbol,
     }, nil
 }
 
-// EstimateFee estimates the transaction fee
-func (t *TronAdapter) EstimateFee(ctx context.Context, from, to string, amount float64) (float64, error) {
-    // Tron has a relatively fixed fee model, so we'll return the standard fee
-    return 0.1, nil
+// GetTokenMetadata retrieves the symbol and decimals of a TRC-20 token
+func (t *TronAdapter) GetTokenMetadata(ctx context.Context, token string) (*TokenMetadata, error) {
+    if _, err := address.Base58ToAddress(token); err != nil {
+        return nil, fmt.Errorf("invalid token contract address: %w", err)
+    }
+
+    if meta, ok := t.tokens.get(token); ok {
+        return meta, nil
+    }
+
+    var decimals *big.Int
+    var symbol string
+    err := t.call(ctx, func(node *client.GrpcClient) error {
+        var err error
+        decimals, err = node.TRC20GetDecimals(token)
+        if err != nil {
+            return fmt.Errorf("failed to fetch token decimals: %w", ClassifyError(err))
+        }
+
+        symbol, err = node.TRC20GetSymbol(token)
+        if err != nil {
+            return fmt.Errorf("failed to fetch token symbol: %w", ClassifyError(err))
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    meta := &TokenMetadata{
+        Contract: token,
+        Symbol:   symbol,
+        Decimals: uint8(decimals.Uint64()),
+    }
+    t.tokens.put(token, meta)
+
+    return meta, nil
+}
+
+// signAndBroadcast signs a transaction built by a node and broadcasts it, returning its ID. The
+// transaction is signed once: failing over only resends it as it was signed.
+func (t *TronAdapter) signAndBroadcast(ctx context.Context, txExt *api.TransactionExtention, key *ecdsa.PrivateKey) (string, error) {
+    if txExt == nil || txExt.Transaction == nil {
+        return "", fmt.Errorf("node returned an empty transaction")
+    }
+    if txExt.Result != nil && !txExt.Result.Result {
+        return "", ClassifyError(fmt.Errorf("node rejected transaction: %s", string(txExt.Result.Message)))
+    }
+
+    rawData, err := proto.Marshal(txExt.Transaction.GetRawData())
+    if err != nil {
+        return "", fmt.Errorf("failed to encode transaction: %w", err)
+    }
+
+    // The transaction ID is the SHA-256 of the raw data, and that is what gets signed
+    hash := sha256.Sum256(rawData)
+    signature, err := crypto.Sign(hash[:], key)
+    if err != nil {
+        return "", fmt.Errorf("failed to sign transaction: %w", err)
+    }
+    txExt.Transaction.Signature = append(txExt.Transaction.Signature, signature)
+
+    signed, err := proto.Marshal(txExt.Transaction)
+    if err != nil {
+        return "", fmt.Errorf("failed to encode signed transaction: %w", err)
+    }
+    id := hex.EncodeToString(hash[:])
+    if err := t.broadcast(ctx, id, hex.EncodeToString(signed), txExt.Transaction); err != nil {
+        return "", err
+    }
+
+    return id, nil
+}
+
+// broadcast broadcasts a signed transaction, to the next node when one fails
+func (t *TronAdapter) broadcast(ctx context.Context, id, raw string, tx *core.Transaction) error {
+    return t.call(ctx, func(node *client.GrpcClient) error {
+        return broadcastTron(node, id, raw, tx)
+    })
+}
+
+// broadcastTron broadcasts a signed transaction to one node. A node that answers has refused it
+// or taken it; any other failure leaves that unknown and is a BroadcastError.
+func broadcastTron(node *client.GrpcClient, id, raw string, tx *core.Transaction) error {
+    result, err := node.Broadcast(tx)
+    if result == nil {
+        if err == nil {
+            err = fmt.Errorf("node returned no broadcast result")
+        }
+        return &BroadcastError{Hash: id, Raw: raw, Err: fmt.Errorf("failed to broadcast transaction: %w", ClassifyError(err))}
+    }
+    if err == nil {
+        return nil
+    }
+    switch result.Code {
+    case api.Return_DUP_TRANSACTION_ERROR:
+        return nil
+    case api.Return_SERVER_BUSY, api.Return_NO_CONNECTION, api.Return_NOT_ENOUGH_EFFECTIVE_CONNECTION, api.Return_OTHER_ERROR:
+        // The node could not relay it, but may still hold it
+        return &BroadcastError{Hash: id, Raw: raw, Err: ClassifyError(fmt.Errorf("broadcast failed with %s: %s", result.Code.String(), string(result.Message)))}
+    }
+    return ClassifyError(fmt.Errorf("broadcast rejected with %s: %s", result.Code.String(), string(result.Message)))
+}
+
+// RebroadcastTransaction sends a transaction signed by this adapter again, as the hex its
+// BroadcastError carried
+func (t *TronAdapter) RebroadcastTransaction(ctx context.Context, raw string) error {
+    data, err := hex.DecodeString(raw)
+    if err != nil {
+        return fmt.Errorf("invalid raw transaction: %w", err)
+    }
+    tx := &core.Transaction{}
+    if err := proto.Unmarshal(data, tx); err != nil {
+        return fmt.Errorf("invalid raw transaction: %w", err)
+    }
+    rawData, err := proto.Marshal(tx.GetRawData())
+    if err != nil {
+        return fmt.Errorf("failed to encode transaction: %w", err)
+    }
+    hash := sha256.Sum256(rawData)
+
+    return t.broadcast(ctx, hex.EncodeToString(hash[:]), raw, tx)
+}
+
+// parseTronKey decodes a hex private key and checks that it controls the from address
+func parseTronKey(privateKey, from string) (*ecdsa.PrivateKey, error) {
+    key, err := crypto.HexToECDSA(strings.TrimPrefix(privateKey, "0x"))
+    if err != nil {
+        return nil, fmt.Errorf("invalid private key: %w", err)
+    }
+
+    if address.PubkeyToAddress(key.PublicKey).String() != from {
+        return nil, fmt.Errorf("private key does not match from address %s", from)
+    }
+
+    return key, nil
+}
+
+// GetTransaction retrieves transaction details, with confirmations counted from the latest block.
+// Amounts and addresses are filled in for TRX transfers.
+func (t *TronAdapter) GetTransaction(ctx context.Context, hash string) (*Transaction, error) {
+    var tx *core.Transaction
+    err := t.call(ctx, func(node *client.GrpcClient) error {
+        var err error
+        tx, err = node.GetTransactionByID(hash)
+        if err != nil {
+            return fmt.Errorf("failed to fetch transaction %s: %w", hash, ClassifyError(err))
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    result := &Transaction{
+        Hash:     hash,
+        Decimals: trxDecimals,
+        Status:   "pending",
+    }
+    if contracts := tx.GetRawData().GetContract(); len(contracts) > 0 && contracts[0].Type == core.Transaction_Contract_TransferContract {
+        var transfer core.TransferContract
+        if err := contracts[0].GetParameter().UnmarshalTo(&transfer); err == nil {
+            result.From = address.Address(transfer.OwnerAddress).String()
+            result.To = address.Address(transfer.ToAddress).String()
+            result.Amount = units.FromInt64(transfer.Amount)
+        }
+    }
+
+    // Transactions without an info record, or with one of block zero, have not been included in
+    // a block yet; any other failure to read it is an error, not a pending transaction
+    var info *core.TransactionInfo
+    err = t.call(ctx, func(node *client.GrpcClient) error {
+        var err error
+        info, err = node.GetTransactionInfoByID(hash)
+        if err != nil {
+            return fmt.Errorf("failed to fetch transaction info %s: %w", hash, ClassifyError(err))
+        }
+        return nil
+    })
+    if errors.Is(err, ErrNotFound) {
+        return result, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    if info.GetBlockNumber() == 0 {
+        return result, nil
+    }
+
+    head, err := t.HeadBlock(ctx)
+    if err != nil {
+        return nil, err
+    }
+
+    result.Confirmations = 1
+    if number := uint64(info.BlockNumber); head > number {
+        result.Confirmations += int(head - number)
+    }
+    result.Fee = units.FromInt64(info.Fee)
+    result.Timestamp = info.BlockTimeStamp / 1000
+    result.Status = "confirmed"
+    if info.Result == core.TransactionInfo_FAILED {
+        result.Status = "failed"
+    }
+
+    return result, nil
+}
+
+// EstimateFee estimates the TRX burnt by a TRX transfer for the bandwidth the sender's free and
+// staked bandwidth do not cover, and for creating the recipient's account when it does not exist
+func (t *TronAdapter) EstimateFee(ctx context.Context, from, to string, amount units.Amount) (FeeEstimates, error) {
+    var burnt int64
+    err := t.call(ctx, func(node *client.GrpcClient) error {
+        bandwidthPrice, _, err := t.resourcePrices(ctx, node)
+        if err != nil {
+            return err
+        }
+
+        resources, err := t.availableResources(node, from)
+        if err != nil {
+            return err
+        }
+        burnt = bandwidthFee(trxTransferBandwidth, resources, bandwidthPrice)
+
+        activated, err := t.accountExists(node, to)
+        if err != nil {
+            return err
+        }
+        if !activated {
+            burnt += tronActivationFee
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return tronFeeEstimates(burnt), nil
+}
+
+// EstimateTokenFee estimates the TRX burnt by a TRC-20 transfer, covering both
+// the bandwidth and the energy the sender has not staked for
+func (t *TronAdapter) EstimateTokenFee(ctx context.Context, token, from, to string, amount units.Amount) (FeeEstimates, error) {
+    var burnt int64
+    err := t.call(ctx, func(node *client.GrpcClient) error {
+        // Dry-run the transfer to find out how much energy it needs
+        params := fmt.Sprintf(`[{"address":"%s"},{"uint256":"%s"}]`, to, amount.String())
+        simulated, err := node.TriggerConstantContract(from, token, "transfer(address,uint256)", params)
+        if err != nil {
+            return fmt.Errorf("failed to estimate energy: %w", ClassifyError(err))
+        }
+
+        bandwidthPrice, energyPrice, err := t.resourcePrices(ctx, node)
+        if err != nil {
+            return err
+        }
+
+        resources, err := t.availableResources(node, from)
+        if err != nil {
+            return err
+        }
+
+        burnt = bandwidthFee(trc20TransferBandwidth, resources, bandwidthPrice) +
+            resourceShortfall(simulated.EnergyUsed, resources.energy)*energyPrice
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return tronFeeEstimates(burnt), nil
+}
+
+// tronFeeEstimates offers the same cost at every tier: Tron has no fee market, so paying
+// more does not speed up inclusion and every transaction lands in the next block
+func tronFeeEstimates(burnt int64) FeeEstimates {
+    estimates := make(FeeEstimates, 0, len(feeTiers))
+    for _, tier := range feeTiers {
+        estimates = append(estimates, FeeEstimate{
+            Tier:                 tier,
+            Fee:                  units.FromInt64(burnt),
+            ExpectedConfirmation: tronBlockTime,
+        })
+    }
+    return estimates
+}
+
+// tronResources is what an address can still spend today of each resource pool
+type tronResources struct {
+    freeBandwidth   int64 // the daily bandwidth every account gets
+    stakedBandwidth int64 // bandwidth obtained by staking TRX
+    energy          int64
+}
+
+// availableResources returns the bandwidth and energy an address can still spend today
+func (t *TronAdapter) availableResources(node *client.GrpcClient, addr string) (tronResources, error) {
+    resources, err := node.GetAccountResource(addr)
+    if err != nil {
+        return tronResources{}, fmt.Errorf("failed to fetch account resources: %w", ClassifyError(err))
+    }
+
+    return tronResources{
+        freeBandwidth:   resources.FreeNetLimit - resources.FreeNetUsed,
+        stakedBandwidth: resources.NetLimit - resources.NetUsed,
+        energy:          resources.EnergyLimit - resources.EnergyUsed,
+    }, nil
+}
+
+// accountExists reports whether an address has an account, which it gets with its first TRX
+func (t *TronAdapter) accountExists(node *client.GrpcClient, addr string) (bool, error) {
+    if _, err := node.GetAccount(addr); err != nil {
+        if isTronAccountNotFound(err) {
+            return false, nil
+        }
+        return false, fmt.Errorf("failed to fetch account info: %w", ClassifyError(err))
+    }
+    return true, nil
+}
+
+// isTronAccountNotFound reports whether a node answered that an address has no account yet
+func isTronAccountNotFound(err error) bool {
+    return strings.Contains(strings.ToLower(err.Error()), "account not found")
+}
+
+// resourcePrices returns the current SUN price of one bandwidth byte and one unit of energy.
+// Prices the chain parameters leave out are the defaults.
+func (t *TronAdapter) resourcePrices(ctx context.Context, node *client.GrpcClient) (int64, int64, error) {
+    bandwidthPrice, energyPrice := int64(defaultTronBandwidthPrice), int64(defaultTronEnergyPrice)
+
+    params, err := node.Client.GetChainParameters(ctx, new(api.EmptyMessage))
+    if err != nil {
+        return 0, 0, fmt.Errorf("failed to fetch chain parameters: %w", ClassifyError(err))
+    }
+
+    for _, param := range params.GetChainParameter() {
+        switch param.GetKey() {
+        case "getTransactionFee":
+            bandwidthPrice = param.GetValue()
+        case "getEnergyFee":
+            energyPrice = param.GetValue()
+        }
+    }
+
+    return bandwidthPrice, energyPrice, nil
+}
+
+// bandwidthFee returns the SUN burnt for a transaction's bandwidth. Its whole size comes out of
+// staked bandwidth, or else out of free bandwidth: pools are never combined nor partly used, so
+// when neither covers the whole size every byte is paid for in TRX.
+func bandwidthFee(size int64, resources tronResources, price int64) int64 {
+    if resources.stakedBandwidth >= size || resources.freeBandwidth >= size {
+        return 0
+    }
+    return size * price
+}
+
+// resourceShortfall returns how much energy must be paid for in TRX: unlike bandwidth, the
+// energy an address has covers part of what a call needs
+func resourceShortfall(needed, available int64) int64 {
+    return max(0, needed-max(0, available))
 }
 
 // ConnectToTestnet connects to a Tron testnet
@@ -221,8 +748,10 @@ func (t *TronAdapter) ConnectToTestnet(network string) error {
         return fmt.Errorf("unsupported testnet: %s", network)
     }
     
-    t.client = client.NewGrpcClient(grpcURL)
-    t.grpcURL = grpcURL
+    for _, e := range t.endpoints.endpoints {
+        e.client.stop()
+    }
+    t.endpoints = newTronEndpointPool([]string{grpcURL})
     
     return nil
 }
\ No newline at end of file
@@ -0,0 +1,214 @@
+package blockchain
+
+import (
+    "bytes"
+    "context"
+    "encoding/hex"
+    "fmt"
+    "math/big"
+
+    "github.com/fbsobreira/gotron-sdk/pkg/address"
+    "github.com/fbsobreira/gotron-sdk/pkg/client"
+    "github.com/fbsobreira/gotron-sdk/pkg/proto/api"
+    "github.com/fbsobreira/gotron-sdk/pkg/proto/core"
+)
+
+// tronBlockPage is the most blocks a node returns for one block range request
+const tronBlockPage = 100
+
+// TronBlock is a Tron block with the TRX and TRC-20 transfers of its successful transactions
+type TronBlock struct {
+    Number     uint64
+    Hash       string
+    ParentHash string
+    Time       int64
+    Transfers  []TronTransfer
+}
+
+// TronTransfer is a TRX transfer, or a TRC-20 Transfer event
+type TronTransfer struct {
+    TxID   string
+    Index  uint   // index of the TRX transfer contract in its transaction, or of the Transfer event in its transaction's logs
+    From   string
+    To     string
+    Token  string // TRC-20 contract, empty for TRX
+    Amount *big.Int
+}
+
+// HeadBlock returns the number of the latest block
+func (t *TronAdapter) HeadBlock(ctx context.Context) (uint64, error) {
+    var head uint64
+    err := t.call(ctx, func(node *client.GrpcClient) error {
+        block, err := node.GetNowBlock()
+        if err != nil {
+            return fmt.Errorf("failed to fetch latest block: %w", ClassifyError(err))
+        }
+        head = uint64(block.GetBlockHeader().GetRawData().GetNumber())
+        return nil
+    })
+    if err != nil {
+        return 0, err
+    }
+    return head, nil
+}
+
+// GetBlocks returns the blocks from one number to another, inclusive, in order
+func (t *TronAdapter) GetBlocks(ctx context.Context, from, to uint64) ([]*TronBlock, error) {
+    var blocks []*TronBlock
+    for start := from; start <= to; start += tronBlockPage {
+        // The end of the range is exclusive
+        end := start + tronBlockPage
+        if end > to+1 {
+            end = to + 1
+        }
+
+        var list *api.BlockListExtention
+        err := t.call(ctx, func(node *client.GrpcClient) error {
+            var err error
+            list, err = node.GetBlockByLimitNext(int64(start), int64(end))
+            if err != nil {
+                return fmt.Errorf("failed to fetch blocks %d to %d: %w", start, end-1, ClassifyError(err))
+            }
+            return nil
+        })
+        if err != nil {
+            return nil, err
+        }
+        for _, block := range list.GetBlock() {
+            result, calls := tronBlock(block)
+            if calls {
+                // Token transfers are read from the events of the block, which catch transfers made
+                // by other contracts and leave out calls that did not move anything
+                transfers, err := t.trc20Transfers(ctx, result.Number)
+                if err != nil {
+                    return nil, err
+                }
+                result.Transfers = append(result.Transfers, transfers...)
+            }
+            blocks = append(blocks, result)
+        }
+    }
+
+    return blocks, nil
+}
+
+// BlockHash returns the ID of the block at a number
+func (t *TronAdapter) BlockHash(ctx context.Context, number uint64) (string, error) {
+    var hash string
+    err := t.call(ctx, func(node *client.GrpcClient) error {
+        block, err := node.GetBlockByNum(int64(number))
+        if err != nil {
+            return fmt.Errorf("failed to fetch block %d: %w", number, ClassifyError(err))
+        }
+        if len(block.GetBlockid()) == 0 {
+            return fmt.Errorf("block %d: %w", number, ErrNotFound)
+        }
+        hash = hex.EncodeToString(block.GetBlockid())
+        return nil
+    })
+    if err != nil {
+        return "", err
+    }
+    return hash, nil
+}
+
+// tronBlock collects the TRX transfers of a block's successful transactions, and reports whether
+// any of them called a contract, which may have transferred tokens
+func tronBlock(block *api.BlockExtention) (*TronBlock, bool) {
+    raw := block.GetBlockHeader().GetRawData()
+    result := &TronBlock{
+        Number:     uint64(raw.GetNumber()),
+        Hash:       hex.EncodeToString(block.GetBlockid()),
+        ParentHash: hex.EncodeToString(raw.GetParentHash()),
+        Time:       raw.GetTimestamp() / 1000,
+    }
+
+    calls := false
+    for _, ext := range block.GetTransactions() {
+        tx := ext.GetTransaction()
+        if ret := tx.GetRet(); len(ret) > 0 {
+            // Failed calls, such as a token transfer that ran out of energy, move nothing
+            if status := ret[0].GetContractRet(); status != core.Transaction_Result_DEFAULT && status != core.Transaction_Result_SUCCESS {
+                continue
+            }
+        }
+        txID := hex.EncodeToString(ext.GetTxid())
+
+        for i, contract := range tx.GetRawData().GetContract() {
+            switch contract.GetType() {
+            case core.Transaction_Contract_TransferContract:
+                var transfer core.TransferContract
+                if err := contract.GetParameter().UnmarshalTo(&transfer); err != nil || transfer.Amount <= 0 {
+                    continue
+                }
+                result.Transfers = append(result.Transfers, TronTransfer{
+                    TxID:   txID,
+                    Index:  uint(i),
+                    From:   address.Address(transfer.OwnerAddress).String(),
+                    To:     address.Address(transfer.ToAddress).String(),
+                    Amount: big.NewInt(transfer.Amount),
+                })
+            case core.Transaction_Contract_TriggerSmartContract:
+                calls = true
+            }
+        }
+    }
+
+    return result, calls
+}
+
+// trc20Transfers reads the TRC-20 Transfer events of a block's successful transactions from their
+// transaction infos
+func (t *TronAdapter) trc20Transfers(ctx context.Context, number uint64) ([]TronTransfer, error) {
+    var infos *api.TransactionInfoList
+    err := t.call(ctx, func(node *client.GrpcClient) error {
+        var err error
+        infos, err = node.GetBlockInfoByNum(int64(number))
+        if err != nil {
+            return fmt.Errorf("failed to fetch transaction infos of block %d: %w", number, ClassifyError(err))
+        }
+        return nil
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    var transfers []TronTransfer
+    for _, info := range infos.GetTransactionInfo() {
+        // The events of a reverted call were rolled back with it
+        if info.GetResult() == core.TransactionInfo_FAILED {
+            continue
+        }
+        txID := hex.EncodeToString(info.GetId())
+
+        for i, event := range info.GetLog() {
+            topics := event.GetTopics()
+            if len(topics) != 3 || !bytes.Equal(topics[0], ERC20TransferTopic.Bytes()) || len(event.GetData()) != 32 {
+                continue
+            }
+            amount := new(big.Int).SetBytes(event.GetData())
+            if amount.Sign() <= 0 {
+                continue
+            }
+            transfers = append(transfers, TronTransfer{
+                TxID:   txID,
+                Index:  uint(i),
+                From:   tronAccount(topics[1]),
+                To:     tronAccount(topics[2]),
+                Token:  tronAccount(event.GetAddress()),
+                Amount: amount,
+            })
+        }
+    }
+
+    return transfers, nil
+}
+
+// tronAccount turns an account as events carry it, 20 bytes or a 32-byte word ending in them,
+// into a Tron address, which prefixes the account with 0x41
+func tronAccount(account []byte) string {
+    if len(account) > 20 {
+        account = account[len(account)-20:]
+    }
+    return address.Address(append([]byte{address.TronBytePrefix}, account...)).String()
+}
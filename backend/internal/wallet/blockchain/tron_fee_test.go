@@ -0,0 +1,24 @@
+package blockchain
+
+import "testing"
+
+func TestBandwidthFee(t *testing.T) {
+    const price = 1000
+
+    tests := []struct {
+        name      string
+        resources tronResources
+        want      int64
+    }{
+        {"free bandwidth covers it", tronResources{freeBandwidth: 600}, 0},
+        {"staked bandwidth covers it", tronResources{freeBandwidth: 100, stakedBandwidth: 300}, 0},
+        {"pools are not combined", tronResources{freeBandwidth: 200, stakedBandwidth: 200}, trxTransferBandwidth * price},
+        {"a partly covered size burns every byte", tronResources{freeBandwidth: trxTransferBandwidth - 1}, trxTransferBandwidth * price},
+        {"no bandwidth left", tronResources{}, trxTransferBandwidth * price},
+    }
+    for _, tt := range tests {
+        if got := bandwidthFee(trxTransferBandwidth, tt.resources, price); got != tt.want {
+            t.Errorf("%s: bandwidthFee() = %d, want %d", tt.name, got, tt.want)
+        }
+    }
+}
@@ -6,6 +6,8 @@ import (
     "fmt"
     "math/big"
     "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
 )
 
 // BitGoProvider implements the Provider interface for BitGo
@@ -47,7 +49,7 @@ func (b *BitGoProvider) CreateWallet(ctx context.Context, chain string) (*Wallet
         ID:       walletID,
         Address:  address,
         Chain:    chain,
-        Balance:  0,
+        Balance:  units.Amount{},
         IsActive: true,
     }, nil
 }
@@ -65,7 +67,7 @@ func (b *BitGoProvider) GetWallet(ctx context.Context, walletID string) (*Wallet
         ID:       walletID,
         Address:  address,
         Chain:    "ethereum", // Would be fetched from BitGo in real implementation
-        Balance:  8.25,       // Would be fetched from BitGo in real implementation
+        Balance:  mockAmount("8.25"), // Would be fetched from BitGo in real implementation
         IsActive: true,
     }, nil
 }
@@ -79,22 +81,22 @@ func (b *BitGoProvider) GetWalletByAddress(ctx context.Context, address string)
         ID:       fmt.Sprintf("bg-wallet-%d", time.Now().UnixNano()),
         Address:  address,
         Chain:    "ethereum", // Would be fetched from BitGo in real implementation
-        Balance:  3.4,        // Would be fetched from BitGo in real implementation
+        Balance:  mockAmount("3.4"), // Would be fetched from BitGo in real implementation
         IsActive: true,
     }, nil
 }
 
 // GetBalance retrieves the balance of a wallet
-func (b *BitGoProvider) GetBalance(ctx context.Context, walletID string) (float64, error) {
+func (b *BitGoProvider) GetBalance(ctx context.Context, walletID string) (units.Amount, error) {
     // In a real implementation, this would make an API call to BitGo
     // For demonstration, we'll return a random balance
     
     balance, _ := rand.Int(rand.Reader, big.NewInt(100000000))
-    return float64(balance.Int64()) / 1000000, nil
+    return units.NewAmount(balance), nil
 }
 
 // SendTransaction sends a transaction from a custodial wallet
-func (b *BitGoProvider) SendTransaction(ctx context.Context, walletID, to string, amount float64) (*Transaction, error) {
+func (b *BitGoProvider) SendTransaction(ctx context.Context, walletID, to string, amount units.Amount) (*Transaction, error) {
     // In a real implementation, this would make an API call to BitGo
     // For demonstration, we'll return a mock transaction
     
@@ -118,7 +120,7 @@ func (b *BitGoProvider) SendTransaction(ctx context.Context, walletID, to string
         Chain:         "ethereum", // Would be determined by wallet in real implementation
         Status:        "pending",
         Confirmations: 0,
-        Fee:           0.00021,
+        Fee:           mockAmount("0.00021"),
         CreatedAt:     time.Now().Unix(),
     }, nil
 }
@@ -145,11 +147,11 @@ func (b *BitGoProvider) GetTransaction(ctx context.Context, txID string) (*Trans
         TxHash:        txHash,
         FromAddress:   from,
         ToAddress:     to,
-        Amount:        1.8,
+        Amount:        mockAmount("1.8"),
         Chain:         "ethereum",
         Status:        "confirmed",
         Confirmations: 8,
-        Fee:           0.00021,
+        Fee:           mockAmount("0.00021"),
         CreatedAt:     time.Now().Unix() - 7200, // 2 hours ago
     }, nil
 }
@@ -179,11 +181,11 @@ func (b *BitGoProvider) ListTransactions(ctx context.Context, walletID string, l
             TxHash:        txHash,
             FromAddress:   from,
             ToAddress:     to,
-            Amount:        float64(i+1) * 0.75,
+            Amount:        mockAmount("0.75").Mul(uint64(i + 1)),
             Chain:         "ethereum",
             Status:        "confirmed",
             Confirmations: 8,
-            Fee:           0.00021,
+            Fee:           mockAmount("0.00021"),
             CreatedAt:     time.Now().Unix() - int64(i*7200), // i*2 hours ago
         }
         
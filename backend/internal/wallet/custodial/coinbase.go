@@ -6,6 +6,8 @@ import (
     "fmt"
     "math/big"
     "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
 )
 
 // CoinbaseProvider implements the Provider interface for Coinbase Custody
@@ -49,7 +51,7 @@ func (c *CoinbaseProvider) CreateWallet(ctx context.Context, chain string) (*Wal
         ID:       walletID,
         Address:  address,
         Chain:    chain,
-        Balance:  0,
+        Balance:  units.Amount{},
         IsActive: true,
     }, nil
 }
@@ -67,7 +69,7 @@ func (c *CoinbaseProvider) GetWallet(ctx context.Context, walletID string) (*Wal
         ID:       walletID,
         Address:  address,
         Chain:    "ethereum", // Would be fetched from Coinbase in real implementation
-        Balance:  12.75,      // Would be fetched from Coinbase in real implementation
+        Balance:  mockAmount("12.75"), // Would be fetched from Coinbase in real implementation
         IsActive: true,
     }, nil
 }
@@ -81,22 +83,22 @@ func (c *CoinbaseProvider) GetWalletByAddress(ctx context.Context, address strin
         ID:       fmt.Sprintf("cb-wallet-%d", time.Now().UnixNano()),
         Address:  address,
         Chain:    "ethereum", // Would be fetched from Coinbase in real implementation
-        Balance:  6.9,        // Would be fetched from Coinbase in real implementation
+        Balance:  mockAmount("6.9"), // Would be fetched from Coinbase in real implementation
         IsActive: true,
     }, nil
 }
 
 // GetBalance retrieves the balance of a wallet
-func (c *CoinbaseProvider) GetBalance(ctx context.Context, walletID string) (float64, error) {
+func (c *CoinbaseProvider) GetBalance(ctx context.Context, walletID string) (units.Amount, error) {
     // In a real implementation, this would make an API call to Coinbase Custody
     // For demonstration, we'll return a random balance
     
     balance, _ := rand.Int(rand.Reader, big.NewInt(100000000))
-    return float64(balance.Int64()) / 1000000, nil
+    return units.NewAmount(balance), nil
 }
 
 // SendTransaction sends a transaction from a custodial wallet
-func (c *CoinbaseProvider) SendTransaction(ctx context.Context, walletID, to string, amount float64) (*Transaction, error) {
+func (c *CoinbaseProvider) SendTransaction(ctx context.Context, walletID, to string, amount units.Amount) (*Transaction, error) {
     // In a real implementation, this would make an API call to Coinbase Custody
     // For demonstration, we'll return a mock transaction
     
@@ -120,7 +122,7 @@ func (c *CoinbaseProvider) SendTransaction(ctx context.Context, walletID, to str
         Chain:         "ethereum", // Would be determined by wallet in real implementation
         Status:        "pending",
         Confirmations: 0,
-        Fee:           0.00021,
+        Fee:           mockAmount("0.00021"),
         CreatedAt:     time.Now().Unix(),
     }, nil
 }
@@ -147,11 +149,11 @@ func (c *CoinbaseProvider) GetTransaction(ctx context.Context, txID string) (*Tr
         TxHash:        txHash,
         FromAddress:   from,
         ToAddress:     to,
-        Amount:        3.2,
+        Amount:        mockAmount("3.2"),
         Chain:         "ethereum",
         Status:        "confirmed",
         Confirmations: 15,
-        Fee:           0.00021,
+        Fee:           mockAmount("0.00021"),
         CreatedAt:     time.Now().Unix() - 5400, // 1.5 hours ago
     }, nil
 }
@@ -181,11 +183,11 @@ func (c *CoinbaseProvider) ListTransactions(ctx context.Context, walletID string
             TxHash:        txHash,
             FromAddress:   from,
             ToAddress:     to,
-            Amount:        float64(i+1) * 1.25,
+            Amount:        mockAmount("1.25").Mul(uint64(i + 1)),
             Chain:         "ethereum",
             Status:        "confirmed",
             Confirmations: 15,
-            Fee:           0.00021,
+            Fee:           mockAmount("0.00021"),
             CreatedAt:     time.Now().Unix() - int64(i*5400), // i*1.5 hours ago
         }
         
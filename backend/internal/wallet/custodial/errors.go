@@ -0,0 +1,56 @@
+package custodial
+
+import (
+    "context"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+)
+
+// classifiedProvider maps every error of a provider onto the blockchain package's sentinel
+// errors, so callers branch on custodial failures the same way as on adapter failures
+type classifiedProvider struct {
+    Provider
+}
+
+// CreateWallet creates a new custodial wallet
+func (p classifiedProvider) CreateWallet(ctx context.Context, chain string) (*Wallet, error) {
+    w, err := p.Provider.CreateWallet(ctx, chain)
+    return w, blockchain.ClassifyError(err)
+}
+
+// GetWallet retrieves wallet information
+func (p classifiedProvider) GetWallet(ctx context.Context, walletID string) (*Wallet, error) {
+    w, err := p.Provider.GetWallet(ctx, walletID)
+    return w, blockchain.ClassifyError(err)
+}
+
+// GetWalletByAddress retrieves wallet information by address
+func (p classifiedProvider) GetWalletByAddress(ctx context.Context, address string) (*Wallet, error) {
+    w, err := p.Provider.GetWalletByAddress(ctx, address)
+    return w, blockchain.ClassifyError(err)
+}
+
+// GetBalance retrieves the balance of a wallet in base units
+func (p classifiedProvider) GetBalance(ctx context.Context, walletID string) (units.Amount, error) {
+    balance, err := p.Provider.GetBalance(ctx, walletID)
+    return balance, blockchain.ClassifyError(err)
+}
+
+// SendTransaction sends an amount in base units from a custodial wallet
+func (p classifiedProvider) SendTransaction(ctx context.Context, walletID, to string, amount units.Amount) (*Transaction, error) {
+    tx, err := p.Provider.SendTransaction(ctx, walletID, to, amount)
+    return tx, blockchain.ClassifyError(err)
+}
+
+// GetTransaction retrieves transaction details
+func (p classifiedProvider) GetTransaction(ctx context.Context, txID string) (*Transaction, error) {
+    tx, err := p.Provider.GetTransaction(ctx, txID)
+    return tx, blockchain.ClassifyError(err)
+}
+
+// ListTransactions lists transactions for a wallet
+func (p classifiedProvider) ListTransactions(ctx context.Context, walletID string, limit, offset int) ([]Transaction, error) {
+    txs, err := p.Provider.ListTransactions(ctx, walletID, limit, offset)
+    return txs, blockchain.ClassifyError(err)
+}
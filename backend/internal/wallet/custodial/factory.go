@@ -14,23 +14,24 @@ func NewProviderFactory(config map[string]interface{}) *ProviderFactory {
     }
 }
 
-// CreateProvider creates a custodial wallet provider for the specified service
+// CreateProvider creates a custodial wallet provider for the specified service. Its errors are
+// mapped onto the blockchain package's sentinel errors.
 func (f *ProviderFactory) CreateProvider(provider string) (Provider, error) {
     switch provider {
     case "fireblocks":
         apiKey, _ := f.config["fireblocks_api_key"].(string)
         secretKey, _ := f.config["fireblocks_secret_key"].(string)
         baseURL, _ := f.config["fireblocks_base_url"].(string)
-        return NewFireblocksProvider(apiKey, secretKey, baseURL), nil
+        return classifiedProvider{NewFireblocksProvider(apiKey, secretKey, baseURL)}, nil
     case "bitgo":
         accessToken, _ := f.config["bitgo_access_token"].(string)
         baseURL, _ := f.config["bitgo_base_url"].(string)
-        return NewBitGoProvider(accessToken, baseURL), nil
+        return classifiedProvider{NewBitGoProvider(accessToken, baseURL)}, nil
     case "coinbase":
         apiKey, _ := f.config["coinbase_api_key"].(string)
         secretKey, _ := f.config["coinbase_secret_key"].(string)
         baseURL, _ := f.config["coinbase_base_url"].(string)
-        return NewCoinbaseProvider(apiKey, secretKey, baseURL), nil
+        return classifiedProvider{NewCoinbaseProvider(apiKey, secretKey, baseURL)}, nil
     default:
         return nil, fmt.Errorf("unsupported custodial wallet provider: %s", provider)
     }
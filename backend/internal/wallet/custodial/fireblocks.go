@@ -6,6 +6,8 @@ import (
     "fmt"
     "math/big"
     "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
 )
 
 // FireblocksProvider implements the Provider interface for Fireblocks
@@ -40,7 +42,7 @@ func (f *FireblocksProvider) CreateWallet(ctx context.Context, chain string) (*W
         ID:       walletID,
         Address:  address,
         Chain:    chain,
-        Balance:  0,
+        Balance:  units.Amount{},
         IsActive: true,
     }, nil
 }
@@ -58,7 +60,7 @@ func (f *FireblocksProvider) GetWallet(ctx context.Context, walletID string) (*W
         ID:       walletID,
         Address:  address,
         Chain:    "ethereum", // Would be fetched from Fireblocks in real implementation
-        Balance:  10.5,       // Would be fetched from Fireblocks in real implementation
+        Balance:  mockAmount("10.5"), // Would be fetched from Fireblocks in real implementation
         IsActive: true,
     }, nil
 }
@@ -72,22 +74,22 @@ func (f *FireblocksProvider) GetWalletByAddress(ctx context.Context, address str
         ID:       fmt.Sprintf("fb-wallet-%d", time.Now().UnixNano()),
         Address:  address,
         Chain:    "ethereum", // Would be fetched from Fireblocks in real implementation
-        Balance:  5.75,       // Would be fetched from Fireblocks in real implementation
+        Balance:  mockAmount("5.75"), // Would be fetched from Fireblocks in real implementation
         IsActive: true,
     }, nil
 }
 
 // GetBalance retrieves the balance of a wallet
-func (f *FireblocksProvider) GetBalance(ctx context.Context, walletID string) (float64, error) {
+func (f *FireblocksProvider) GetBalance(ctx context.Context, walletID string) (units.Amount, error) {
     // In a real implementation, this would make an API call to Fireblocks
     // For demonstration, we'll return a random balance
     
     balance, _ := rand.Int(rand.Reader, big.NewInt(100000000))
-    return float64(balance.Int64()) / 1000000, nil
+    return units.NewAmount(balance), nil
 }
 
 // SendTransaction sends a transaction from a custodial wallet
-func (f *FireblocksProvider) SendTransaction(ctx context.Context, walletID, to string, amount float64) (*Transaction, error) {
+func (f *FireblocksProvider) SendTransaction(ctx context.Context, walletID, to string, amount units.Amount) (*Transaction, error) {
     // In a real implementation, this would make an API call to Fireblocks
     // For demonstration, we'll return a mock transaction
     
@@ -111,7 +113,7 @@ func (f *FireblocksProvider) SendTransaction(ctx context.Context, walletID, to s
         Chain:         "ethereum", // Would be determined by wallet in real implementation
         Status:        "pending",
         Confirmations: 0,
-        Fee:           0.00021,
+        Fee:           mockAmount("0.00021"),
         CreatedAt:     time.Now().Unix(),
     }, nil
 }
@@ -138,11 +140,11 @@ func (f *FireblocksProvider) GetTransaction(ctx context.Context, txID string) (*
         TxHash:        txHash,
         FromAddress:   from,
         ToAddress:     to,
-        Amount:        2.5,
+        Amount:        mockAmount("2.5"),
         Chain:         "ethereum",
         Status:        "confirmed",
         Confirmations: 12,
-        Fee:           0.00021,
+        Fee:           mockAmount("0.00021"),
         CreatedAt:     time.Now().Unix() - 3600, // 1 hour ago
     }, nil
 }
@@ -172,11 +174,11 @@ func (f *FireblocksProvider) ListTransactions(ctx context.Context, walletID stri
             TxHash:        txHash,
             FromAddress:   from,
             ToAddress:     to,
-            Amount:        float64(i+1) * 0.5,
+            Amount:        mockAmount("0.5").Mul(uint64(i + 1)),
             Chain:         "ethereum",
             Status:        "confirmed",
             Confirmations: 12,
-            Fee:           0.00021,
+            Fee:           mockAmount("0.00021"),
             CreatedAt:     time.Now().Unix() - int64(i*3600), // i hours ago
         }
         
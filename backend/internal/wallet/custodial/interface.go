@@ -1,13 +1,17 @@
 package custodial
 
-import "context"
+import (
+    "context"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+)
 
 // Wallet represents a custodial wallet
 type Wallet struct {
     ID       string
     Address  string
     Chain    string
-    Balance  float64
+    Balance  units.Amount // in base units of the chain's native coin
     IsActive bool
 }
 
@@ -18,11 +22,11 @@ type Transaction struct {
     TxHash        string
     FromAddress   string
     ToAddress     string
-    Amount        float64
+    Amount        units.Amount // in base units
     Chain         string
     Status        string
     Confirmations int
-    Fee           float64
+    Fee           units.Amount // in base units of the chain's native coin
     CreatedAt     int64
 }
 
@@ -37,11 +41,11 @@ type Provider interface {
     // GetWalletByAddress retrieves wallet information by address
     GetWalletByAddress(ctx context.Context, address string) (*Wallet, error)
     
-    // GetBalance retrieves the balance of a wallet
-    GetBalance(ctx context.Context, walletID string) (float64, error)
+    // GetBalance retrieves the balance of a wallet in base units
+    GetBalance(ctx context.Context, walletID string) (units.Amount, error)
     
-    // SendTransaction sends a transaction from a custodial wallet
-    SendTransaction(ctx context.Context, walletID, to string, amount float64) (*Transaction, error)
+    // SendTransaction sends an amount in base units from a custodial wallet
+    SendTransaction(ctx context.Context, walletID, to string, amount units.Amount) (*Transaction, error)
     
     // GetTransaction retrieves transaction details
     GetTransaction(ctx context.Context, txID string) (*Transaction, error)
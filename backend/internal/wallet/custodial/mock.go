@@ -0,0 +1,15 @@
+package custodial
+
+import "github.com/blockchain-dapp/backend/internal/pkg/units"
+
+// mockDecimals is the precision of the mock amounts, which are all on Ethereum
+const mockDecimals = 18
+
+// mockAmount converts a display amount used by the mock providers to base units
+func mockAmount(display string) units.Amount {
+    amount, err := units.Parse(display, mockDecimals)
+    if err != nil {
+        panic(err)
+    }
+    return amount
+}
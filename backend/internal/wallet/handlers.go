@@ -1,8 +1,10 @@
 package wallet
 
 import (
+    "errors"
     "strconv"
 
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
     "github.com/gofiber/fiber/v2"
     "gorm.io/gorm"
 )
@@ -51,12 +53,17 @@ func (h *Handler) CreateWallet(c *fiber.Ctx) error {
     }
 
     if err := h.service.CreateWallet(&wallet); err != nil {
+        if status, ok := errorStatus(err); ok {
+            return c.Status(status).JSON(fiber.Map{
+                "error": err.Error(),
+            })
+        }
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "error": "Cannot create wallet",
         })
     }
 
-    return c.Status(fiber.StatusCreated).JSON(wallet)
+    return c.Status(fiber.StatusCreated).JSON(newWalletView(wallet))
 }
 
 // GetWallets retrieves all wallets for a user
@@ -75,7 +82,7 @@ func (h *Handler) GetWallets(c *fiber.Ctx) error {
         })
     }
 
-    return c.JSON(wallets)
+    return c.JSON(newWalletViews(wallets))
 }
 
 // GetWallet retrieves a wallet by ID
@@ -94,7 +101,7 @@ func (h *Handler) GetWallet(c *fiber.Ctx) error {
         })
     }
 
-    return c.JSON(wallet)
+    return c.JSON(newWalletView(*wallet))
 }
 
 // UpdateWallet updates a wallet
@@ -117,12 +124,17 @@ func (h *Handler) UpdateWallet(c *fiber.Ctx) error {
     wallet.ID = uint(id)
 
     if err := h.service.UpdateWallet(&wallet); err != nil {
+        if status, ok := errorStatus(err); ok {
+            return c.Status(status).JSON(fiber.Map{
+                "error": err.Error(),
+            })
+        }
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "error": "Cannot update wallet",
         })
     }
 
-    return c.JSON(wallet)
+    return c.JSON(newWalletView(wallet))
 }
 
 // DeleteWallet deletes a wallet
@@ -152,23 +164,35 @@ func (h *Handler) CreateTransaction(c *fiber.Ctx) error {
         })
     }
 
-    var tx Transaction
-    if err := c.BodyParser(&tx); err != nil {
+    var req transactionRequest
+    if err := c.BodyParser(&req); err != nil {
         return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
             "error": "Cannot parse JSON",
         })
     }
 
+    tx, err := req.transaction()
+    if err != nil {
+        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+            "error": err.Error(),
+        })
+    }
+
     // Set the wallet ID
     tx.WalletID = uint(walletID)
 
     if err := h.service.CreateTransaction(&tx); err != nil {
+        if status, ok := errorStatus(err); ok {
+            return c.Status(status).JSON(fiber.Map{
+                "error": err.Error(),
+            })
+        }
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "error": "Cannot create transaction",
         })
     }
 
-    return c.Status(fiber.StatusCreated).JSON(tx)
+    return c.Status(fiber.StatusCreated).JSON(newTransactionView(tx))
 }
 
 // GetTransactions retrieves all transactions for a wallet
@@ -187,7 +211,7 @@ func (h *Handler) GetTransactions(c *fiber.Ctx) error {
         })
     }
 
-    return c.JSON(transactions)
+    return c.JSON(newTransactionViews(transactions))
 }
 
 // CreateCustodialWallet creates a new custodial wallet
@@ -200,12 +224,17 @@ func (h *Handler) CreateCustodialWallet(c *fiber.Ctx) error {
     }
 
     if err := h.service.CreateCustodialWallet(&wallet); err != nil {
+        if status, ok := errorStatus(err); ok {
+            return c.Status(status).JSON(fiber.Map{
+                "error": err.Error(),
+            })
+        }
         return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
             "error": "Cannot create custodial wallet",
         })
     }
 
-    return c.Status(fiber.StatusCreated).JSON(wallet)
+    return c.Status(fiber.StatusCreated).JSON(newCustodialWalletView(wallet))
 }
 
 // GetCustodialWallets retrieves all custodial wallets for a user
@@ -224,5 +253,25 @@ func (h *Handler) GetCustodialWallets(c *fiber.Ctx) error {
         })
     }
 
-    return c.JSON(wallets)
-}
\ No newline at end of file
+    return c.JSON(newCustodialWalletViews(wallets))
+}
+
+// errorStatus maps errors that can be shown to the user to an HTTP status: validation errors
+// caused by the request, and chain errors the user can act on or retry later. Other errors
+// report false and should be answered with a generic internal error.
+func errorStatus(err error) (int, bool) {
+    switch {
+    case errors.Is(err, ErrUnsupportedChain), errors.Is(err, ErrDepositNotAllowed), errors.Is(err, blockchain.ErrInvalidAddress):
+        return fiber.StatusBadRequest, true
+    case errors.Is(err, blockchain.ErrNotFound):
+        return fiber.StatusNotFound, true
+    case errors.Is(err, blockchain.ErrInsufficientFunds), errors.Is(err, blockchain.ErrReverted):
+        return fiber.StatusUnprocessableEntity, true
+    case errors.Is(err, blockchain.ErrNonceTooLow), errors.Is(err, blockchain.ErrUnderpriced), errors.Is(err, blockchain.ErrTransactionNotPending):
+        return fiber.StatusConflict, true
+    case errors.Is(err, blockchain.ErrChainUnavailable):
+        return fiber.StatusServiceUnavailable, true
+    default:
+        return 0, false
+    }
+}
@@ -0,0 +1,135 @@
+package hdwallet
+
+import (
+    "crypto/hmac"
+    "crypto/sha512"
+    "encoding/binary"
+    "errors"
+    "fmt"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/security"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "github.com/btcsuite/btcd/btcutil/hdkeychain"
+    "github.com/btcsuite/btcd/chaincfg"
+    "github.com/tyler-smith/go-bip39"
+)
+
+// mnemonicEntropyBits is the entropy of generated mnemonics (24 words)
+const mnemonicEntropyBits = 256
+
+// Keyring derives deposit keys from a single master seed
+type Keyring struct {
+    seed []byte
+}
+
+// NewKeyring creates a keyring from a raw BIP-32 seed
+func NewKeyring(seed []byte) (*Keyring, error) {
+    if len(seed) < hdkeychain.MinSeedBytes || len(seed) > hdkeychain.MaxSeedBytes {
+        return nil, fmt.Errorf("seed must be between %d and %d bytes", hdkeychain.MinSeedBytes, hdkeychain.MaxSeedBytes)
+    }
+
+    return &Keyring{seed: append([]byte(nil), seed...)}, nil
+}
+
+// NewKeyringFromMnemonic creates a keyring from a BIP-39 mnemonic and optional passphrase
+func NewKeyringFromMnemonic(mnemonic, passphrase string) (*Keyring, error) {
+    seed, err := bip39.NewSeedWithErrorChecking(mnemonic, passphrase)
+    if err != nil {
+        return nil, fmt.Errorf("invalid mnemonic: %w", err)
+    }
+
+    return NewKeyring(seed)
+}
+
+// NewKeyringFromEncryptedSeed creates a keyring from a master seed encrypted with the KMS
+func NewKeyringFromEncryptedSeed(kms security.KMSClient, ciphertext []byte) (*Keyring, error) {
+    seed, err := kms.Decrypt(ciphertext)
+    if err != nil {
+        return nil, fmt.Errorf("failed to decrypt master seed: %w", err)
+    }
+
+    return NewKeyring(seed)
+}
+
+// GenerateEncryptedSeed creates a new master seed and returns its mnemonic, for offline
+// backup, together with the seed encrypted by the KMS, for the service configuration
+func GenerateEncryptedSeed(kms security.KMSClient) (string, []byte, error) {
+    entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+    if err != nil {
+        return "", nil, fmt.Errorf("failed to generate entropy: %w", err)
+    }
+
+    mnemonic, err := bip39.NewMnemonic(entropy)
+    if err != nil {
+        return "", nil, fmt.Errorf("failed to generate mnemonic: %w", err)
+    }
+
+    ciphertext, err := kms.Encrypt(bip39.NewSeed(mnemonic, ""))
+    if err != nil {
+        return "", nil, fmt.Errorf("failed to encrypt master seed: %w", err)
+    }
+
+    return mnemonic, ciphertext, nil
+}
+
+// DeriveKey derives the raw private key at a path using the curve of the chain family:
+// BIP-32 secp256k1 for Bitcoin, EVM and Tron, SLIP-0010 ed25519 for Solana
+func (k *Keyring) DeriveKey(family blockchain.ChainFamily, path Path) ([]byte, error) {
+    switch family {
+    case blockchain.FamilyBitcoin, blockchain.FamilyEVM, blockchain.FamilyTron:
+        return k.deriveSecp256k1(path)
+    case blockchain.FamilySolana:
+        return k.deriveEd25519(path)
+    default:
+        return nil, fmt.Errorf("no derivation scheme for chain family %s", family)
+    }
+}
+
+// deriveSecp256k1 derives a BIP-32 secp256k1 private key
+func (k *Keyring) deriveSecp256k1(path Path) ([]byte, error) {
+    // The network only affects extended key serialization, not the derived keys
+    key, err := hdkeychain.NewMaster(k.seed, &chaincfg.MainNetParams)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create master key: %w", err)
+    }
+
+    for _, element := range path {
+        key, err = key.Derive(element)
+        if err != nil {
+            return nil, fmt.Errorf("failed to derive %s: %w", path.String(), err)
+        }
+    }
+
+    privKey, err := key.ECPrivKey()
+    if err != nil {
+        return nil, fmt.Errorf("failed to get private key for %s: %w", path.String(), err)
+    }
+
+    return privKey.Serialize(), nil
+}
+
+// deriveEd25519 derives a SLIP-0010 ed25519 private key seed
+func (k *Keyring) deriveEd25519(path Path) ([]byte, error) {
+    mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+    mac.Write(k.seed)
+    sum := mac.Sum(nil)
+    key, chainCode := sum[:32], sum[32:]
+
+    for _, element := range path {
+        if element < HardenedOffset {
+            return nil, errors.New("ed25519 derivation only supports hardened path elements")
+        }
+
+        data := make([]byte, 0, 1+32+4)
+        data = append(data, 0)
+        data = append(data, key...)
+        data = binary.BigEndian.AppendUint32(data, element)
+
+        mac = hmac.New(sha512.New, chainCode)
+        mac.Write(data)
+        sum = mac.Sum(nil)
+        key, chainCode = sum[:32], sum[32:]
+    }
+
+    return key, nil
+}
@@ -0,0 +1,101 @@
+package hdwallet
+
+import (
+    "fmt"
+    "strconv"
+    "strings"
+
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+)
+
+// HardenedOffset is added to a path element to make it a hardened derivation
+const HardenedOffset uint32 = 0x80000000
+
+// BIP-44 coin types (SLIP-0044)
+const (
+    CoinTypeBitcoin        uint32 = 0
+    CoinTypeBitcoinTestnet uint32 = 1
+    CoinTypeEthereum       uint32 = 60
+    CoinTypeTron           uint32 = 195
+    CoinTypeSolana         uint32 = 501
+)
+
+// Path is a BIP-32 derivation path, with hardened elements offset by HardenedOffset
+type Path []uint32
+
+// String formats the path in the usual m/44'/60'/0'/0/0 notation
+func (p Path) String() string {
+    var b strings.Builder
+    b.WriteString("m")
+    for _, element := range p {
+        b.WriteString("/")
+        if element >= HardenedOffset {
+            b.WriteString(strconv.FormatUint(uint64(element-HardenedOffset), 10))
+            b.WriteString("'")
+        } else {
+            b.WriteString(strconv.FormatUint(uint64(element), 10))
+        }
+    }
+    return b.String()
+}
+
+// ParsePath parses a derivation path in m/44'/60'/0'/0/0 notation
+func ParsePath(s string) (Path, error) {
+    parts := strings.Split(strings.TrimSpace(s), "/")
+    if len(parts) == 0 || parts[0] != "m" {
+        return nil, fmt.Errorf("derivation path must start with m: %q", s)
+    }
+
+    path := make(Path, 0, len(parts)-1)
+    for _, part := range parts[1:] {
+        hardened := strings.HasSuffix(part, "'") || strings.HasSuffix(part, "h")
+        part = strings.TrimRight(part, "'h")
+
+        value, err := strconv.ParseUint(part, 10, 32)
+        if err != nil || uint32(value) >= HardenedOffset {
+            return nil, fmt.Errorf("invalid derivation path element %q in %q", part, s)
+        }
+
+        element := uint32(value)
+        if hardened {
+            element += HardenedOffset
+        }
+        path = append(path, element)
+    }
+
+    return path, nil
+}
+
+// DepositPath returns the BIP-44 path of a deposit address. The account level is the
+// user ID and the address index is the user's per-chain derivation index:
+//
+//     secp256k1 chains: m/44'/coin'/user'/0/index
+//     Solana:           m/44'/501'/user'/index'  (ed25519 only supports hardened steps)
+func DepositPath(chain blockchain.ChainConfig, userID uint, index uint32) (Path, error) {
+    if uint64(userID) >= uint64(HardenedOffset) {
+        return nil, fmt.Errorf("user ID %d is too large for a BIP-44 account", userID)
+    }
+    if index >= HardenedOffset {
+        return nil, fmt.Errorf("derivation index %d is out of range", index)
+    }
+
+    account := uint32(userID) + HardenedOffset
+
+    switch chain.Family {
+    case blockchain.FamilyBitcoin:
+        coin := CoinTypeBitcoin
+        if chain.Testnet {
+            coin = CoinTypeBitcoinTestnet
+        }
+        return Path{44 + HardenedOffset, coin + HardenedOffset, account, 0, index}, nil
+    case blockchain.FamilyEVM:
+        // Every EVM network shares Ethereum's coin type, like common wallets do
+        return Path{44 + HardenedOffset, CoinTypeEthereum + HardenedOffset, account, 0, index}, nil
+    case blockchain.FamilyTron:
+        return Path{44 + HardenedOffset, CoinTypeTron + HardenedOffset, account, 0, index}, nil
+    case blockchain.FamilySolana:
+        return Path{44 + HardenedOffset, CoinTypeSolana + HardenedOffset, account, index + HardenedOffset}, nil
+    default:
+        return nil, fmt.Errorf("no derivation scheme for chain family %s", chain.Family)
+    }
+}
@@ -3,6 +3,7 @@ package wallet
 import (
     "time"
 
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
     "gorm.io/gorm"
 )
 
@@ -10,37 +11,113 @@ import (
 type Wallet struct {
     ID        uint           `gorm:"primaryKey" json:"id"`
     UserID    uint           `gorm:"not null" json:"user_id"`
-    Address   string         `gorm:"not null;uniqueIndex" json:"address"`
-    Chain     string         `gorm:"not null" json:"chain"` // bitcoin, ethereum, solana, tron, bnb
+    Address   string         `gorm:"not null;uniqueIndex:idx_wallet_address_chain" json:"address"`
+    Chain     string         `gorm:"not null;uniqueIndex:idx_wallet_address_chain" json:"chain"` // name from the chain registry, e.g. bitcoin, ethereum, polygon
     PublicKey string         `gorm:"not null" json:"public_key"`
-    Balance   float64        `gorm:"default:0" json:"balance"`
+    PrivateKey string        `json:"-"` // key of a private (hot) wallet that signs withdrawals, empty for deposit addresses
+    Balance   units.Amount   `gorm:"not null;default:0" json:"balance"` // base units of the chain's native coin
+    Type      string         `gorm:"default:'deposit'" json:"type"` // deposit, hot, cold
     IsActive  bool           `gorm:"default:true" json:"is_active"`
+
+    // HD derivation of the address key, empty for wallets created from random keys
+    DerivationPath  string  `json:"derivation_path,omitempty"`
+    DerivationIndex *uint32 `json:"derivation_index,omitempty"`
+
     CreatedAt time.Time      `json:"created_at"`
     UpdatedAt time.Time      `json:"updated_at"`
     DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
-// Transaction represents a blockchain transaction
+// DerivationCursor records the next HD derivation index for a user's deposit addresses on a chain.
+// Together with the master seed it is enough to re-derive every deposit address of a chain.
+type DerivationCursor struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    UserID    uint      `gorm:"not null;uniqueIndex:idx_derivation_cursor_user_chain" json:"user_id"`
+    Chain     string    `gorm:"not null;uniqueIndex:idx_derivation_cursor_user_chain" json:"chain"`
+    NextIndex uint32    `gorm:"not null;default:0" json:"next_index"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BlockCursor records the last block a chain's block watcher processed, so it resumes after that
+// block when restarted instead of skipping the deposits made while it was down
+type BlockCursor struct {
+    ID        uint      `gorm:"primaryKey" json:"id"`
+    Chain     string    `gorm:"not null;uniqueIndex" json:"chain"`
+    Height    uint64    `gorm:"not null" json:"height"`
+    Hash      string    `gorm:"not null" json:"hash"`
+    CreatedAt time.Time `json:"created_at"`
+    UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WatchedBlock is one of the recent blocks a block watcher processed. The watcher compares each
+// new block's parent with them to detect reorgs and find the common ancestor.
+type WatchedBlock struct {
+    ID         uint      `gorm:"primaryKey" json:"id"`
+    Chain      string    `gorm:"not null;uniqueIndex:idx_watched_block_chain_height" json:"chain"`
+    Height     uint64    `gorm:"not null;uniqueIndex:idx_watched_block_chain_height" json:"height"`
+    Hash       string    `gorm:"not null" json:"hash"`
+    ParentHash string    `gorm:"not null" json:"parent_hash"`
+    CreatedAt  time.Time `json:"created_at"`
+}
+
+// Transaction represents a blockchain transaction. A deposit is unique per transfer, that is per
+// chain, hash, token, log index and trace path, and a broadcast withdrawal per chain and hash.
 type Transaction struct {
     ID            uint           `gorm:"primaryKey" json:"id"`
     WalletID      uint           `gorm:"not null" json:"wallet_id"`
-    TxHash        string         `gorm:"not null;uniqueIndex" json:"tx_hash"`
+    UserID        uint           `gorm:"index" json:"user_id,omitempty"` // user who requested a withdrawal; deposits belong to the wallet's user
+    TxHash        string         `gorm:"not null;uniqueIndex:idx_transaction_deposit,priority:2;uniqueIndex:idx_transaction_withdrawal,priority:2" json:"tx_hash"`
     FromAddress   string         `gorm:"not null" json:"from_address"`
     ToAddress     string         `gorm:"not null" json:"to_address"`
-    Amount        float64        `gorm:"not null" json:"amount"`
-    Chain         string         `gorm:"not null" json:"chain"`
-    Status        string         `gorm:"not null" json:"status"` // pending, confirmed, failed
-    Confirmations int            `gorm:"default:0" json:"confirmations"`
-    GasPrice      float64        `json:"gas_price,omitempty"`
+    Amount        units.Amount   `gorm:"not null" json:"amount"` // base units of the token, or of the native coin
+    Decimals      uint8          `gorm:"not null;default:0" json:"decimals"`
+    Chain         string         `gorm:"not null;uniqueIndex:idx_transaction_deposit,priority:1,where:type = 'deposit';uniqueIndex:idx_transaction_withdrawal,priority:1,where:type = 'withdrawal' AND tx_hash <> ''" json:"chain"`
+    TokenContract string         `gorm:"index;uniqueIndex:idx_transaction_deposit,priority:3" json:"token_contract,omitempty"` // token contract or SPL mint, empty for the native coin
+    LogIndex      uint           `gorm:"not null;default:0;uniqueIndex:idx_transaction_deposit,priority:4" json:"log_index,omitempty"` // index of the token's Transfer log in its block, or of the output (vout) on Bitcoin
+    TracePath     string         `gorm:"not null;default:'';uniqueIndex:idx_transaction_deposit,priority:5" json:"trace_path,omitempty"` // calls leading to an internal EVM transfer, such as 0.2, empty for top-level transfers
+    ScriptType    string         `json:"script_type,omitempty"` // script of a Bitcoin output, such as pubkeyhash or witness_v0_keyhash
+    TokenSymbol   string         `json:"token_symbol,omitempty"`
+    Type          string         `gorm:"not null;default:'deposit';index" json:"type"` // deposit, withdrawal
+    Status        string         `gorm:"not null;index" json:"status"` // unconfirmed (seen in the mempool), pending, processing (being sent), confirmed, final, failed, replaced, dropped, reverted, needs_review
+    Confirmations int            `gorm:"default:0" json:"confirmations"` // blocks on top of and including the transaction's block
+    BlockNumber   uint64         `gorm:"index" json:"block_number,omitempty"`
+    BlockHash     string         `gorm:"index" json:"block_hash,omitempty"`
+    FeeTier       string         `json:"fee_tier,omitempty"` // slow, standard, fast
+    GasPrice      float64        `json:"gas_price,omitempty"` // max fee per gas in gwei
+    GasTipCap     float64        `json:"gas_tip_cap,omitempty"` // max priority fee per gas in gwei
     GasLimit      float64        `json:"gas_limit,omitempty"`
     GasUsed       float64        `json:"gas_used,omitempty"`
-    Fee           float64        `json:"fee,omitempty"`
+    FeeRate       uint64         `json:"fee_rate,omitempty"` // sat/vB on Bitcoin, micro-lamports per compute unit on Solana
+    Fee           units.Amount   `gorm:"not null;default:0" json:"fee"` // base units of the native coin
     Memo          string         `json:"memo,omitempty"`
+    ErrorMessage  string         `json:"error_message,omitempty"` // why the last attempt to send a withdrawal failed
+    UseCustodial  bool           `gorm:"not null;default:false" json:"use_custodial,omitempty"` // withdrawal sent through a custodial provider instead of signed here
+
+    // Signed withdrawal whose broadcast got no answer, kept to be sent again exactly as it was
+    RawTx string `gorm:"type:text" json:"-"`
+
+    // Fee bumps link a stuck transaction and the one that replaced it; only one of them gets mined
+    ReplacesTxHash   string `gorm:"index" json:"replaces_tx_hash,omitempty"`
+    ReplacedByTxHash string `gorm:"index" json:"replaced_by_tx_hash,omitempty"`
+
     CreatedAt     time.Time      `json:"created_at"`
     UpdatedAt     time.Time      `json:"updated_at"`
     DeletedAt     gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 }
 
+// TokenBalance represents a wallet's balance of a token such as USDT or USDC
+type TokenBalance struct {
+    ID            uint         `gorm:"primaryKey" json:"id"`
+    WalletID      uint         `gorm:"not null;uniqueIndex:idx_token_balance_wallet_token" json:"wallet_id"`
+    TokenContract string       `gorm:"not null;uniqueIndex:idx_token_balance_wallet_token" json:"token_contract"` // ERC-20/TRC-20 contract or SPL mint
+    TokenSymbol   string       `json:"token_symbol,omitempty"`
+    Decimals      uint8        `gorm:"not null;default:0" json:"decimals"`
+    Balance       units.Amount `gorm:"not null;default:0" json:"balance"` // base units of the token
+    CreatedAt     time.Time    `json:"created_at"`
+    UpdatedAt     time.Time    `json:"updated_at"`
+}
+
 // CustodialWallet represents a custodial wallet managed by third-party services
 type CustodialWallet struct {
     ID              uint           `gorm:"primaryKey" json:"id"`
@@ -49,7 +126,7 @@ type CustodialWallet struct {
     Provider        string         `gorm:"not null" json:"provider"` // fireblocks, bitgo, coinbase
     Chain           string         `gorm:"not null" json:"chain"`
     Address         string         `gorm:"not null" json:"address"`
-    Balance         float64        `gorm:"default:0" json:"balance"`
+    Balance         units.Amount   `gorm:"not null;default:0" json:"balance"` // base units of the chain's native coin
     IsActive        bool           `gorm:"default:true" json:"is_active"`
     LastSyncedAt    time.Time      `json:"last_synced_at"`
     CreatedAt       time.Time      `json:"created_at"`
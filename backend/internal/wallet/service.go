@@ -2,10 +2,22 @@ package wallet
 
 import (
     "errors"
+    "fmt"
 
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
     "gorm.io/gorm"
 )
 
+// ErrUnsupportedChain is returned when a wallet names a chain missing from the chain registry
+var ErrUnsupportedChain = errors.New("unsupported chain")
+
+// ErrInvalidAmount is returned when a request's amount is not a valid display amount of its asset
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ErrDepositNotAllowed is returned when a request tries to record a deposit. Deposits are only
+// recorded by the chain watchers, from what they read on chain, since they are credited when final.
+var ErrDepositNotAllowed = errors.New("deposits are recorded from the chain and cannot be created")
+
 // Service provides wallet operations
 type Service struct {
     db *gorm.DB
@@ -22,9 +34,46 @@ func (s *Service) CreateWallet(wallet *Wallet) error {
         return errors.New("address, chain, and public key are required")
     }
 
+    if err := normalizeChain(&wallet.Chain); err != nil {
+        return err
+    }
+
+    if err := validateAddress(wallet.Chain, wallet.Address); err != nil {
+        return err
+    }
+    wallet.Address = normalizeAddress(wallet.Chain, wallet.Address)
+
     return s.db.Create(wallet).Error
 }
 
+// normalizeChain checks a chain name against the chain registry and replaces aliases with the canonical name
+func normalizeChain(chain *string) error {
+    config, ok := blockchain.Chains().Get(*chain)
+    if !ok {
+        return fmt.Errorf("%w: %s", ErrUnsupportedChain, *chain)
+    }
+
+    *chain = config.Name
+    return nil
+}
+
+// validateAddress checks an address against the format of a registered chain
+func validateAddress(chain, address string) error {
+    config, ok := blockchain.Chains().Get(chain)
+    if !ok {
+        return fmt.Errorf("%w: %s", ErrUnsupportedChain, chain)
+    }
+
+    return blockchain.ValidateAddress(config, address)
+}
+
+// normalizeAddress returns the canonical form of a validated address of a registered chain,
+// the form the deposit watchers look addresses up in
+func normalizeAddress(chain, address string) string {
+    config, _ := blockchain.Chains().Get(chain)
+    return blockchain.NormalizeAddress(config, address)
+}
+
 // GetWalletByID retrieves a wallet by ID
 func (s *Service) GetWalletByID(id uint) (*Wallet, error) {
     var wallet Wallet
@@ -54,6 +103,15 @@ func (s *Service) GetWalletsByUserID(userID uint) ([]Wallet, error) {
 
 // UpdateWallet updates a wallet
 func (s *Service) UpdateWallet(wallet *Wallet) error {
+    if err := normalizeChain(&wallet.Chain); err != nil {
+        return err
+    }
+
+    if err := validateAddress(wallet.Chain, wallet.Address); err != nil {
+        return err
+    }
+    wallet.Address = normalizeAddress(wallet.Chain, wallet.Address)
+
     return s.db.Save(wallet).Error
 }
 
@@ -62,11 +120,33 @@ func (s *Service) DeleteWallet(id uint) error {
     return s.db.Delete(&Wallet{}, id).Error
 }
 
-// CreateTransaction creates a new transaction
+// CreateTransaction records a withdrawal broadcast outside this service. It starts out pending,
+// whatever status the request gives, and the confirmation tracker follows it from there.
 func (s *Service) CreateTransaction(tx *Transaction) error {
-    if tx.TxHash == "" || tx.FromAddress == "" || tx.ToAddress == "" || tx.Amount <= 0 {
+    if tx.TxHash == "" || tx.FromAddress == "" || tx.ToAddress == "" || tx.Amount.Sign() <= 0 {
         return errors.New("transaction hash, from address, to address, and amount are required")
     }
+    if tx.Type != "withdrawal" {
+        return ErrDepositNotAllowed
+    }
+
+    if err := normalizeChain(&tx.Chain); err != nil {
+        return err
+    }
+    if err := validateAddress(tx.Chain, tx.FromAddress); err != nil {
+        return fmt.Errorf("from address: %w", err)
+    }
+    if err := validateAddress(tx.Chain, tx.ToAddress); err != nil {
+        return fmt.Errorf("to address: %w", err)
+    }
+
+    tx.FromAddress = normalizeAddress(tx.Chain, tx.FromAddress)
+    tx.ToAddress = normalizeAddress(tx.Chain, tx.ToAddress)
+
+    tx.Status = "pending"
+    tx.Confirmations = 0
+    tx.BlockNumber = 0
+    tx.BlockHash = ""
 
     return s.db.Create(tx).Error
 }
@@ -99,6 +179,14 @@ func (s *Service) CreateCustodialWallet(wallet *CustodialWallet) error {
         return errors.New("external ID, provider, chain, and address are required")
     }
 
+    if err := normalizeChain(&wallet.Chain); err != nil {
+        return err
+    }
+    if err := validateAddress(wallet.Chain, wallet.Address); err != nil {
+        return err
+    }
+    wallet.Address = normalizeAddress(wallet.Chain, wallet.Address)
+
     return s.db.Create(wallet).Error
 }
 
@@ -0,0 +1,195 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "hash/fnv"
+    "sync"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/wallet"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "gorm.io/gorm"
+)
+
+// addressReloadInterval is how often the index is reloaded in full, so addresses of deleted
+// wallets stop being watched; new addresses are picked up on every refresh
+const addressReloadInterval = 10 * time.Minute
+
+// Bloom filter sizing: bits per address and hash functions per lookup, for about a 1% false
+// positive rate, and the fewest addresses it is sized for
+const (
+    bloomBitsPerAddress = 10
+    bloomHashes         = 7
+    bloomMinAddresses   = 1024
+)
+
+// AddressIndex is an in-memory index of the deposit addresses of one chain and their wallet IDs.
+// A block watcher checks every recipient in a block against it, so lookups never reach the
+// database, and a bloom filter in front of the map turns away the recipients that are not ours,
+// nearly all of them, from a compact bit array. Addresses are indexed and looked up in their
+// canonical form, so a lowercase EVM address stored for a wallet matches the checksummed one a
+// block carries. The index is loaded once, then refreshed with the wallets created since, and
+// is safe for concurrent use.
+type AddressIndex struct {
+    db     *gorm.DB
+    chain  string
+    config blockchain.ChainConfig
+
+    mu       sync.RWMutex
+    wallets  map[string]uint
+    filter   *bloomFilter
+    lastID   uint
+    loadedAt time.Time
+}
+
+// NewAddressIndex creates an empty index of a chain's deposit addresses
+func NewAddressIndex(db *gorm.DB, chain string) *AddressIndex {
+    config, _ := blockchain.Chains().Get(chain)
+    return &AddressIndex{
+        db:      db,
+        chain:   chain,
+        config:  config,
+        wallets: make(map[string]uint),
+        filter:  newBloomFilter(bloomMinAddresses),
+    }
+}
+
+// Contains reports whether an address is one of our deposit addresses
+func (i *AddressIndex) Contains(address string) bool {
+    _, ok := i.WalletID(address)
+    return ok
+}
+
+// WalletID returns the ID of the wallet an address belongs to
+func (i *AddressIndex) WalletID(address string) (uint, bool) {
+    address = blockchain.NormalizeAddress(i.config, address)
+
+    i.mu.RLock()
+    defer i.mu.RUnlock()
+
+    if !i.filter.mayContain(address) {
+        return 0, false
+    }
+    id, ok := i.wallets[address]
+    return id, ok
+}
+
+// Len returns the number of indexed addresses
+func (i *AddressIndex) Len() int {
+    i.mu.RLock()
+    defer i.mu.RUnlock()
+    return len(i.wallets)
+}
+
+// Add indexes a wallet's address right away, such as a deposit address that was just created,
+// instead of waiting for the next refresh. Wallets of other chains are ignored.
+func (i *AddressIndex) Add(w *wallet.Wallet) {
+    if w.Chain != i.chain {
+        return
+    }
+
+    i.mu.Lock()
+    defer i.mu.Unlock()
+    i.add(w.Address, w.ID)
+}
+
+// add indexes an address; the caller holds the write lock
+func (i *AddressIndex) add(address string, id uint) {
+    address = blockchain.NormalizeAddress(i.config, address)
+    if _, ok := i.wallets[address]; !ok && len(i.wallets) >= i.filter.capacity {
+        // The filter is full and would let too many strangers through, so it is rebuilt twice as large
+        i.filter = newBloomFilter(2 * i.filter.capacity)
+        for indexed := range i.wallets {
+            i.filter.add(indexed)
+        }
+    }
+
+    i.wallets[address] = id
+    i.filter.add(address)
+}
+
+// Refresh loads the wallets created since the last refresh, or reloads every wallet of the chain
+// on the first call and when the last full load is older than the reload interval
+func (i *AddressIndex) Refresh(ctx context.Context) error {
+    i.mu.RLock()
+    lastID, loadedAt := i.lastID, i.loadedAt
+    i.mu.RUnlock()
+
+    full := loadedAt.IsZero() || time.Since(loadedAt) > addressReloadInterval
+    query := i.db.WithContext(ctx).Select("id", "address").Where("chain = ?", i.chain)
+    if !full {
+        query = query.Where("id > ?", lastID)
+    }
+
+    var wallets []wallet.Wallet
+    if err := query.Order("id").Find(&wallets).Error; err != nil {
+        return fmt.Errorf("failed to load deposit addresses: %w", err)
+    }
+
+    i.mu.Lock()
+    defer i.mu.Unlock()
+
+    if full {
+        i.wallets = make(map[string]uint, len(wallets))
+        capacity := bloomMinAddresses
+        for capacity < len(wallets) {
+            capacity *= 2
+        }
+        i.filter = newBloomFilter(capacity)
+        i.lastID = 0
+        i.loadedAt = time.Now()
+    }
+    for _, w := range wallets {
+        i.add(w.Address, w.ID)
+        if w.ID > i.lastID {
+            i.lastID = w.ID
+        }
+    }
+    return nil
+}
+
+// bloomFilter is a set of strings that can answer "possibly present" for a string it was never
+// given, but never "absent" for one it was
+type bloomFilter struct {
+    bits     []uint64
+    capacity int // addresses the filter is sized for
+}
+
+// newBloomFilter creates a filter sized for a number of strings
+func newBloomFilter(capacity int) *bloomFilter {
+    words := (capacity*bloomBitsPerAddress + 63) / 64
+    return &bloomFilter{bits: make([]uint64, words), capacity: capacity}
+}
+
+// add sets the bits of a string
+func (f *bloomFilter) add(s string) {
+    h1, h2 := bloomHash(s)
+    size := uint64(len(f.bits)) * 64
+    for k := uint64(0); k < bloomHashes; k++ {
+        bit := (h1 + k*h2) % size
+        f.bits[bit/64] |= 1 << (bit % 64)
+    }
+}
+
+// mayContain reports whether a string may have been added
+func (f *bloomFilter) mayContain(s string) bool {
+    h1, h2 := bloomHash(s)
+    size := uint64(len(f.bits)) * 64
+    for k := uint64(0); k < bloomHashes; k++ {
+        bit := (h1 + k*h2) % size
+        if f.bits[bit/64]&(1<<(bit%64)) == 0 {
+            return false
+        }
+    }
+    return true
+}
+
+// bloomHash derives the two hashes the filter's hash functions are combined from
+func bloomHash(s string) (uint64, uint64) {
+    h := fnv.New64a()
+    h.Write([]byte(s))
+    sum := h.Sum64()
+    // An odd second hash visits distinct bits for every function
+    return sum & 0xffffffff, sum>>32 | 1
+}
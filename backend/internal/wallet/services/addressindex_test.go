@@ -0,0 +1,26 @@
+package services
+
+import (
+    "testing"
+
+    "github.com/blockchain-dapp/backend/internal/wallet"
+)
+
+func TestAddressIndexMatchesEVMAddressInAnyCase(t *testing.T) {
+    addresses := NewAddressIndex(nil, "ethereum")
+    addresses.Add(&wallet.Wallet{ID: 1, Chain: "ethereum", Address: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"})
+
+    // A block carries the checksummed form of the address stored in lowercase
+    for _, address := range []string{
+        "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+        "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+        "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED",
+    } {
+        if id, ok := addresses.WalletID(address); !ok || id != 1 {
+            t.Errorf("WalletID(%s) = %d, %v, want 1, true", address, id, ok)
+        }
+    }
+    if addresses.Contains("0x0000000000000000000000000000000000000001") {
+        t.Error("Contains() matched an address that was never added")
+    }
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+)
+
+// mempoolPollInterval is how often the Bitcoin mempool is listed for new transactions
+const mempoolPollInterval = 10 * time.Second
+
+// bitcoinChainWatcher finds the outputs paying our deposit addresses in Bitcoin blocks. Every
+// output is its own deposit, told apart from the other outputs of its transaction by its vout.
+type bitcoinChainWatcher struct {
+    source blockchain.BitcoinBlockSource
+    config blockchain.ChainConfig
+}
+
+// NewBitcoinChainWatcher creates a chain watcher for a Bitcoin chain reading blocks from a source,
+// such as a blockchain.BitcoinFixtureSource of recorded blocks
+func NewBitcoinChainWatcher(config blockchain.ChainConfig, source blockchain.BitcoinBlockSource) ChainWatcher {
+    return &bitcoinChainWatcher{source: source, config: config}
+}
+
+// Head returns the height of the best block
+func (w *bitcoinChainWatcher) Head(ctx context.Context) (uint64, error) {
+    return w.source.TipHeight(ctx)
+}
+
+// FetchRange returns the best chain's blocks from one height to another, inclusive
+func (w *bitcoinChainWatcher) FetchRange(ctx context.Context, from, to uint64) ([]ChainBlock, error) {
+    blocks := make([]ChainBlock, 0, to-from+1)
+    for height := from; height <= to; height++ {
+        hash, err := w.source.BlockHash(ctx, height)
+        if err != nil {
+            return nil, err
+        }
+        block, err := w.source.Block(ctx, hash)
+        if err != nil {
+            return nil, err
+        }
+        blocks = append(blocks, ChainBlock{
+            Height:     block.Height,
+            Hash:       block.Hash,
+            ParentHash: block.PreviousHash,
+            raw:        block,
+        })
+    }
+    return blocks, nil
+}
+
+// BlockHash returns the hash of the best chain's block at a height
+func (w *bitcoinChainWatcher) BlockHash(ctx context.Context, height uint64) (string, error) {
+    return w.source.BlockHash(ctx, height)
+}
+
+// Transfers returns the outputs of a block that pay one of the addresses
+func (w *bitcoinChainWatcher) Transfers(ctx context.Context, block ChainBlock, addresses *AddressIndex) ([]Transfer, error) {
+    raw, ok := block.raw.(*blockchain.BitcoinBlock)
+    if !ok {
+        return nil, fmt.Errorf("block %d was not fetched by the %s watcher", block.Height, w.config.Name)
+    }
+
+    var transfers []Transfer
+    for _, tx := range raw.Transactions {
+        for _, output := range tx.Outputs {
+            if output.Value <= 0 || !addresses.Contains(output.Address) {
+                continue
+            }
+            transfers = append(transfers, Transfer{
+                TxHash:     tx.Txid,
+                Index:      uint(output.Vout),
+                To:         output.Address,
+                Amount:     units.FromInt64(output.Value), // satoshis
+                Decimals:   w.config.Decimals,
+                ScriptType: output.ScriptType,
+            })
+        }
+    }
+
+    return transfers, nil
+}
+
+// WatchPending polls the mempool and reports the outputs of new transactions that pay one of the
+// addresses, if the block source can read the mempool. Transactions already in the mempool when
+// it starts are only seen once mined, so a restart does not fetch a whole mempool.
+func (w *bitcoinChainWatcher) WatchPending(ctx context.Context, addresses *AddressIndex, found func(Transfer)) error {
+    mempool, ok := w.source.(blockchain.BitcoinMempoolSource)
+    if !ok {
+        return ErrMempoolUnsupported
+    }
+
+    ticker := time.NewTicker(mempoolPollInterval)
+    defer ticker.Stop()
+
+    var seen map[string]bool
+    for {
+        txids, err := mempool.MempoolTxids(ctx)
+        if err != nil {
+            log.Printf("Error listing %s mempool: %v", w.config.Name, err)
+        } else {
+            current := make(map[string]bool, len(txids))
+            for _, txid := range txids {
+                current[txid] = true
+                if seen == nil || seen[txid] {
+                    continue
+                }
+
+                tx, err := mempool.MempoolTx(ctx, txid)
+                if errors.Is(err, blockchain.ErrNotFound) {
+                    // Mined or evicted since the mempool was listed
+                    continue
+                }
+                if err != nil {
+                    // Fetched again on the next poll
+                    log.Printf("Error fetching %s mempool transaction %s: %v", w.config.Name, txid, err)
+                    delete(current, txid)
+                    continue
+                }
+                for _, output := range tx.Outputs {
+                    if output.Value <= 0 || !addresses.Contains(output.Address) {
+                        continue
+                    }
+                    found(Transfer{
+                        TxHash:     tx.Txid,
+                        Index:      uint(output.Vout),
+                        To:         output.Address,
+                        Amount:     units.FromInt64(output.Value), // satoshis
+                        Decimals:   w.config.Decimals,
+                        ScriptType: output.ScriptType,
+                    })
+                }
+            }
+            seen = current
+        }
+
+        select {
+        case <-ctx.Done():
+            return ctx.Err()
+        case <-ticker.C:
+        }
+    }
+}
+
+// TransactionStatus reports whether a transaction is in the mempool or was mined, and how deep.
+// A mined Bitcoin transaction cannot fail.
+func (w *bitcoinChainWatcher) TransactionStatus(ctx context.Context, hash string) (PendingStatus, error) {
+    mempool, ok := w.source.(blockchain.BitcoinMempoolSource)
+    if !ok {
+        return PendingStatus{}, ErrMempoolUnsupported
+    }
+
+    confirmations, err := mempool.TransactionConfirmations(ctx, hash)
+    if errors.Is(err, blockchain.ErrNotFound) {
+        return PendingStatus{}, nil
+    }
+    if err != nil {
+        return PendingStatus{}, err
+    }
+    return PendingStatus{Known: true, Confirmations: confirmations}, nil
+}
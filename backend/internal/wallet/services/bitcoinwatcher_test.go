@@ -0,0 +1,89 @@
+package services
+
+import (
+    "context"
+    "reflect"
+    "testing"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "github.com/btcsuite/btcd/chaincfg"
+)
+
+func TestBitcoinTransfers(t *testing.T) {
+    ctx := context.Background()
+    config, err := blockchain.Chains().Lookup("bitcoin")
+    if err != nil {
+        t.Fatal(err)
+    }
+    source, err := blockchain.LoadBitcoinFixtures("../blockchain/testdata/bitcoin/bitcoind", &chaincfg.MainNetParams)
+    if err != nil {
+        t.Fatalf("LoadBitcoinFixtures() error = %v", err)
+    }
+    watcher := NewBitcoinChainWatcher(config, source)
+
+    // Deposit addresses of three script types; the block's other outputs pay someone else
+    addresses := NewAddressIndex(nil, config.Name)
+    for id, address := range map[uint]string{
+        1: "bc1qtu3x522kkc9r2fqfuzpv0c9wekf5wpgt55wvsf",
+        2: "1KcnaKLWb2w7oiZ69xh4aC51sPztwGU29n",
+        3: "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+    } {
+        addresses.Add(&wallet.Wallet{ID: id, Chain: config.Name, Address: address})
+    }
+
+    blocks, err := watcher.FetchRange(ctx, 100, 100)
+    if err != nil {
+        t.Fatalf("FetchRange() error = %v", err)
+    }
+    if len(blocks) != 1 || blocks[0].Hash != "0000000000000000000e1887678a726f7c9cb6304bb33c3eb4f4f9bd40cb9511" {
+        t.Fatalf("FetchRange() = %+v, want the fixture block", blocks)
+    }
+
+    transfers, err := watcher.Transfers(ctx, blocks[0], addresses)
+    if err != nil {
+        t.Fatalf("Transfers() error = %v", err)
+    }
+
+    // Each output is its own deposit, keyed by txid:vout
+    want := []Transfer{
+        {
+            TxHash:     "54977171b8b8dbb8f6941781829349d11f3a42403219ae8a54286554f4c38281",
+            Index:      0,
+            To:         "bc1qtu3x522kkc9r2fqfuzpv0c9wekf5wpgt55wvsf",
+            Amount:     units.FromInt64(50000000),
+            Decimals:   8,
+            ScriptType: "witness_v0_keyhash",
+        },
+        {
+            TxHash:     "f75fb9685a9b83db96f4fdfe7e7f92313dcb580b3e4f052cfd4b55203346cc57",
+            Index:      0,
+            To:         "1KcnaKLWb2w7oiZ69xh4aC51sPztwGU29n",
+            Amount:     units.FromInt64(12345),
+            Decimals:   8,
+            ScriptType: "pubkeyhash",
+        },
+        {
+            TxHash:     "f75fb9685a9b83db96f4fdfe7e7f92313dcb580b3e4f052cfd4b55203346cc57",
+            Index:      2,
+            To:         "bc1p0xlxvlhemja6c4dqv22uapctqupfhlxm9h8z3k2e72q4k9hcz7vqzk5jj0",
+            Amount:     units.FromInt64(2100000),
+            Decimals:   8,
+            ScriptType: "witness_v1_taproot",
+        },
+    }
+    if len(transfers) != len(want) {
+        t.Fatalf("Transfers() found %d transfers, want %d: %+v", len(transfers), len(want), transfers)
+    }
+    for i := range want {
+        got := transfers[i]
+        if got.Amount.Cmp(want[i].Amount) != 0 {
+            t.Errorf("transfer %s:%d amount = %s sats, want %s", got.TxHash, got.Index, got.Amount, want[i].Amount)
+        }
+        got.Amount = want[i].Amount
+        if !reflect.DeepEqual(got, want[i]) {
+            t.Errorf("transfer %d = %+v, want %+v", i, got, want[i])
+        }
+    }
+}
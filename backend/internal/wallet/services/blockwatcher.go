@@ -2,49 +2,129 @@ package services
 
 import (
     "context"
+    "errors"
     "fmt"
     "log"
-    "math/big"
     "sync"
     "time"
 
     "github.com/blockchain-dapp/backend/internal/wallet"
     "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
-    "github.com/ethereum/go-ethereum"
-    "github.com/ethereum/go-ethereum/common"
-    "github.com/ethereum/go-ethereum/core/types"
-    "github.com/ethereum/go-ethereum/ethclient"
     "gorm.io/gorm"
 )
 
-// BlockWatcher monitors blockchain blocks for deposit transactions
+// fetchBatch is how many heights one fetch from the chain covers
+const fetchBatch = 10
+
+// fetchWorkers is how many fetches run at once, bounding the load the watcher puts on the nodes
+const fetchWorkers = 4
+
+// headBuffer is how many pushed heads may wait while the watcher processes a block
+const headBuffer = 16
+
+// Delays between attempts to renew a failed head subscription, doubling up to the maximum
+const (
+    minResubscribeDelay = time.Second
+    maxResubscribeDelay = time.Minute
+)
+
+// minHeadTimeout is the shortest time a head subscription may go quiet before it is renewed
+const minHeadTimeout = time.Minute
+
+// errDepositMismatch means the chain does not carry a recorded deposit the way it was recorded
+var errDepositMismatch = errors.New("deposit does not match the chain")
+
+// BlockWatcher monitors a chain's blocks for deposits to our addresses. The chain is read by a
+// ChainWatcher, and the BlockWatcher drives it the same way on every chain: the last processed
+// block is kept in a wallet.BlockCursor so a restart resumes where the watcher stopped, a block
+// whose parent is not the block processed before it rolls the reorg back, and every transfer the
+// chain watcher finds is recorded as a deposit for the ConfirmationTracker to finalize and credit.
+// Blocks are fetched and scanned by a bounded pool of workers, ahead of the cursor, while the
+// cursor still moves one block at a time.
 type BlockWatcher struct {
     db           *gorm.DB
-    client       *ethclient.Client
+    store        *depositStore
+    watcher      ChainWatcher
+    addresses    *AddressIndex
     chain        string
-    lastBlock    *big.Int
+    config       blockchain.ChainConfig
+    lastBlock    uint64
+    resumed      bool
     pollInterval time.Duration
     mu           sync.RWMutex
     running      bool
+    metrics      WatcherMetrics
+}
+
+// WatcherMetrics is a snapshot of how far a block watcher is behind its chain and how fast it moves
+type WatcherMetrics struct {
+    Chain            string    `json:"chain"`
+    Head             uint64    `json:"head"`
+    LastBlock        uint64    `json:"last_block"`
+    Lag              uint64    `json:"lag"` // blocks between the head and the last processed block
+    Addresses        int       `json:"addresses"`
+    BlocksProcessed  uint64    `json:"blocks_processed"`
+    DepositsRecorded uint64    `json:"deposits_recorded"`
+    BlocksPerSecond  float64   `json:"blocks_per_second"` // throughput of the last catch-up
+    LastBlockAt      time.Time `json:"last_block_at"`
 }
 
-// NewBlockWatcher creates a new block watcher
-func NewBlockWatcher(db *gorm.DB, rpcURL, chain string, pollInterval time.Duration) (*BlockWatcher, error) {
-    client, err := ethclient.Dial(rpcURL)
+// scannedBlock is a fetched block with the transfers to our addresses found in it
+type scannedBlock struct {
+    block     ChainBlock
+    transfers []Transfer
+}
+
+// NewBlockWatcher creates a new block watcher with the chain watcher of the chain's family
+func NewBlockWatcher(db *gorm.DB, chain string, pollInterval time.Duration) (*BlockWatcher, error) {
+    config, err := blockchain.Chains().Lookup(chain)
     if err != nil {
-        return nil, fmt.Errorf("failed to connect to Ethereum node: %w", err)
+        return nil, err
     }
-    
+
+    watcher, err := NewChainWatcher(config)
+    if err != nil {
+        return nil, err
+    }
+
+    return NewBlockWatcherWithChain(db, config, watcher, pollInterval), nil
+}
+
+// NewBlockWatchers creates a block watcher for every chain in the registry, keyed by chain name,
+// so deposits are detected on every chain deposit addresses are generated for
+func NewBlockWatchers(db *gorm.DB, registry *blockchain.Registry, pollInterval time.Duration) (map[string]*BlockWatcher, error) {
+    watchers := make(map[string]*BlockWatcher)
+    for _, config := range registry.Chains() {
+        watcher, err := NewChainWatcher(config)
+        if err != nil {
+            return nil, err
+        }
+        watchers[config.Name] = NewBlockWatcherWithChain(db, config, watcher, pollInterval)
+    }
+    return watchers, nil
+}
+
+// NewBlockWatcherWithChain creates a new block watcher driving a given chain watcher, such as a
+// Bitcoin one reading recorded blocks
+func NewBlockWatcherWithChain(db *gorm.DB, config blockchain.ChainConfig, watcher ChainWatcher, pollInterval time.Duration) *BlockWatcher {
     return &BlockWatcher{
         db:           db,
-        client:       client,
-        chain:        chain,
+        store:        newDepositStore(db, config.Name),
+        watcher:      watcher,
+        addresses:    NewAddressIndex(db, config.Name),
+        chain:        config.Name,
+        config:       config,
         pollInterval: pollInterval,
         mu:           sync.RWMutex{},
-    }, nil
+        metrics:      WatcherMetrics{Chain: config.Name},
+    }
 }
 
-// Start begins watching for new blocks
+// Start begins watching for new blocks. It resumes after the last block recorded in the chain's
+// cursor, or starts at the current head the first time the chain is watched. When the chain
+// watcher can push new heads, blocks are processed as soon as they arrive; a dropped subscription
+// falls back to polling while it is renewed with backoff, and the heights missed in between are
+// caught up from the cursor. Otherwise the head is polled every poll interval.
 func (bw *BlockWatcher) Start(ctx context.Context) error {
     bw.mu.Lock()
     if bw.running {
@@ -53,145 +133,516 @@ func (bw *BlockWatcher) Start(ctx context.Context) error {
     }
     bw.running = true
     bw.mu.Unlock()
-    
-    // Get the current block number to start watching from
-    header, err := bw.client.HeaderByNumber(ctx, nil)
-    if err != nil {
-        return fmt.Errorf("failed to get current block header: %w", err)
-    }
-    
-    bw.lastBlock = header.Number
-    
-    log.Printf("Starting block watcher for %s from block %s", bw.chain, bw.lastBlock.String())
-    
-    // Start the watching loop
+
+    defer func() {
+        bw.mu.Lock()
+        bw.running = false
+        bw.mu.Unlock()
+    }()
+
+    if err := bw.resume(ctx); err != nil {
+        return err
+    }
+
+    if subscriber, ok := bw.watcher.(HeadSubscriber); ok {
+        return bw.follow(ctx, subscriber)
+    }
+    return bw.pollUntil(ctx, nil)
+}
+
+// follow processes blocks as the subscription pushes their heads, renewing the subscription
+// whenever it fails
+func (bw *BlockWatcher) follow(ctx context.Context, subscriber HeadSubscriber) error {
+    delay := minResubscribeDelay
+    for {
+        heads := make(chan uint64, headBuffer)
+        sub, err := subscriber.SubscribeHeads(ctx, heads)
+        if errors.Is(err, blockchain.ErrSubscriptionsUnsupported) {
+            log.Printf("No %s endpoint supports subscriptions, polling for new blocks", bw.chain)
+            return bw.pollUntil(ctx, nil)
+        }
+        if err != nil {
+            if ctx.Err() != nil {
+                return ctx.Err()
+            }
+            log.Printf("Failed to subscribe to %s heads, polling for %s before retrying: %v", bw.chain, delay, err)
+        } else {
+            log.Printf("Subscribed to %s heads", bw.chain)
+            received, err := bw.consume(ctx, sub, heads)
+            sub.Unsubscribe()
+            if ctx.Err() != nil {
+                return ctx.Err()
+            }
+            if received {
+                // The subscription worked before it failed, so it is renewed promptly
+                delay = minResubscribeDelay
+            }
+            log.Printf("%s head subscription dropped, polling for %s before resubscribing: %v", bw.chain, delay, err)
+        }
+
+        timer := time.NewTimer(delay)
+        err = bw.pollUntil(ctx, timer.C)
+        timer.Stop()
+        if err != nil {
+            return err
+        }
+        if delay *= 2; delay > maxResubscribeDelay {
+            delay = maxResubscribeDelay
+        }
+    }
+}
+
+// consume catches up on the heights missed before the subscription started, then processes
+// blocks up to every pushed head. It returns why the subscription ended, when it fails or when
+// no head arrives for longer than the chain should take, and whether it delivered any head.
+func (bw *BlockWatcher) consume(ctx context.Context, sub Subscription, heads <-chan uint64) (bool, error) {
+    if err := bw.Poll(ctx); err != nil {
+        log.Printf("Error catching up %s blocks: %v", bw.chain, err)
+    }
+
+    timeout := bw.headTimeout()
+    idle := time.NewTimer(timeout)
+    defer idle.Stop()
+
+    received := false
+    for {
+        select {
+        case <-ctx.Done():
+            return received, ctx.Err()
+        case err := <-sub.Err():
+            if err == nil {
+                err = fmt.Errorf("subscription closed")
+            }
+            return received, err
+        case <-idle.C:
+            // A subscription can stall without failing
+            return received, fmt.Errorf("no new head for %s", timeout)
+        case head := <-heads:
+            received = true
+            if !idle.Stop() {
+                <-idle.C
+            }
+            idle.Reset(timeout)
+
+            if err := bw.catchUp(ctx, head); err != nil {
+                log.Printf("Error watching %s blocks: %v", bw.chain, err)
+            }
+        }
+    }
+}
+
+// headTimeout is how long a subscription may go without a new head before it is considered stalled
+func (bw *BlockWatcher) headTimeout() time.Duration {
+    timeout := 10 * bw.config.BlockDuration()
+    if timeout < minHeadTimeout {
+        timeout = minHeadTimeout
+    }
+    return timeout
+}
+
+// pollUntil polls for new blocks every poll interval until the context is cancelled, returning
+// its error, or until the stop channel fires, returning nil. A nil stop channel never fires.
+func (bw *BlockWatcher) pollUntil(ctx context.Context, stop <-chan time.Time) error {
     ticker := time.NewTicker(bw.pollInterval)
     defer ticker.Stop()
-    
+
     for {
         select {
         case <-ctx.Done():
-            bw.mu.Lock()
-            bw.running = false
-            bw.mu.Unlock()
             return ctx.Err()
+        case <-stop:
+            return nil
         case <-ticker.C:
-            if err := bw.watchBlocks(ctx); err != nil {
-                log.Printf("Error watching blocks: %v", err)
+            if err := bw.Poll(ctx); err != nil {
+                log.Printf("Error watching %s blocks: %v", bw.chain, err)
             }
         }
     }
 }
 
-// watchBlocks checks for new blocks and processes transactions
-func (bw *BlockWatcher) watchBlocks(ctx context.Context) error {
-    // Get the current block number
-    header, err := bw.client.HeaderByNumber(ctx, nil)
-    if err != nil {
-        return fmt.Errorf("failed to get current block header: %w", err)
-    }
-    
-    currentBlock := header.Number
-    
-    // If we haven't processed any blocks yet, start from the current block
-    if bw.lastBlock == nil {
-        bw.lastBlock = currentBlock
+// resume loads the cursor the first time it is called, or saves one at the current head
+func (bw *BlockWatcher) resume(ctx context.Context) error {
+    if bw.resumed {
         return nil
     }
-    
-    // Process blocks from lastBlock + 1 to currentBlock
-    for i := new(big.Int).Add(bw.lastBlock, big.NewInt(1)); i.Cmp(currentBlock) <= 0; i.Add(i, big.NewInt(1)) {
-        if err := bw.processBlock(ctx, i); err != nil {
-            log.Printf("Error processing block %s: %v", i.String(), err)
+
+    cursor, err := bw.store.loadCursor(ctx)
+    if err != nil {
+        return err
+    }
+
+    if cursor != nil {
+        bw.lastBlock = cursor.Height
+        log.Printf("Resuming block watcher for %s after block %d (%s)", bw.chain, cursor.Height, cursor.Hash)
+    } else {
+        head, err := bw.watcher.Head(ctx)
+        if err != nil {
+            return fmt.Errorf("failed to get head block: %w", err)
         }
+        blocks, err := bw.watcher.FetchRange(ctx, head, head)
+        if err != nil {
+            return err
+        }
+        if len(blocks) == 0 {
+            return fmt.Errorf("no block at head %d", head)
+        }
+
+        block := blocks[len(blocks)-1]
+        if err := bw.store.advance(ctx, block.Height, block.Hash, block.ParentHash); err != nil {
+            return err
+        }
+        bw.lastBlock = block.Height
+        log.Printf("Starting block watcher for %s from block %d", bw.chain, bw.lastBlock)
     }
-    
-    // Update the last processed block
-    bw.lastBlock = currentBlock
-    
+
+    bw.observe(func(m *WatcherMetrics) { m.LastBlock = bw.lastBlock })
+    bw.resumed = true
     return nil
 }
 
-// processBlock processes transactions in a block
-func (bw *BlockWatcher) processBlock(ctx context.Context, blockNumber *big.Int) error {
-    log.Printf("Processing block %s", blockNumber.String())
-    
-    // Get the block by number
-    block, err := bw.client.BlockByNumber(ctx, blockNumber)
+// Poll processes every block after the last processed one up to the current head
+func (bw *BlockWatcher) Poll(ctx context.Context) error {
+    head, err := bw.watcher.Head(ctx)
     if err != nil {
-        return fmt.Errorf("failed to get block %s: %w", blockNumber.String(), err)
+        return fmt.Errorf("failed to get head block: %w", err)
+    }
+
+    return bw.catchUp(ctx, head)
+}
+
+// catchUp processes every block after the last processed one up to a head, moving the
+// cursor after each block. Blocks are scanned a window at a time by the fetch workers, then
+// processed in order. It stops at the first block that fails so the next poll retries it
+// instead of skipping its deposits. A block whose parent is not the block the watcher processed
+// before it starts a reorg rollback to the common ancestor, after which the canonical blocks are
+// processed again.
+func (bw *BlockWatcher) catchUp(ctx context.Context, head uint64) error {
+    if err := bw.resume(ctx); err != nil {
+        return err
+    }
+    bw.observe(func(m *WatcherMetrics) { m.Head = head })
+    if head <= bw.lastBlock {
+        return nil
     }
-    
-    // Process each transaction in the block
-    for _, tx := range block.Transactions() {
-        if err := bw.processTransaction(ctx, tx); err != nil {
-            log.Printf("Error processing transaction %s: %v", tx.Hash().Hex(), err)
+
+    if err := bw.addresses.Refresh(ctx); err != nil {
+        return err
+    }
+
+    started := time.Now()
+    processed := 0
+    defer func() {
+        if elapsed := time.Since(started).Seconds(); processed > 0 && elapsed > 0 {
+            bw.observe(func(m *WatcherMetrics) { m.BlocksPerSecond = float64(processed) / elapsed })
         }
+    }()
+
+    for from := bw.lastBlock + 1; from <= head; {
+        to := from + fetchWorkers*fetchBatch - 1
+        if to > head {
+            to = head
+        }
+
+        // Blocks scanned before a failed fetch are still processed
+        scanned, scanErr := bw.scan(ctx, from, to)
+
+        reorged := false
+        for _, s := range scanned {
+            block := s.block
+            parent, known, err := bw.store.blockHash(ctx, bw.lastBlock)
+            if err != nil {
+                return err
+            }
+            if known && parent != block.ParentHash {
+                ancestor, err := bw.store.rollback(ctx, bw.lastBlock, bw.watcher.BlockHash)
+                if err != nil {
+                    return fmt.Errorf("failed to roll back reorg at block %d: %w", block.Height, err)
+                }
+
+                // Continue from the block after the common ancestor
+                bw.lastBlock = ancestor
+                bw.observe(func(m *WatcherMetrics) { m.LastBlock = ancestor })
+                reorged = true
+                break
+            }
+
+            recorded, err := bw.processBlock(ctx, s, head)
+            if err != nil {
+                return fmt.Errorf("failed to process block %d: %w", block.Height, err)
+            }
+
+            if err := bw.store.advance(ctx, block.Height, block.Hash, block.ParentHash); err != nil {
+                return err
+            }
+            bw.lastBlock = block.Height
+            processed++
+            bw.observe(func(m *WatcherMetrics) {
+                m.LastBlock = block.Height
+                m.BlocksProcessed++
+                m.DepositsRecorded += uint64(recorded)
+                m.LastBlockAt = time.Now()
+            })
+        }
+
+        if reorged {
+            from = bw.lastBlock + 1
+            continue
+        }
+        if scanErr != nil {
+            return scanErr
+        }
+        from = to + 1
     }
-    
+
     return nil
 }
 
-// processTransaction checks if a transaction is a deposit to one of our addresses
-func (bw *BlockWatcher) processTransaction(ctx context.Context, tx *types.Transaction) error {
-    // We only care about transactions that have a recipient (not contract creation)
-    if tx.To() == nil {
-        return nil
+// scan fetches the blocks from one height to another, inclusive, and finds the transfers to our
+// addresses in them. The range is split into fetch batches that the fetch workers fetch and scan
+// concurrently. It returns the blocks in order up to the first batch that failed, and that
+// batch's error.
+func (bw *BlockWatcher) scan(ctx context.Context, from, to uint64) ([]scannedBlock, error) {
+    type batch struct {
+        blocks []scannedBlock
+        err    error
+    }
+    batches := make([]batch, (to-from)/fetchBatch+1)
+
+    workers := make(chan struct{}, fetchWorkers)
+    var wg sync.WaitGroup
+    for i := range batches {
+        start := from + uint64(i)*fetchBatch
+        end := start + fetchBatch - 1
+        if end > to {
+            end = to
+        }
+
+        wg.Add(1)
+        workers <- struct{}{}
+        go func(i int, start, end uint64) {
+            defer wg.Done()
+            defer func() { <-workers }()
+            batches[i].blocks, batches[i].err = bw.scanBatch(ctx, start, end)
+        }(i, start, end)
+    }
+    wg.Wait()
+
+    var scanned []scannedBlock
+    for _, b := range batches {
+        if b.err != nil {
+            return scanned, b.err
+        }
+        scanned = append(scanned, b.blocks...)
     }
-    
-    // Check if the recipient address is one of our deposit addresses
-    var wallet wallet.Wallet
-    err := bw.db.Where("address = ? AND chain = ?", tx.To().Hex(), bw.chain).First(&wallet).Error
+    return scanned, nil
+}
+
+// scanBatch fetches the blocks of one fetch batch and finds the transfers in them
+func (bw *BlockWatcher) scanBatch(ctx context.Context, from, to uint64) ([]scannedBlock, error) {
+    blocks, err := bw.watcher.FetchRange(ctx, from, to)
     if err != nil {
-        // Not one of our addresses, that's fine
-        if err == gorm.ErrRecordNotFound {
-            return nil
+        return nil, err
+    }
+
+    scanned := make([]scannedBlock, 0, len(blocks))
+    for _, block := range blocks {
+        transfers, err := bw.watcher.Transfers(ctx, block, bw.addresses)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan block %d: %w", block.Height, err)
+        }
+        scanned = append(scanned, scannedBlock{block: block, transfers: transfers})
+    }
+    return scanned, nil
+}
+
+// Backfill rescans the blocks from one height to another, inclusive, and records any deposit
+// that was missed. Deposits that are already recorded are skipped, so a window can be replayed
+// safely, and the watcher's cursor is left where it is. It returns the number of new deposits.
+func (bw *BlockWatcher) Backfill(ctx context.Context, from, to uint64) (int, error) {
+    if from > to {
+        return 0, fmt.Errorf("backfill range %d-%d is empty", from, to)
+    }
+
+    head, err := bw.watcher.Head(ctx)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get head block: %w", err)
+    }
+    if head < to {
+        return 0, fmt.Errorf("backfill range ends at block %d, after the current head %d", to, head)
+    }
+
+    if err := bw.addresses.Refresh(ctx); err != nil {
+        return 0, err
+    }
+
+    log.Printf("Backfilling %s blocks %d to %d", bw.chain, from, to)
+
+    recorded := 0
+    for start := from; start <= to; start += fetchWorkers * fetchBatch {
+        if ctx.Err() != nil {
+            return recorded, ctx.Err()
+        }
+
+        end := start + fetchWorkers*fetchBatch - 1
+        if end > to {
+            end = to
+        }
+
+        scanned, scanErr := bw.scan(ctx, start, end)
+        for _, s := range scanned {
+            n, err := bw.processBlock(ctx, s, head)
+            recorded += n
+            if err != nil {
+                return recorded, fmt.Errorf("failed to backfill block %d: %w", s.block.Height, err)
+            }
         }
-        return fmt.Errorf("failed to query wallet: %w", err)
+        if scanErr != nil {
+            return recorded, scanErr
+        }
+    }
+
+    log.Printf("Backfilled %s blocks %d to %d, recorded %d missed deposits", bw.chain, from, to, recorded)
+
+    return recorded, nil
+}
+
+// Cursor returns the last block the watcher processed on its chain, or nil if it never ran
+func (bw *BlockWatcher) Cursor(ctx context.Context) (*wallet.BlockCursor, error) {
+    return bw.store.loadCursor(ctx)
+}
+
+// AddAddress starts watching a deposit address as soon as it is created, before the next refresh
+// of the address index. Wallets of other chains are ignored, so the method can be registered
+// with DepositService.OnAddressCreated for every watcher.
+func (bw *BlockWatcher) AddAddress(w *wallet.Wallet) {
+    bw.addresses.Add(w)
+}
+
+// VerifyDeposit reads the block a deposit was recorded in again and checks that it still carries
+// the deposit's transfer, to the address of the deposit's wallet and for its amount. The
+// ConfirmationTracker calls it before crediting a deposit, so only what the chain shows is ever
+// credited. A deposit the chain does not confirm fails with errDepositMismatch.
+func (bw *BlockWatcher) VerifyDeposit(ctx context.Context, deposit wallet.Transaction) error {
+    if deposit.Chain != bw.chain || deposit.BlockHash == "" {
+        return fmt.Errorf("%w: deposit %s was not recorded from a %s block", errDepositMismatch, deposit.TxHash, bw.chain)
+    }
+
+    var w wallet.Wallet
+    if err := bw.db.WithContext(ctx).First(&w, deposit.WalletID).Error; err != nil {
+        return fmt.Errorf("failed to get wallet %d: %w", deposit.WalletID, err)
+    }
+    if w.Chain != bw.chain {
+        return fmt.Errorf("%w: wallet %d is on %s", errDepositMismatch, w.ID, w.Chain)
+    }
+
+    blocks, err := bw.watcher.FetchRange(ctx, deposit.BlockNumber, deposit.BlockNumber)
+    if err != nil {
+        return fmt.Errorf("failed to fetch block %d: %w", deposit.BlockNumber, err)
     }
-    
-    // This is a deposit to one of our addresses
-    log.Printf("Found deposit transaction %s to address %s", tx.Hash().Hex(), tx.To().Hex())
-    
-    // Get the transaction receipt to confirm it
-    receipt, err := bw.client.TransactionReceipt(ctx, tx.Hash())
+    if len(blocks) != 1 || blocks[0].Hash != deposit.BlockHash {
+        return fmt.Errorf("%w: block %d is not %s", errDepositMismatch, deposit.BlockNumber, deposit.BlockHash)
+    }
+
+    // Only the deposit's wallet is looked for, so a transfer to any other address cannot match
+    addresses := NewAddressIndex(bw.db, bw.chain)
+    addresses.add(w.Address, w.ID)
+
+    transfers, err := bw.watcher.Transfers(ctx, blocks[0], addresses)
     if err != nil {
-        return fmt.Errorf("failed to get transaction receipt: %w", err)
+        return fmt.Errorf("failed to read transfers of block %d: %w", deposit.BlockNumber, err)
     }
-    
-    // Check if the transaction was successful
-    if receipt.Status != types.ReceiptStatusSuccessful {
-        log.Printf("Transaction %s failed, not processing", tx.Hash().Hex())
+    for _, transfer := range transfers {
+        if transfer.TxHash != deposit.TxHash || transfer.TokenContract != deposit.TokenContract || transfer.Index != deposit.LogIndex || transfer.TracePath != deposit.TracePath {
+            continue
+        }
+        if id, ok := addresses.WalletID(transfer.To); !ok || id != w.ID {
+            continue
+        }
+        if transfer.Amount.Cmp(deposit.Amount) != 0 {
+            return fmt.Errorf("%w: %s pays %s, recorded as %s", errDepositMismatch, deposit.TxHash, transfer.Amount, deposit.Amount)
+        }
         return nil
     }
-    
-    // Create a transaction record
-    transaction := &wallet.Transaction{
-        WalletID:    wallet.ID,
-        TxHash:      tx.Hash().Hex(),
-        FromAddress: "", // Would need to derive from signature
-        ToAddress:   tx.To().Hex(),
-        Amount:      float64(tx.Value().Int64()) / 1000000000000000000, // Convert wei to ETH
-        Chain:       bw.chain,
-        Status:      "confirmed",
-        Confirmations: int(receipt.BlockNumber.Uint64()),
-        Fee:         0, // Would calculate from gas price and limit
-    }
-    
-    // Save the transaction to the database
-    if err := bw.db.Create(transaction).Error; err != nil {
-        return fmt.Errorf("failed to save transaction: %w", err)
-    }
-    
-    // Update the wallet balance
-    wallet.Balance += transaction.Amount
-    if err := bw.db.Save(&wallet).Error; err != nil {
-        return fmt.Errorf("failed to update wallet balance: %w", err)
-    }
-    
-    log.Printf("Processed deposit transaction %s for %f ETH to wallet %d", tx.Hash().Hex(), transaction.Amount, wallet.ID)
-    
-    return nil
+
+    return fmt.Errorf("%w: block %d has no transfer %s/%d to %s", errDepositMismatch, deposit.BlockNumber, deposit.TxHash, deposit.LogIndex, w.Address)
+}
+
+// Metrics returns a snapshot of the watcher's lag and throughput
+func (bw *BlockWatcher) Metrics() WatcherMetrics {
+    bw.mu.RLock()
+    metrics := bw.metrics
+    bw.mu.RUnlock()
+
+    if metrics.Head > metrics.LastBlock {
+        metrics.Lag = metrics.Head - metrics.LastBlock
+    }
+    metrics.Addresses = bw.addresses.Len()
+    return metrics
+}
+
+// observe updates the watcher's metrics
+func (bw *BlockWatcher) observe(update func(m *WatcherMetrics)) {
+    bw.mu.Lock()
+    defer bw.mu.Unlock()
+    update(&bw.metrics)
+}
+
+// processBlock records the deposits found in a block, counting confirmations up to the head block.
+// Deposits that are already recorded are skipped, and deposits reverted by a reorg are restored
+// when they are mined again on the canonical chain. It returns the number of new deposits, and
+// fails if any deposit in the block could not be recorded.
+func (bw *BlockWatcher) processBlock(ctx context.Context, scanned scannedBlock, head uint64) (int, error) {
+    block := scanned.block
+    log.Printf("Processing %s block %d", bw.chain, block.Height)
+
+    confirmations := confirmationsAt(head, block.Height)
+    recorded := 0
+    var firstErr error
+    for _, transfer := range scanned.transfers {
+        walletID, ok := bw.addresses.WalletID(transfer.To)
+        if !ok {
+            continue
+        }
+
+        transaction := &wallet.Transaction{
+            WalletID:      walletID,
+            TxHash:        transfer.TxHash,
+            FromAddress:   transfer.From,
+            ToAddress:     transfer.To,
+            Amount:        transfer.Amount,
+            Decimals:      transfer.Decimals,
+            Chain:         bw.chain,
+            TokenContract: transfer.TokenContract,
+            TokenSymbol:   transfer.TokenSymbol,
+            LogIndex:      transfer.Index,
+            ScriptType:    transfer.ScriptType,
+            TracePath:     transfer.TracePath,
+            Type:          "deposit",
+            Status:        "confirmed",
+            Confirmations: confirmations,
+            BlockNumber:   block.Height,
+            BlockHash:     block.Hash,
+        }
+
+        saved, err := bw.store.recordDeposit(ctx, transaction)
+        if err != nil {
+            log.Printf("Error recording deposit %s/%d: %v", transfer.TxHash, transfer.Index, err)
+            if firstErr == nil {
+                firstErr = err
+            }
+            continue
+        }
+        if saved {
+            recorded++
+            symbol := transfer.TokenSymbol
+            if transfer.TokenContract == "" {
+                symbol = bw.config.NativeSymbol
+            }
+            log.Printf("Recorded deposit %s/%d for %s %s to wallet %d with %d of %d confirmations", transfer.TxHash, transfer.Index, transfer.Amount.Format(transfer.Decimals), symbol, walletID, confirmations, bw.config.Confirmations)
+        }
+    }
+
+    return recorded, firstErr
 }
 
 // Stop stops the block watcher
@@ -208,26 +659,11 @@ func (bw *BlockWatcher) IsRunning() bool {
     return bw.running
 }
 
-// ConnectToTestnet connects the block watcher to an Ethereum testnet
+// ConnectToTestnet connects the block watcher of an EVM chain to an Ethereum testnet
 func (bw *BlockWatcher) ConnectToTestnet(network string) error {
-    var rpcURL string
-    switch network {
-    case "goerli":
-        rpcURL = "https://goerli.infura.io/v3/YOUR_INFURA_PROJECT_ID"
-    case "sepolia":
-        rpcURL = "https://sepolia.infura.io/v3/YOUR_INFURA_PROJECT_ID"
-    case "rinkeby":
-        rpcURL = "https://rinkeby.infura.io/v3/YOUR_INFURA_PROJECT_ID"
-    default:
-        return fmt.Errorf("unsupported testnet: %s", network)
-    }
-    
-    client, err := ethclient.Dial(rpcURL)
-    if err != nil {
-        return fmt.Errorf("failed to connect to %s testnet: %w", network, err)
+    evm, ok := bw.watcher.(*evmChainWatcher)
+    if !ok {
+        return fmt.Errorf("chain %s is not an EVM network", bw.chain)
     }
-    
-    bw.client = client
-    
-    return nil
-}
\ No newline at end of file
+    return evm.connectToTestnet(network)
+}
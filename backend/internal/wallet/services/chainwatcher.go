@@ -0,0 +1,87 @@
+package services
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+)
+
+// ChainWatcher reads the blocks of one chain and finds the transfers to our deposit addresses in
+// them. A BlockWatcher drives it: the driver keeps the cursor, detects reorgs from the parent
+// hashes, and records what the chain watcher extracts, so every chain feeds the same deposit
+// pipeline. Heights are block numbers, or slots on Solana. The driver fetches and scans several
+// ranges at once, so FetchRange and Transfers must be safe for concurrent use.
+type ChainWatcher interface {
+    // Head returns the height of the latest block the watcher should process
+    Head(ctx context.Context) (uint64, error)
+    // FetchRange returns the blocks from one height to another, inclusive, in order. Heights
+    // without a block, such as skipped Solana slots, are left out.
+    FetchRange(ctx context.Context, from, to uint64) ([]ChainBlock, error)
+    // BlockHash returns the hash of the canonical block at a height
+    BlockHash(ctx context.Context, height uint64) (string, error)
+    // Transfers returns the transfers in a block that pay one of the addresses
+    Transfers(ctx context.Context, block ChainBlock, addresses *AddressIndex) ([]Transfer, error)
+}
+
+// HeadSubscriber is implemented by chain watchers whose nodes can push new heads, which lets a
+// BlockWatcher process blocks as soon as they are produced instead of polling for them
+type HeadSubscriber interface {
+    // SubscribeHeads sends the height of every new head to a channel until the subscription is
+    // cancelled or fails
+    SubscribeHeads(ctx context.Context, heads chan<- uint64) (Subscription, error)
+}
+
+// Subscription is a stream of notifications; Err delivers the error that ended it, and is closed
+// by Unsubscribe
+type Subscription interface {
+    Unsubscribe()
+    Err() <-chan error
+}
+
+// ChainBlock is a block fetched by a ChainWatcher
+type ChainBlock struct {
+    Height     uint64
+    Hash       string
+    ParentHash string
+    raw        interface{} // the chain's own block, read back by the watcher that fetched it
+}
+
+// Transfer is a payment to a deposit address found in a block
+type Transfer struct {
+    TxHash        string
+    Index         uint // Transfer log, output or balance index that tells the transfers of one transaction apart
+    From          string
+    To            string
+    Amount        units.Amount // base units of the token, or of the native coin
+    Decimals      uint8
+    TokenContract string // empty for the native coin
+    TokenSymbol   string
+    ScriptType    string // script of a Bitcoin output
+    TracePath     string // calls leading to an internal EVM transfer, empty for top-level transfers
+}
+
+// NewChainWatcher creates the chain watcher of a chain's family
+func NewChainWatcher(config blockchain.ChainConfig) (ChainWatcher, error) {
+    switch config.Family {
+    case blockchain.FamilyEVM:
+        client, err := blockchain.DialMultiClient(config)
+        if err != nil {
+            return nil, fmt.Errorf("failed to connect to %s nodes: %w", config.Name, err)
+        }
+        return NewEVMChainWatcher(config, client), nil
+    case blockchain.FamilyBitcoin:
+        source, err := blockchain.NewBitcoinBlockSource(config)
+        if err != nil {
+            return nil, err
+        }
+        return NewBitcoinChainWatcher(config, source), nil
+    case blockchain.FamilySolana:
+        return NewSolanaChainWatcher(config, blockchain.NewSolanaAdapter(config.RPCURLs...)), nil
+    case blockchain.FamilyTron:
+        return NewTronChainWatcher(config, blockchain.NewTronAdapter(config.RPCURLs...)), nil
+    default:
+        return nil, fmt.Errorf("deposit watching is not supported on chain %s", config.Name)
+    }
+}
@@ -0,0 +1,374 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/wallet"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "gorm.io/gorm"
+)
+
+// defaultDropAfter is how long a broadcast transaction may be unknown to the node before it is
+// considered dropped from the mempool
+const defaultDropAfter = time.Hour
+
+// errTransactionChanged means another writer changed a transaction's status since it was loaded
+var errTransactionChanged = errors.New("transaction status changed concurrently")
+
+// trackedStatuses are the statuses a transaction can still move out of
+var trackedStatuses = []string{"pending", "confirmed", "replaced"}
+
+// StatusChange is emitted whenever the tracker moves a transaction to another status
+type StatusChange struct {
+    Transaction wallet.Transaction
+    From        string
+    To          string
+}
+
+// ConfirmationTracker revisits every deposit and withdrawal that is not final yet, counts its
+// confirmations against the chain head and moves it to confirmed, final, failed or dropped.
+// A transaction is final once it has as many confirmations as its chain requires, and deposits
+// are only credited to their wallet at that point, after the block watcher of their chain has
+// read them from the chain again.
+type ConfirmationTracker struct {
+    db           *gorm.DB
+    adapters     map[string]blockchain.Adapter
+    watchers     map[string]*BlockWatcher
+    pollInterval time.Duration
+    dropAfter    time.Duration
+    subscribers  []func(StatusChange)
+    mu           sync.RWMutex
+    running      bool
+}
+
+// NewConfirmationTracker creates a new confirmation tracker
+func NewConfirmationTracker(db *gorm.DB, adapters map[string]blockchain.Adapter, pollInterval time.Duration) *ConfirmationTracker {
+    return &ConfirmationTracker{
+        db:           db,
+        adapters:     adapters,
+        watchers:     make(map[string]*BlockWatcher),
+        pollInterval: pollInterval,
+        dropAfter:    defaultDropAfter,
+        mu:           sync.RWMutex{},
+    }
+}
+
+// SetDropAfter changes how long a broadcast transaction may be unknown to the node before it is marked dropped
+func (t *ConfirmationTracker) SetDropAfter(d time.Duration) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.dropAfter = d
+}
+
+// UseBlockWatcher verifies the deposits of the watcher's chain against the chain before crediting
+// them. Final deposits on a chain without a block watcher stay confirmed and are not credited.
+func (t *ConfirmationTracker) UseBlockWatcher(watcher *BlockWatcher) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.watchers[watcher.chain] = watcher
+}
+
+// Subscribe registers a handler that is called after every status change is saved.
+// Handlers run on the tracker's goroutine and should return quickly.
+func (t *ConfirmationTracker) Subscribe(handler func(StatusChange)) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    t.subscribers = append(t.subscribers, handler)
+}
+
+// Start revisits the tracked transactions every poll interval until the context is cancelled
+func (t *ConfirmationTracker) Start(ctx context.Context) error {
+    t.mu.Lock()
+    if t.running {
+        t.mu.Unlock()
+        return fmt.Errorf("confirmation tracker is already running")
+    }
+    t.running = true
+    t.mu.Unlock()
+
+    ticker := time.NewTicker(t.pollInterval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            t.mu.Lock()
+            t.running = false
+            t.mu.Unlock()
+            return ctx.Err()
+        case <-ticker.C:
+            if err := t.Poll(ctx); err != nil {
+                log.Printf("Error tracking confirmations: %v", err)
+            }
+        }
+    }
+}
+
+// IsRunning returns whether the tracker is currently running
+func (t *ConfirmationTracker) IsRunning() bool {
+    t.mu.RLock()
+    defer t.mu.RUnlock()
+    return t.running
+}
+
+// Poll revisits every broadcast transaction that is not final yet once
+func (t *ConfirmationTracker) Poll(ctx context.Context) error {
+    var transactions []wallet.Transaction
+    err := t.db.WithContext(ctx).
+        Where("status IN ? AND tx_hash <> ''", trackedStatuses).
+        Order("id").
+        Find(&transactions).Error
+    if err != nil {
+        return fmt.Errorf("failed to load tracked transactions: %w", err)
+    }
+
+    for i := range transactions {
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+        if err := t.track(ctx, &transactions[i]); err != nil {
+            log.Printf("Error tracking transaction %s on %s: %v", transactions[i].TxHash, transactions[i].Chain, err)
+        }
+    }
+
+    return nil
+}
+
+// track refreshes one transaction from the chain
+func (t *ConfirmationTracker) track(ctx context.Context, transaction *wallet.Transaction) error {
+    t.mu.RLock()
+    adapter, exists := t.adapters[transaction.Chain]
+    t.mu.RUnlock()
+
+    if !exists {
+        return fmt.Errorf("unsupported chain: %s", transaction.Chain)
+    }
+
+    config, err := blockchain.Chains().Lookup(transaction.Chain)
+    if err != nil {
+        return err
+    }
+
+    tx, err := adapter.GetTransaction(ctx, transaction.TxHash)
+    if errors.Is(err, blockchain.ErrNotFound) {
+        return t.trackMissing(ctx, transaction)
+    }
+    if err != nil {
+        return err
+    }
+
+    status := finalityStatus(tx, config.Confirmations)
+    if status == "pending" && transaction.Status == "replaced" {
+        // A replaced transaction keeps its status until one of its group is mined
+        return nil
+    }
+    if status == transaction.Status && tx.Confirmations == transaction.Confirmations {
+        return nil
+    }
+
+    if status == "final" && transaction.Type == "deposit" {
+        // Only a deposit the chain still shows as recorded is credited
+        err := t.verifyDeposit(ctx, *transaction)
+        if errors.Is(err, errDepositMismatch) {
+            return t.holdDeposit(ctx, transaction, err)
+        }
+        if err != nil {
+            return err
+        }
+    }
+
+    from := transaction.Status
+    transaction.Status = status
+    transaction.Confirmations = tx.Confirmations
+    transaction.UpdatedAt = time.Now()
+
+    var dropped []wallet.Transaction
+    err = t.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+        if err := updateStatus(db, transaction, from); err != nil {
+            return err
+        }
+        if status != "pending" && from != "confirmed" && (transaction.ReplacesTxHash != "" || transaction.ReplacedByTxHash != "") {
+            // Only one transaction of a replacement group can be mined
+            if dropped, err = dropReplacements(ctx, db, *transaction); err != nil {
+                return err
+            }
+        }
+        if status == "final" && transaction.Type == "deposit" {
+            if err := creditDeposit(ctx, db, *transaction); err != nil {
+                return err
+            }
+        }
+        return nil
+    })
+    if errors.Is(err, errTransactionChanged) {
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to update transaction %s: %w", transaction.TxHash, err)
+    }
+
+    for _, other := range dropped {
+        t.emit(StatusChange{Transaction: other, From: "replaced", To: "dropped"})
+    }
+    if from != status {
+        t.emit(StatusChange{Transaction: *transaction, From: from, To: status})
+    }
+
+    return nil
+}
+
+// verifyDeposit checks a deposit against the chain with the block watcher of its chain
+func (t *ConfirmationTracker) verifyDeposit(ctx context.Context, deposit wallet.Transaction) error {
+    t.mu.RLock()
+    watcher, exists := t.watchers[deposit.Chain]
+    t.mu.RUnlock()
+
+    if !exists {
+        return fmt.Errorf("no block watcher to verify deposits on %s", deposit.Chain)
+    }
+    return watcher.VerifyDeposit(ctx, deposit)
+}
+
+// holdDeposit moves a deposit the chain does not confirm to needs_review instead of crediting it
+func (t *ConfirmationTracker) holdDeposit(ctx context.Context, deposit *wallet.Transaction, reason error) error {
+    from := deposit.Status
+    deposit.Status = "needs_review"
+    deposit.UpdatedAt = time.Now()
+
+    err := updateStatus(t.db.WithContext(ctx), deposit, from)
+    if errors.Is(err, errTransactionChanged) {
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to update transaction %s: %w", deposit.TxHash, err)
+    }
+
+    alertf("deposit %s to wallet %d on %s was not credited: %v", deposit.TxHash, deposit.WalletID, deposit.Chain, reason)
+    t.emit(StatusChange{Transaction: *deposit, From: from, To: deposit.Status})
+    return nil
+}
+
+// trackMissing handles a transaction the node does not know. A mined transaction that disappears
+// was removed by a reorg and goes back to pending; a pending one is dropped once it has been
+// missing for longer than the drop delay.
+func (t *ConfirmationTracker) trackMissing(ctx context.Context, transaction *wallet.Transaction) error {
+    t.mu.RLock()
+    dropAfter := t.dropAfter
+    t.mu.RUnlock()
+
+    from := transaction.Status
+    switch {
+    case from == "confirmed":
+        transaction.Status = "pending"
+        transaction.Confirmations = 0
+    case from == "pending" && time.Since(transaction.UpdatedAt) > dropAfter:
+        transaction.Status = "dropped"
+    default:
+        // Replaced transactions are settled when their replacement is mined or dropped
+        return nil
+    }
+    transaction.UpdatedAt = time.Now()
+
+    var dropped []wallet.Transaction
+    err := t.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+        if transaction.Status == "dropped" && transaction.ReplacesTxHash != "" {
+            // The latest replacement is gone, so nothing in its group will be mined anymore
+            var err error
+            if dropped, err = dropReplacements(ctx, db, *transaction); err != nil {
+                return err
+            }
+        }
+        return updateStatus(db, transaction, from)
+    })
+    if errors.Is(err, errTransactionChanged) {
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to update transaction %s: %w", transaction.TxHash, err)
+    }
+
+    for _, other := range dropped {
+        t.emit(StatusChange{Transaction: other, From: "replaced", To: "dropped"})
+    }
+    t.emit(StatusChange{Transaction: *transaction, From: from, To: transaction.Status})
+
+    return nil
+}
+
+// dropReplacements marks every other replaced transaction in a transaction's replacement group as
+// dropped and returns them
+func dropReplacements(ctx context.Context, db *gorm.DB, transaction wallet.Transaction) ([]wallet.Transaction, error) {
+    group, err := replacementChain(ctx, db, transaction)
+    if err != nil {
+        return nil, err
+    }
+
+    var dropped []wallet.Transaction
+    for _, other := range group {
+        if other.ID == transaction.ID || other.Status != "replaced" {
+            continue
+        }
+        other.Status = "dropped"
+        other.UpdatedAt = time.Now()
+        if err := db.Model(&other).Updates(map[string]interface{}{"status": other.Status, "updated_at": other.UpdatedAt}).Error; err != nil {
+            return nil, err
+        }
+        dropped = append(dropped, other)
+    }
+
+    return dropped, nil
+}
+
+// updateStatus saves a transaction's new status and confirmations if its status is still the one
+// it was loaded with, so a transition, and the credit that comes with it, is only applied once
+func updateStatus(db *gorm.DB, transaction *wallet.Transaction, from string) error {
+    result := db.Model(&wallet.Transaction{}).
+        Where("id = ? AND status = ?", transaction.ID, from).
+        Updates(map[string]interface{}{
+            "status":        transaction.Status,
+            "confirmations": transaction.Confirmations,
+            "updated_at":    transaction.UpdatedAt,
+        })
+    if result.Error != nil {
+        return result.Error
+    }
+    if result.RowsAffected == 0 {
+        return errTransactionChanged
+    }
+    return nil
+}
+
+// emit calls every subscriber with a status change
+func (t *ConfirmationTracker) emit(change StatusChange) {
+    t.mu.RLock()
+    subscribers := t.subscribers
+    t.mu.RUnlock()
+
+    log.Printf("Transaction %s on %s moved from %s to %s with %d confirmations", change.Transaction.TxHash, change.Transaction.Chain, change.From, change.To, change.Transaction.Confirmations)
+    for _, handler := range subscribers {
+        handler(change)
+    }
+}
+
+// finalityStatus returns the status of a transaction on chain given the confirmations its chain requires
+func finalityStatus(tx *blockchain.Transaction, required int) string {
+    if required < 1 {
+        required = 1
+    }
+
+    switch {
+    case tx.Confirmations == 0:
+        return "pending"
+    case tx.Status == "failed":
+        return "failed"
+    case tx.Confirmations >= required:
+        return "final"
+    default:
+        return "confirmed"
+    }
+}
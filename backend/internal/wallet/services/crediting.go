@@ -0,0 +1,210 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/accounting"
+    "github.com/blockchain-dapp/backend/internal/wallet"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// customerDepositsAccount names the ledger liability account holding what the platform owes a user
+// in one native coin; liability accounts of a token add its chain and contract to the name
+const customerDepositsAccount = "customer deposits"
+
+// creditDeposit credits a final deposit inside the caller's database transaction: it posts the
+// deposit to the accounting ledger, debiting the chain's hot wallet asset account and crediting
+// the user's liability account, and adds it to the wallet balance, or to the wallet's token
+// balance. A deposit whose ledger posting already exists, and was not reversed since, is not
+// credited again.
+func creditDeposit(ctx context.Context, db *gorm.DB, deposit wallet.Transaction) error {
+    reference, existing, err := depositPosting(ctx, db, deposit)
+    if err != nil {
+        return err
+    }
+    if existing != nil {
+        // Credited by an earlier attempt
+        return nil
+    }
+
+    var w wallet.Wallet
+    if err := db.First(&w, deposit.WalletID).Error; err != nil {
+        return fmt.Errorf("failed to get wallet %d: %w", deposit.WalletID, err)
+    }
+
+    asset, liability, currency, err := depositAccounts(ctx, db, w, deposit)
+    if err != nil {
+        return err
+    }
+
+    amount := deposit.Amount.Decimal(deposit.Decimals)
+    description := fmt.Sprintf("Deposit %s on %s", deposit.TxHash, deposit.Chain)
+    _, posted, err := accounting.NewLedgerService(db).PostTransaction(ctx, accounting.Transaction{
+        ReferenceID:    reference,
+        AccountID:      liability.ID,
+        CounterpartyID: &asset.ID,
+        Type:           accounting.TypeDeposit,
+        Amount:         amount,
+        Currency:       currency,
+        Description:    description,
+    }, []accounting.JournalEntry{
+        {AccountID: asset.ID, Debit: amount, Description: description},
+        {AccountID: liability.ID, Credit: amount, Description: description},
+    })
+    if err != nil {
+        return err
+    }
+    if !posted {
+        // Credited by an earlier attempt
+        return nil
+    }
+
+    if deposit.TokenContract == "" {
+        err := db.Model(&wallet.Wallet{}).
+            Where("id = ?", deposit.WalletID).
+            Updates(map[string]interface{}{"balance": gorm.Expr("balance + ?", deposit.Amount), "updated_at": time.Now()}).Error
+        if err != nil {
+            return fmt.Errorf("failed to credit wallet %d: %w", deposit.WalletID, err)
+        }
+        return nil
+    }
+
+    tokenBalance := &wallet.TokenBalance{
+        WalletID:      deposit.WalletID,
+        TokenContract: deposit.TokenContract,
+        TokenSymbol:   deposit.TokenSymbol,
+        Decimals:      deposit.Decimals,
+        Balance:       deposit.Amount,
+    }
+    err = db.Clauses(clause.OnConflict{
+        Columns: []clause.Column{{Name: "wallet_id"}, {Name: "token_contract"}},
+        DoUpdates: clause.Assignments(map[string]interface{}{
+            "balance":    gorm.Expr("token_balances.balance + ?", deposit.Amount),
+            "updated_at": time.Now(),
+        }),
+    }).Create(tokenBalance).Error
+    if err != nil {
+        return fmt.Errorf("failed to credit %s to wallet %d: %w", deposit.TokenSymbol, deposit.WalletID, err)
+    }
+    return nil
+}
+
+// reverseDeposit takes back the credit of a final deposit that is no longer on the canonical
+// chain, inside the caller's database transaction: the ledger posting is reversed and the amount
+// is subtracted from the wallet balance, or from the wallet's token balance. A deposit that was
+// never credited, or whose credit was already reversed, is left alone.
+func reverseDeposit(ctx context.Context, db *gorm.DB, deposit wallet.Transaction, reason string) error {
+    reference, existing, err := depositPosting(ctx, db, deposit)
+    if err != nil {
+        return err
+    }
+    if existing == nil {
+        return nil
+    }
+
+    _, posted, err := accounting.NewLedgerService(db).ReverseTransaction(ctx, reference, reason)
+    if err != nil {
+        return err
+    }
+    if !posted {
+        return nil
+    }
+
+    if deposit.TokenContract == "" {
+        err = db.Model(&wallet.Wallet{}).
+            Where("id = ?", deposit.WalletID).
+            Updates(map[string]interface{}{"balance": gorm.Expr("balance - ?", deposit.Amount), "updated_at": time.Now()}).Error
+    } else {
+        err = db.Model(&wallet.TokenBalance{}).
+            Where("wallet_id = ? AND token_contract = ?", deposit.WalletID, deposit.TokenContract).
+            Updates(map[string]interface{}{"balance": gorm.Expr("balance - ?", deposit.Amount), "updated_at": time.Now()}).Error
+    }
+    if err != nil {
+        return fmt.Errorf("failed to reverse deposit %s to wallet %d: %w", deposit.TxHash, deposit.WalletID, err)
+    }
+    return nil
+}
+
+// depositPosting returns the ledger reference a deposit is credited under now, and the posting
+// made under it, nil if there is none yet. Every credit a reorg reversed moves the deposit on to
+// the next reference, numbered from 2, so a deposit mined in block A, reorged out to block B and
+// back to A is credited again instead of matching its reversed first credit.
+func depositPosting(ctx context.Context, db *gorm.DB, deposit wallet.Transaction) (string, *accounting.Transaction, error) {
+    base := depositReference(deposit)
+    for attempt := 1; ; attempt++ {
+        reference := base
+        if attempt > 1 {
+            reference = fmt.Sprintf("%s:%d", base, attempt)
+        }
+
+        var posting accounting.Transaction
+        if err := db.WithContext(ctx).Where("reference_id = ?", reference).Limit(1).Find(&posting).Error; err != nil {
+            return "", nil, fmt.Errorf("failed to get ledger posting %s: %w", reference, err)
+        }
+        if posting.ID == 0 {
+            return reference, nil, nil
+        }
+        if posting.Status != accounting.StatusReversed {
+            return reference, &posting, nil
+        }
+    }
+}
+
+// depositReference is the first ledger reference ID of a deposit's credit. It includes the block
+// hash, so a deposit that a reorg reverted is credited anew when it is mined again in another
+// block, and the trace path of internal transfers, which share their transaction's hash and index.
+func depositReference(deposit wallet.Transaction) string {
+    reference := fmt.Sprintf("deposit:%s:%s:%s:%d:%s", deposit.Chain, deposit.TxHash, deposit.TokenContract, deposit.LogIndex, deposit.BlockHash)
+    if deposit.TracePath != "" {
+        reference += ":" + deposit.TracePath
+    }
+    return reference
+}
+
+// depositAccounts returns the hot wallet asset account of a deposit's chain and asset, the user's
+// liability account for the asset, and the asset's currency code. Anyone can deploy a token with
+// the symbol of another, so token accounts are told apart by contract rather than by symbol.
+func depositAccounts(ctx context.Context, db *gorm.DB, w wallet.Wallet, deposit wallet.Transaction) (*accounting.Account, *accounting.Account, string, error) {
+    currency := deposit.TokenSymbol
+    assetName := fmt.Sprintf("%s hot wallet", deposit.Chain)
+    liabilityName := customerDepositsAccount
+    if deposit.TokenContract == "" {
+        config, err := blockchain.Chains().Lookup(deposit.Chain)
+        if err != nil {
+            return nil, nil, "", err
+        }
+        currency = config.NativeSymbol
+    } else {
+        assetName = fmt.Sprintf("%s %s", assetName, deposit.TokenContract)
+        liabilityName = fmt.Sprintf("%s %s %s", customerDepositsAccount, deposit.Chain, deposit.TokenContract)
+    }
+
+    ledger := accounting.NewLedgerService(db)
+    asset, err := ledger.FindOrCreateAccount(ctx, accounting.Account{
+        Name:        assetName,
+        Type:        accounting.AccountTypeAsset,
+        Currency:    currency,
+        Description: fmt.Sprintf("%s held in deposit and hot wallets on %s", currency, deposit.Chain),
+    })
+    if err != nil {
+        return nil, nil, "", err
+    }
+
+    userID := w.UserID
+    liability, err := ledger.FindOrCreateAccount(ctx, accounting.Account{
+        UserID:      &userID,
+        Name:        liabilityName,
+        Type:        accounting.AccountTypeLiability,
+        Currency:    currency,
+        Description: fmt.Sprintf("%s owed to user %d", currency, userID),
+    })
+    if err != nil {
+        return nil, nil, "", err
+    }
+
+    return asset, liability, currency, nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+    "context"
+    "fmt"
+    "testing"
+
+    "github.com/blockchain-dapp/backend/internal/accounting"
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet"
+    "gorm.io/driver/sqlite"
+    "gorm.io/gorm"
+    "gorm.io/gorm/logger"
+)
+
+// newTestLedgerDB opens an in-memory database with only the ledger tables
+func newTestLedgerDB(t *testing.T) *gorm.DB {
+    t.Helper()
+
+    db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{
+        Logger: logger.Default.LogMode(logger.Silent),
+    })
+    if err != nil {
+        t.Fatalf("failed to open database: %v", err)
+    }
+    sqlDB, err := db.DB()
+    if err != nil {
+        t.Fatalf("failed to open database: %v", err)
+    }
+    sqlDB.SetMaxOpenConns(1)
+    t.Cleanup(func() { sqlDB.Close() })
+
+    if err := accounting.NewLedgerService(db).InitializeDB(); err != nil {
+        t.Fatalf("failed to migrate ledger tables: %v", err)
+    }
+    return db
+}
+
+func TestDepositPostingMovesOnAfterReversal(t *testing.T) {
+    ctx := context.Background()
+    db := newTestLedgerDB(t)
+    ledger := accounting.NewLedgerService(db)
+
+    deposit := wallet.Transaction{Chain: "ethereum", TxHash: "0xabc", BlockHash: "0xa", Amount: units.FromInt64(1)}
+    base := depositReference(deposit)
+
+    asset, err := ledger.FindOrCreateAccount(ctx, accounting.Account{Name: "ethereum hot wallet", Type: accounting.AccountTypeAsset, Currency: "ETH"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    userID := uint(1)
+    liability, err := ledger.FindOrCreateAccount(ctx, accounting.Account{UserID: &userID, Name: customerDepositsAccount, Type: accounting.AccountTypeLiability, Currency: "ETH"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    post := func(reference string) {
+        t.Helper()
+
+        amount := deposit.Amount.Decimal(18)
+        _, _, err := ledger.PostTransaction(ctx, accounting.Transaction{
+            ReferenceID: reference,
+            AccountID:   liability.ID,
+            Type:        accounting.TypeDeposit,
+            Amount:      amount,
+            Currency:    "ETH",
+        }, []accounting.JournalEntry{
+            {AccountID: asset.ID, Debit: amount},
+            {AccountID: liability.ID, Credit: amount},
+        })
+        if err != nil {
+            t.Fatal(err)
+        }
+    }
+    reverse := func(reference string) {
+        t.Helper()
+
+        if _, _, err := ledger.ReverseTransaction(ctx, reference, "reorg"); err != nil {
+            t.Fatal(err)
+        }
+    }
+
+    // Each step changes the ledger, then checks the reference depositPosting settles on
+    tests := []struct {
+        name          string
+        change        func()
+        wantReference string
+        wantPosting   bool
+    }{
+        {"never credited", func() {}, base, false},
+        {"credited", func() { post(base) }, base, true},
+        {"first credit reversed", func() { reverse(base) }, base + ":2", false},
+        {"credited again", func() { post(base + ":2") }, base + ":2", true},
+        {"second credit reversed", func() { reverse(base + ":2") }, base + ":3", false},
+    }
+    for _, tt := range tests {
+        tt.change()
+
+        reference, posting, err := depositPosting(ctx, db, deposit)
+        if err != nil {
+            t.Fatalf("%s: %v", tt.name, err)
+        }
+        if reference != tt.wantReference {
+            t.Errorf("%s: reference = %s, want %s", tt.name, reference, tt.wantReference)
+        }
+        if (posting != nil) != tt.wantPosting {
+            t.Errorf("%s: posting = %v, want posting %v", tt.name, posting, tt.wantPosting)
+        }
+        if posting != nil && posting.ReferenceID != reference {
+            t.Errorf("%s: posting reference = %s, want %s", tt.name, posting.ReferenceID, reference)
+        }
+    }
+}
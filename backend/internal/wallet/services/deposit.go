@@ -8,14 +8,20 @@ import (
 
     "github.com/blockchain-dapp/backend/internal/wallet"
     "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "github.com/blockchain-dapp/backend/internal/wallet/hdwallet"
     "gorm.io/gorm"
+    "gorm.io/gorm/clause"
 )
 
 // DepositService handles deposit address generation and monitoring
 type DepositService struct {
     db       *gorm.DB
     adapters map[string]blockchain.Adapter
+    keyring  *hdwallet.Keyring
     mu       sync.RWMutex
+
+    // listeners are told about every deposit address created or restored
+    listeners []func(*wallet.Wallet)
 }
 
 // NewDepositService creates a new deposit service
@@ -27,15 +33,51 @@ func NewDepositService(db *gorm.DB, adapters map[string]blockchain.Adapter) *Dep
     }
 }
 
+// UseKeyring derives new deposit addresses from the keyring's master seed instead of random keys
+func (s *DepositService) UseKeyring(keyring *hdwallet.Keyring) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.keyring = keyring
+}
+
+// OnAddressCreated registers a function to call with every deposit address created or restored,
+// such as BlockWatcher.AddAddress so deposits to the address are seen from its first block
+func (s *DepositService) OnAddressCreated(listener func(*wallet.Wallet)) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.listeners = append(s.listeners, listener)
+}
+
+// addressCreated tells the listeners about a new deposit address
+func (s *DepositService) addressCreated(w *wallet.Wallet) {
+    s.mu.RLock()
+    listeners := s.listeners
+    s.mu.RUnlock()
+
+    for _, listener := range listeners {
+        listener(w)
+    }
+}
+
 // GenerateDepositAddress generates a new deposit address for a user and chain
 func (s *DepositService) GenerateDepositAddress(ctx context.Context, userID uint, chain string) (*wallet.Wallet, error) {
     s.mu.RLock()
     adapter, exists := s.adapters[chain]
+    keyring := s.keyring
     s.mu.RUnlock()
     
     if !exists {
         return nil, fmt.Errorf("unsupported chain: %s", chain)
     }
+
+    if keyring != nil {
+        depositWallet, err := s.deriveDepositAddress(ctx, keyring, adapter, userID, chain)
+        if err != nil {
+            return nil, err
+        }
+        s.addressCreated(depositWallet)
+        return depositWallet, nil
+    }
     
     // Create a new wallet using the blockchain adapter
     w, err := adapter.CreateWallet(ctx)
@@ -56,10 +98,177 @@ func (s *DepositService) GenerateDepositAddress(ctx context.Context, userID uint
     if err := s.db.Create(depositWallet).Error; err != nil {
         return nil, fmt.Errorf("failed to save wallet to database: %w", err)
     }
+    s.addressCreated(depositWallet)
     
     return depositWallet, nil
 }
 
+// deriveDepositAddress derives the user's next deposit address for a chain from the master seed.
+// The index is claimed and the wallet saved in one database transaction, so an index is never
+// handed out twice and never skipped.
+func (s *DepositService) deriveDepositAddress(ctx context.Context, keyring *hdwallet.Keyring, adapter blockchain.Adapter, userID uint, chain string) (*wallet.Wallet, error) {
+    config, err := blockchain.Chains().Lookup(chain)
+    if err != nil {
+        return nil, err
+    }
+
+    var depositWallet *wallet.Wallet
+    err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+        cursor := wallet.DerivationCursor{UserID: userID, Chain: chain}
+        if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&cursor).Error; err != nil {
+            return fmt.Errorf("failed to create derivation cursor: %w", err)
+        }
+        if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+            Where("user_id = ? AND chain = ?", userID, chain).
+            First(&cursor).Error; err != nil {
+            return fmt.Errorf("failed to lock derivation cursor: %w", err)
+        }
+
+        index := cursor.NextIndex
+        w, path, err := deriveWallet(keyring, adapter, config, userID, index)
+        if err != nil {
+            return err
+        }
+
+        depositWallet = &wallet.Wallet{
+            UserID:          userID,
+            Chain:           chain,
+            Address:         w.Address,
+            PublicKey:       w.PublicKey,
+            Type:            "deposit",
+            DerivationPath:  path.String(),
+            DerivationIndex: &index,
+        }
+        if err := tx.Create(depositWallet).Error; err != nil {
+            return fmt.Errorf("failed to save wallet to database: %w", err)
+        }
+
+        return tx.Model(&cursor).Update("next_index", index+1).Error
+    })
+    if err != nil {
+        return nil, err
+    }
+
+    return depositWallet, nil
+}
+
+// DeriveDepositKey re-derives the private key of an HD deposit address, for example to sweep it.
+// The key is never stored; callers should drop it as soon as the transaction is signed.
+func (s *DepositService) DeriveDepositKey(ctx context.Context, walletID uint) (*blockchain.Wallet, error) {
+    var w wallet.Wallet
+    if err := s.db.WithContext(ctx).First(&w, walletID).Error; err != nil {
+        return nil, err
+    }
+    if w.DerivationIndex == nil {
+        return nil, fmt.Errorf("wallet %d was not derived from the master seed", walletID)
+    }
+
+    s.mu.RLock()
+    adapter, exists := s.adapters[w.Chain]
+    keyring := s.keyring
+    s.mu.RUnlock()
+
+    if !exists {
+        return nil, fmt.Errorf("unsupported chain: %s", w.Chain)
+    }
+    if keyring == nil {
+        return nil, fmt.Errorf("no master seed configured")
+    }
+
+    config, err := blockchain.Chains().Lookup(w.Chain)
+    if err != nil {
+        return nil, err
+    }
+
+    derived, _, err := deriveWallet(keyring, adapter, config, w.UserID, *w.DerivationIndex)
+    if err != nil {
+        return nil, err
+    }
+    if derived.Address != w.Address {
+        return nil, fmt.Errorf("derived address %s does not match wallet address %s", derived.Address, w.Address)
+    }
+
+    return derived, nil
+}
+
+// RestoreDepositAddresses re-derives every deposit address of a chain recorded in the derivation
+// cursors and recreates any wallet rows that are missing. It returns the number of restored wallets.
+func (s *DepositService) RestoreDepositAddresses(ctx context.Context, chain string) (int, error) {
+    s.mu.RLock()
+    adapter, exists := s.adapters[chain]
+    keyring := s.keyring
+    s.mu.RUnlock()
+
+    if !exists {
+        return 0, fmt.Errorf("unsupported chain: %s", chain)
+    }
+    if keyring == nil {
+        return 0, fmt.Errorf("no master seed configured")
+    }
+
+    config, err := blockchain.Chains().Lookup(chain)
+    if err != nil {
+        return 0, err
+    }
+
+    var cursors []wallet.DerivationCursor
+    if err := s.db.WithContext(ctx).Where("chain = ?", chain).Find(&cursors).Error; err != nil {
+        return 0, fmt.Errorf("failed to load derivation cursors: %w", err)
+    }
+
+    restored := 0
+    for _, cursor := range cursors {
+        for index := uint32(0); index < cursor.NextIndex; index++ {
+            w, path, err := deriveWallet(keyring, adapter, config, cursor.UserID, index)
+            if err != nil {
+                return restored, err
+            }
+
+            index := index
+            depositWallet := &wallet.Wallet{
+                UserID:          cursor.UserID,
+                Chain:           chain,
+                Address:         w.Address,
+                PublicKey:       w.PublicKey,
+                Type:            "deposit",
+                DerivationPath:  path.String(),
+                DerivationIndex: &index,
+            }
+
+            result := s.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(depositWallet)
+            if result.Error != nil {
+                return restored, fmt.Errorf("failed to restore wallet %s: %w", w.Address, result.Error)
+            }
+            if result.RowsAffected > 0 {
+                restored++
+                s.addressCreated(depositWallet)
+            }
+        }
+    }
+
+    return restored, nil
+}
+
+// deriveWallet derives the deposit wallet at a user's derivation index on a chain
+func deriveWallet(keyring *hdwallet.Keyring, adapter blockchain.Adapter, config blockchain.ChainConfig, userID uint, index uint32) (*blockchain.Wallet, hdwallet.Path, error) {
+    path, err := hdwallet.DepositPath(config, userID, index)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    key, err := keyring.DeriveKey(config.Family, path)
+    if err != nil {
+        return nil, nil, err
+    }
+
+    w, err := adapter.WalletFromPrivateKey(key)
+    if err != nil {
+        return nil, nil, fmt.Errorf("failed to build wallet for %s: %w", path.String(), err)
+    }
+
+    return w, path, nil
+}
+
 // GetDepositAddress retrieves a user's deposit address for a specific chain
 func (s *DepositService) GetDepositAddress(ctx context.Context, userID uint, chain string) (*wallet.Wallet, error) {
     var w wallet.Wallet
@@ -109,4 +318,63 @@ func (s *DepositService) UpdateDepositAddressBalance(ctx context.Context, wallet
     w.UpdatedAt = time.Now()
     
     return s.db.Save(&w).Error
+}
+
+// UpdateDepositTokenBalance refreshes a deposit address's balance of a token from the blockchain
+func (s *DepositService) UpdateDepositTokenBalance(ctx context.Context, walletID uint, token string) (*wallet.TokenBalance, error) {
+    var w wallet.Wallet
+    if err := s.db.First(&w, walletID).Error; err != nil {
+        return nil, err
+    }
+
+    tokenAdapter, err := s.tokenAdapter(w.Chain)
+    if err != nil {
+        return nil, err
+    }
+
+    meta, err := tokenAdapter.GetTokenMetadata(ctx, token)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get token metadata: %w", err)
+    }
+
+    balance, err := tokenAdapter.GetTokenBalance(ctx, meta.Contract, w.Address)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get token balance: %w", err)
+    }
+
+    tokenBalance := &wallet.TokenBalance{
+        WalletID:      w.ID,
+        TokenContract: meta.Contract,
+        TokenSymbol:   meta.Symbol,
+        Decimals:      meta.Decimals,
+        Balance:       balance,
+    }
+
+    err = s.db.Clauses(clause.OnConflict{
+        Columns:   []clause.Column{{Name: "wallet_id"}, {Name: "token_contract"}},
+        DoUpdates: clause.AssignmentColumns([]string{"token_symbol", "decimals", "balance", "updated_at"}),
+    }).Create(tokenBalance).Error
+    if err != nil {
+        return nil, fmt.Errorf("failed to save token balance: %w", err)
+    }
+
+    return tokenBalance, nil
+}
+
+// tokenAdapter returns the token-capable adapter for a chain
+func (s *DepositService) tokenAdapter(chain string) (blockchain.TokenAdapter, error) {
+    s.mu.RLock()
+    adapter, exists := s.adapters[chain]
+    s.mu.RUnlock()
+
+    if !exists {
+        return nil, fmt.Errorf("unsupported chain: %s", chain)
+    }
+
+    tokenAdapter, ok := adapter.(blockchain.TokenAdapter)
+    if !ok {
+        return nil, fmt.Errorf("tokens are not supported on chain %s", chain)
+    }
+
+    return tokenAdapter, nil
 }
\ No newline at end of file
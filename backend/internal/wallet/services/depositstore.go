@@ -0,0 +1,270 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/wallet"
+    "gorm.io/gorm"
+    "gorm.io/gorm/clause"
+)
+
+// reorgHistory is how many recent block hashes a deposit watcher keeps per chain, which bounds
+// the depth of the reorgs it can roll back
+const reorgHistory = 128
+
+// depositStore persists what a chain's deposit watcher finds: the deposits, the cursor of the
+// last processed block and the recent block hashes used to detect reorgs. Every watcher records
+// deposits through it, so they all feed the same confirmation tracking and crediting.
+type depositStore struct {
+    db    *gorm.DB
+    chain string
+}
+
+// newDepositStore creates the deposit store of a chain
+func newDepositStore(db *gorm.DB, chain string) *depositStore {
+    return &depositStore{db: db, chain: chain}
+}
+
+// loadCursor reads the chain's cursor, returning nil if there is none
+func (s *depositStore) loadCursor(ctx context.Context) (*wallet.BlockCursor, error) {
+    var cursor wallet.BlockCursor
+    err := s.db.WithContext(ctx).Where("chain = ?", s.chain).First(&cursor).Error
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+        return nil, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load block cursor: %w", err)
+    }
+
+    return &cursor, nil
+}
+
+// advance records a processed block in the chain's recent block hashes and moves the cursor to it
+func (s *depositStore) advance(ctx context.Context, height uint64, hash, parent string) error {
+    cursor := &wallet.BlockCursor{
+        Chain:  s.chain,
+        Height: height,
+        Hash:   hash,
+    }
+    block := &wallet.WatchedBlock{
+        Chain:      s.chain,
+        Height:     height,
+        Hash:       hash,
+        ParentHash: parent,
+    }
+
+    err := s.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+        err := db.Clauses(clause.OnConflict{
+            Columns:   []clause.Column{{Name: "chain"}, {Name: "height"}},
+            DoUpdates: clause.AssignmentColumns([]string{"hash", "parent_hash"}),
+        }).Create(block).Error
+        if err != nil {
+            return err
+        }
+
+        // Only the last blocks are needed to find the common ancestor of a reorg
+        if height > reorgHistory {
+            err := db.Where("chain = ? AND height < ?", s.chain, height-reorgHistory).Delete(&wallet.WatchedBlock{}).Error
+            if err != nil {
+                return err
+            }
+        }
+
+        return db.Clauses(clause.OnConflict{
+            Columns:   []clause.Column{{Name: "chain"}},
+            DoUpdates: clause.AssignmentColumns([]string{"height", "hash", "updated_at"}),
+        }).Create(cursor).Error
+    })
+    if err != nil {
+        return fmt.Errorf("failed to save block cursor at %d: %w", height, err)
+    }
+
+    return nil
+}
+
+// blockHash returns the hash of the block the watcher processed at a height, if it still knows it
+func (s *depositStore) blockHash(ctx context.Context, height uint64) (string, bool, error) {
+    var block wallet.WatchedBlock
+    err := s.db.WithContext(ctx).Where("chain = ? AND height = ?", s.chain, height).Limit(1).Find(&block).Error
+    if err != nil {
+        return "", false, fmt.Errorf("failed to load block %d: %w", height, err)
+    }
+    if block.ID == 0 {
+        return "", false, nil
+    }
+
+    return block.Hash, true, nil
+}
+
+// rollback undoes the blocks the watcher processed on a fork that is no longer canonical. Walking
+// back from a height, it compares the stored hashes with the canonical chain until they agree at
+// the common ancestor. Deposits recorded in the orphaned blocks are marked reverted and any credit
+// they received is reversed, the orphaned hashes are forgotten and the cursor is moved back to the
+// common ancestor, whose height is returned.
+func (s *depositStore) rollback(ctx context.Context, from uint64, canonicalHash func(ctx context.Context, height uint64) (string, error)) (uint64, error) {
+    var blocks []wallet.WatchedBlock
+    err := s.db.WithContext(ctx).
+        Where("chain = ? AND height <= ?", s.chain, from).
+        Order("height DESC").
+        Find(&blocks).Error
+    if err != nil {
+        return 0, fmt.Errorf("failed to load recent blocks: %w", err)
+    }
+
+    var ancestor *wallet.WatchedBlock
+    var orphaned []string
+    for i := range blocks {
+        hash, err := canonicalHash(ctx, blocks[i].Height)
+        if err != nil {
+            return 0, fmt.Errorf("failed to get block hash %d: %w", blocks[i].Height, err)
+        }
+        if hash == blocks[i].Hash {
+            ancestor = &blocks[i]
+            break
+        }
+        orphaned = append(orphaned, blocks[i].Hash)
+    }
+    if ancestor == nil {
+        alertf("reorg on %s is deeper than the %d blocks the watcher keeps; replay the affected range with a backfill", s.chain, len(blocks))
+        return 0, fmt.Errorf("no common ancestor within the last %d blocks", len(blocks))
+    }
+
+    log.Printf("Reorg on %s: rolling back %d blocks to common ancestor %d (%s)", s.chain, len(orphaned), ancestor.Height, ancestor.Hash)
+
+    var reverted []wallet.Transaction
+    err = s.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+        err := db.Clauses(clause.Locking{Strength: "UPDATE"}).
+            Where("chain = ? AND type = ? AND block_hash IN ? AND status IN ?", s.chain, "deposit", orphaned, []string{"pending", "confirmed", "final"}).
+            Find(&reverted).Error
+        if err != nil {
+            return err
+        }
+
+        for i := range reverted {
+            if reverted[i].Status == "final" {
+                if err := reverseDeposit(ctx, db, reverted[i], "chain reorganization"); err != nil {
+                    return err
+                }
+            }
+
+            err := db.Model(&reverted[i]).Updates(map[string]interface{}{
+                "status":        "reverted",
+                "confirmations": 0,
+                "updated_at":    time.Now(),
+            }).Error
+            if err != nil {
+                return err
+            }
+        }
+
+        if err := db.Where("chain = ? AND height > ?", s.chain, ancestor.Height).Delete(&wallet.WatchedBlock{}).Error; err != nil {
+            return err
+        }
+
+        return db.Model(&wallet.BlockCursor{}).Where("chain = ?", s.chain).Updates(map[string]interface{}{
+            "height":     ancestor.Height,
+            "hash":       ancestor.Hash,
+            "updated_at": time.Now(),
+        }).Error
+    })
+    if err != nil {
+        return 0, fmt.Errorf("failed to revert orphaned deposits: %w", err)
+    }
+
+    for _, deposit := range reverted {
+        if deposit.Status == "final" {
+            // The credit was already spendable, so the wallet may now be short
+            alertf("final deposit %s of %s to wallet %d on %s was reverted by a reorg", deposit.TxHash, deposit.Amount.String(), deposit.WalletID, s.chain)
+        } else {
+            log.Printf("Deposit %s to wallet %d on %s was reverted by a reorg", deposit.TxHash, deposit.WalletID, s.chain)
+        }
+    }
+
+    return ancestor.Height, nil
+}
+
+// findDeposit looks up a recorded deposit by transaction hash, token, log or output index and
+// trace path, returning nil if there is none
+func (s *depositStore) findDeposit(ctx context.Context, hash, token string, index uint, path string) (*wallet.Transaction, error) {
+    var existing wallet.Transaction
+    err := s.db.WithContext(ctx).
+        Where("chain = ? AND type = ? AND tx_hash = ? AND token_contract = ? AND log_index = ? AND trace_path = ?", s.chain, "deposit", hash, token, index, path).
+        Limit(1).
+        Find(&existing).Error
+    if err != nil {
+        return nil, fmt.Errorf("failed to query transaction: %w", err)
+    }
+    if existing.ID == 0 {
+        return nil, nil
+    }
+
+    return &existing, nil
+}
+
+// depositKey is the unique key of a deposit: one transfer of one transaction. The unique index
+// only covers deposits, so conflicts name its predicate too; it is a literal, as a bound
+// parameter would keep the database from matching the partial index.
+var depositKey = []clause.Column{{Name: "chain"}, {Name: "tx_hash"}, {Name: "token_contract"}, {Name: "log_index"}, {Name: "trace_path"}}
+
+var depositKeyWhere = clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "type = 'deposit'"}}}
+
+// recordDeposit upserts a deposit by chain, transaction hash, token, log index and trace path. A
+// new deposit is inserted; one that a reorg reverted is restored with its new block when it is
+// mined again on the canonical chain, and one first seen in the mempool takes its block; any other
+// existing row is left alone, so replays are harmless. It reports whether anything was written.
+// Crediting happens later, when the confirmation tracker finalizes it.
+func (s *depositStore) recordDeposit(ctx context.Context, transaction *wallet.Transaction) (bool, error) {
+    result := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+        Columns:     depositKey,
+        TargetWhere: depositKeyWhere,
+        Where: clause.Where{Exprs: []clause.Expression{clause.IN{
+            Column: clause.Column{Table: "transactions", Name: "status"},
+            Values: []interface{}{"reverted", "unconfirmed", "dropped"},
+        }}},
+        DoUpdates: clause.Assignments(map[string]interface{}{
+            "status":        transaction.Status,
+            "confirmations": transaction.Confirmations,
+            "block_number":  transaction.BlockNumber,
+            "block_hash":    transaction.BlockHash,
+            "updated_at":    time.Now(),
+        }),
+    }).Create(transaction)
+    if result.Error != nil {
+        return false, fmt.Errorf("failed to save transaction: %w", result.Error)
+    }
+
+    return result.RowsAffected > 0, nil
+}
+
+// recordPending inserts a deposit seen in the mempool as unconfirmed. A deposit that is already
+// recorded is left alone, unless it expired from the mempool and was broadcast again. It reports
+// whether anything was written.
+func (s *depositStore) recordPending(ctx context.Context, transaction *wallet.Transaction) (bool, error) {
+    result := s.db.WithContext(ctx).Clauses(clause.OnConflict{
+        Columns:     depositKey,
+        TargetWhere: depositKeyWhere,
+        Where:       clause.Where{Exprs: []clause.Expression{clause.Eq{Column: clause.Column{Table: "transactions", Name: "status"}, Value: "dropped"}}},
+        DoUpdates: clause.Assignments(map[string]interface{}{
+            "status":     transaction.Status,
+            "updated_at": time.Now(),
+        }),
+    }).Create(transaction)
+    if result.Error != nil {
+        return false, fmt.Errorf("failed to save transaction: %w", result.Error)
+    }
+
+    return result.RowsAffected > 0, nil
+}
+
+// confirmationsAt counts the confirmations of a block at a head height; the block itself is the first
+func confirmationsAt(head, block uint64) int {
+    confirmations := 1
+    if head > block {
+        confirmations += int(head - block)
+    }
+    return confirmations
+}
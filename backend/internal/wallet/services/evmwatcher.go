@@ -0,0 +1,354 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "math/big"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+    "github.com/ethereum/go-ethereum/core/types"
+    "github.com/ethereum/go-ethereum/event"
+)
+
+// evmChainWatcher finds native transfers and deposit token Transfer logs in the blocks of an EVM
+// chain. With tracing configured, native transfers made by contract calls inside a transaction are
+// found too, so deposits from exchanges and smart-contract wallets are not missed.
+type evmChainWatcher struct {
+    client blockchain.EVMBlockClient
+    config blockchain.ChainConfig
+    tokens *blockchain.EVMAdapter // reads metadata of the deposit tokens
+}
+
+// NewEVMChainWatcher creates a chain watcher for an EVM chain reading blocks from a client
+func NewEVMChainWatcher(config blockchain.ChainConfig, client blockchain.EVMBlockClient) ChainWatcher {
+    return &evmChainWatcher{
+        client: client,
+        config: config,
+        tokens: blockchain.NewEVMAdapterWithClient(config, client),
+    }
+}
+
+// Head returns the number of the latest block
+func (w *evmChainWatcher) Head(ctx context.Context) (uint64, error) {
+    header, err := w.client.HeaderByNumber(ctx, nil)
+    if err != nil {
+        return 0, fmt.Errorf("failed to get current block header: %w", err)
+    }
+    return header.Number.Uint64(), nil
+}
+
+// FetchRange returns the blocks from one number to another, inclusive
+func (w *evmChainWatcher) FetchRange(ctx context.Context, from, to uint64) ([]ChainBlock, error) {
+    blocks := make([]ChainBlock, 0, to-from+1)
+    for number := from; number <= to; number++ {
+        block, err := w.client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+        if err != nil {
+            return nil, fmt.Errorf("failed to get block %d: %w", number, err)
+        }
+        blocks = append(blocks, ChainBlock{
+            Height:     number,
+            Hash:       block.Hash().Hex(),
+            ParentHash: block.ParentHash().Hex(),
+            raw:        block,
+        })
+    }
+    return blocks, nil
+}
+
+// BlockHash returns the hash of the canonical block at a number
+func (w *evmChainWatcher) BlockHash(ctx context.Context, height uint64) (string, error) {
+    header, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(height))
+    if err != nil {
+        return "", fmt.Errorf("failed to get block header %d: %w", height, err)
+    }
+    return header.Hash().Hex(), nil
+}
+
+// SubscribeHeads pushes the number of every new block, if one of the chain's endpoints supports
+// subscriptions; otherwise it returns blockchain.ErrSubscriptionsUnsupported
+func (w *evmChainWatcher) SubscribeHeads(ctx context.Context, heads chan<- uint64) (Subscription, error) {
+    subscriber, ok := w.client.(interface {
+        SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+    })
+    if !ok {
+        return nil, blockchain.ErrSubscriptionsUnsupported
+    }
+
+    headers := make(chan *types.Header, headBuffer)
+    sub, err := subscriber.SubscribeNewHead(ctx, headers)
+    if err != nil {
+        return nil, err
+    }
+
+    return event.NewSubscription(func(quit <-chan struct{}) error {
+        defer sub.Unsubscribe()
+        for {
+            select {
+            case header := <-headers:
+                select {
+                case heads <- header.Number.Uint64():
+                case <-quit:
+                    return nil
+                }
+            case err := <-sub.Err():
+                return err
+            case <-quit:
+                return nil
+            }
+        }
+    }), nil
+}
+
+// pendingBuffer is how many streamed pending transactions may wait while a deposit is recorded;
+// busy chains stream dozens a second
+const pendingBuffer = 1024
+
+// pendingSubscriber is a client that can stream the transactions entering the mempool, such as a blockchain.MultiClient
+type pendingSubscriber interface {
+    SubscribePendingTransactions(ctx context.Context, ch chan<- *types.Transaction) (ethereum.Subscription, error)
+}
+
+// blockTracer is a client that can trace the calls of a block, such as a blockchain.MultiClient
+type blockTracer interface {
+    TraceTransfers(ctx context.Context, block *types.Block, tracer string) ([]blockchain.InternalTransfer, error)
+}
+
+// receiptBatcher is a client that reads many receipts in one round trip, such as a blockchain.MultiClient
+type receiptBatcher interface {
+    TransactionReceipts(ctx context.Context, hashes []common.Hash) ([]*types.Receipt, error)
+}
+
+// Transfers returns the successful native transfers and deposit token transfers in a block that
+// pay one of the addresses. It fails if any transaction in the block could not be checked.
+func (w *evmChainWatcher) Transfers(ctx context.Context, block ChainBlock, addresses *AddressIndex) ([]Transfer, error) {
+    raw, ok := block.raw.(*types.Block)
+    if !ok {
+        return nil, fmt.Errorf("block %d was not fetched by the %s watcher", block.Height, w.config.Name)
+    }
+
+    transfers, err := w.nativeTransfers(ctx, raw.Transactions(), addresses)
+    if err != nil {
+        return nil, err
+    }
+
+    if w.config.Tracing != "" {
+        internal, err := w.internalTransfers(ctx, raw, addresses)
+        if err != nil {
+            return nil, err
+        }
+        transfers = append(transfers, internal...)
+    }
+
+    hash := raw.Hash()
+    tokenTransfers, err := w.tokenTransfers(ctx, ethereum.FilterQuery{BlockHash: &hash}, addresses)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get token transfers: %w", err)
+    }
+
+    return append(transfers, tokenTransfers...), nil
+}
+
+// internalTransfers traces a block with the chain's tracer and returns the transfers made by
+// contract calls to one of the addresses, each with the path of the call that made it
+func (w *evmChainWatcher) internalTransfers(ctx context.Context, block *types.Block, addresses *AddressIndex) ([]Transfer, error) {
+    tracer, ok := w.client.(blockTracer)
+    if !ok {
+        return nil, fmt.Errorf("the %s client cannot trace blocks", w.config.Name)
+    }
+
+    traced, err := tracer.TraceTransfers(ctx, block, w.config.Tracing)
+    if err != nil {
+        return nil, fmt.Errorf("failed to trace internal transfers: %w", err)
+    }
+
+    var transfers []Transfer
+    for _, internal := range traced {
+        if !addresses.Contains(internal.To.Hex()) {
+            continue
+        }
+        transfers = append(transfers, Transfer{
+            TxHash:    internal.TxHash.Hex(),
+            From:      internal.From.Hex(),
+            To:        internal.To.Hex(),
+            Amount:    units.NewAmount(internal.Value), // wei
+            Decimals:  w.config.Decimals,
+            TracePath: internal.TracePath,
+        })
+    }
+    return transfers, nil
+}
+
+// nativeTransfers returns the transfers of the transactions that pay one of the addresses and
+// succeeded. Only the receipts of those transactions are read, all in one batch if the client
+// can batch them.
+func (w *evmChainWatcher) nativeTransfers(ctx context.Context, txs types.Transactions, addresses *AddressIndex) ([]Transfer, error) {
+    var paying types.Transactions
+    for _, tx := range txs {
+        // We only care about transactions that have a recipient (not contract creation) and move value
+        if tx.To() == nil || tx.Value().Sign() == 0 || !addresses.Contains(tx.To().Hex()) {
+            continue
+        }
+        paying = append(paying, tx)
+    }
+    if len(paying) == 0 {
+        return nil, nil
+    }
+
+    receipts, err := w.receipts(ctx, paying)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get transaction receipts: %w", err)
+    }
+
+    var transfers []Transfer
+    for i, tx := range paying {
+        // Check if the transaction was successful
+        if receipts[i].Status != types.ReceiptStatusSuccessful {
+            log.Printf("Transaction %s failed, not processing", tx.Hash().Hex())
+            continue
+        }
+
+        transfer := Transfer{
+            TxHash:   tx.Hash().Hex(),
+            To:       tx.To().Hex(),
+            Amount:   units.NewAmount(tx.Value()), // wei
+            Decimals: w.config.Decimals,
+        }
+        if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+            transfer.From = from.Hex()
+        }
+        transfers = append(transfers, transfer)
+    }
+    return transfers, nil
+}
+
+// receipts returns the receipts of transactions, in their order
+func (w *evmChainWatcher) receipts(ctx context.Context, txs types.Transactions) ([]*types.Receipt, error) {
+    hashes := make([]common.Hash, len(txs))
+    for i, tx := range txs {
+        hashes[i] = tx.Hash()
+    }
+
+    if batcher, ok := w.client.(receiptBatcher); ok {
+        return batcher.TransactionReceipts(ctx, hashes)
+    }
+
+    receipts := make([]*types.Receipt, len(hashes))
+    for i, hash := range hashes {
+        receipt, err := w.client.TransactionReceipt(ctx, hash)
+        if err != nil {
+            return nil, fmt.Errorf("transaction %s: %w", hash.Hex(), err)
+        }
+        receipts[i] = receipt
+    }
+    return receipts, nil
+}
+
+// WatchPending reports the native transfers to one of the addresses in transactions entering the
+// mempool, if one of the chain's endpoints can stream them; otherwise it returns
+// blockchain.ErrSubscriptionsUnsupported. Token transfers are only seen once mined, when their log
+// index is known.
+func (w *evmChainWatcher) WatchPending(ctx context.Context, addresses *AddressIndex, found func(Transfer)) error {
+    subscriber, ok := w.client.(pendingSubscriber)
+    if !ok {
+        return blockchain.ErrSubscriptionsUnsupported
+    }
+
+    txs := make(chan *types.Transaction, pendingBuffer)
+    sub, err := subscriber.SubscribePendingTransactions(ctx, txs)
+    if err != nil {
+        return err
+    }
+    defer sub.Unsubscribe()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return ctx.Err()
+        case err := <-sub.Err():
+            if err == nil {
+                err = fmt.Errorf("subscription closed")
+            }
+            return err
+        case tx := <-txs:
+            if tx.To() == nil || tx.Value().Sign() == 0 || !addresses.Contains(tx.To().Hex()) {
+                continue
+            }
+
+            transfer := Transfer{
+                TxHash:   tx.Hash().Hex(),
+                To:       tx.To().Hex(),
+                Amount:   units.NewAmount(tx.Value()), // wei
+                Decimals: w.config.Decimals,
+            }
+            if from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx); err == nil {
+                transfer.From = from.Hex()
+            }
+            found(transfer)
+        }
+    }
+}
+
+// TransactionStatus reports whether the node still knows a transaction, pending or mined, and
+// for a mined one how deep it is and whether its execution failed
+func (w *evmChainWatcher) TransactionStatus(ctx context.Context, hash string) (PendingStatus, error) {
+    txHash := common.HexToHash(hash)
+    _, pending, err := w.client.TransactionByHash(ctx, txHash)
+    if errors.Is(err, ethereum.NotFound) {
+        return PendingStatus{}, nil
+    }
+    if err != nil {
+        return PendingStatus{}, fmt.Errorf("failed to get transaction %s: %w", hash, err)
+    }
+    if pending {
+        return PendingStatus{Known: true}, nil
+    }
+
+    receipt, err := w.client.TransactionReceipt(ctx, txHash)
+    if errors.Is(err, ethereum.NotFound) {
+        // Mined, but the node has not indexed the receipt yet
+        return PendingStatus{Known: true}, nil
+    }
+    if err != nil {
+        return PendingStatus{}, fmt.Errorf("failed to get receipt of %s: %w", hash, err)
+    }
+    head, err := w.Head(ctx)
+    if err != nil {
+        return PendingStatus{}, err
+    }
+
+    return PendingStatus{
+        Known:         true,
+        Confirmations: confirmationsAt(head, receipt.BlockNumber.Uint64()),
+        Failed:        receipt.Status == types.ReceiptStatusFailed,
+    }, nil
+}
+
+// connectToTestnet connects the watcher to an Ethereum testnet
+func (w *evmChainWatcher) connectToTestnet(network string) error {
+    var rpcURL string
+    switch network {
+    case "goerli":
+        rpcURL = "https://goerli.infura.io/v3/YOUR_INFURA_PROJECT_ID"
+    case "sepolia":
+        rpcURL = "https://sepolia.infura.io/v3/YOUR_INFURA_PROJECT_ID"
+    case "rinkeby":
+        rpcURL = "https://rinkeby.infura.io/v3/YOUR_INFURA_PROJECT_ID"
+    default:
+        return fmt.Errorf("unsupported testnet: %s", network)
+    }
+
+    client, err := blockchain.DialMultiClient(blockchain.ChainConfig{Name: network, RPCURLs: []string{rpcURL}})
+    if err != nil {
+        return fmt.Errorf("failed to connect to %s testnet: %w", network, err)
+    }
+
+    w.client = client
+    w.tokens = blockchain.NewEVMAdapterWithClient(w.config, client)
+
+    return nil
+}
@@ -0,0 +1,329 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/wallet"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "gorm.io/gorm"
+)
+
+// defaultExpireAfter is how long an unconfirmed deposit may go unrecorded before the monitor
+// checks where its transaction stands, and expires it if it was dropped or mined without reaching us
+const defaultExpireAfter = time.Hour
+
+// ErrMempoolUnsupported is returned for chains whose watcher cannot read pending transactions
+var ErrMempoolUnsupported = errors.New("mempool monitoring is not supported")
+
+// MempoolWatcher is implemented by chain watchers that can see transactions before they are mined
+type MempoolWatcher interface {
+    // WatchPending calls found with every transfer to one of the addresses in transactions
+    // entering the mempool, until the context is cancelled or the stream fails
+    WatchPending(ctx context.Context, addresses *AddressIndex, found func(Transfer)) error
+    // TransactionStatus reports whether a transaction is still in the mempool or was mined, and
+    // if it was mined how deep it is and whether it failed; one that is neither was dropped
+    TransactionStatus(ctx context.Context, hash string) (PendingStatus, error)
+}
+
+// PendingStatus is what a node knows of the transaction of an unconfirmed deposit
+type PendingStatus struct {
+    Known         bool // in the mempool, or mined
+    Confirmations int  // confirmations of the block it was mined in, zero while it is in the mempool
+    Failed        bool // mined, but its execution failed, so nothing was transferred
+}
+
+// IncomingDeposit is emitted when a deposit is first seen in the mempool, before it is mined
+type IncomingDeposit struct {
+    Transaction wallet.Transaction
+    UserID      uint
+    Symbol      string
+}
+
+// MempoolMonitor watches a chain's mempool for transfers to our deposit addresses, so users can be
+// told a deposit is on its way before it is mined. Each one is recorded as an unconfirmed deposit,
+// which is never credited: the BlockWatcher takes the row over once the transaction is mined, and
+// the ConfirmationTracker credits it at finality. Unconfirmed deposits whose transaction leaves the
+// mempool without being mined, or is mined and sinks past the confirmation window without the
+// block watcher recording it, expire to dropped; those whose transaction is mined but fails are
+// failed.
+type MempoolMonitor struct {
+    db           *gorm.DB
+    store        *depositStore
+    watcher      MempoolWatcher
+    addresses    *AddressIndex
+    chain        string
+    config       blockchain.ChainConfig
+    pollInterval time.Duration
+    expireAfter  time.Duration
+    subscribers  []func(IncomingDeposit)
+    mu           sync.RWMutex
+    running      bool
+}
+
+// NewMempoolMonitor creates a mempool monitor for a chain watcher that can read pending
+// transactions. The poll interval is how often new addresses are loaded and unconfirmed deposits
+// are checked for expiry.
+func NewMempoolMonitor(db *gorm.DB, config blockchain.ChainConfig, watcher ChainWatcher, pollInterval time.Duration) (*MempoolMonitor, error) {
+    mempool, ok := watcher.(MempoolWatcher)
+    if !ok {
+        return nil, fmt.Errorf("chain %s: %w", config.Name, ErrMempoolUnsupported)
+    }
+
+    return &MempoolMonitor{
+        db:           db,
+        store:        newDepositStore(db, config.Name),
+        watcher:      mempool,
+        addresses:    NewAddressIndex(db, config.Name),
+        chain:        config.Name,
+        config:       config,
+        pollInterval: pollInterval,
+        expireAfter:  defaultExpireAfter,
+        mu:           sync.RWMutex{},
+    }, nil
+}
+
+// SetExpireAfter changes how long an unconfirmed deposit may go unmined before it can expire
+func (m *MempoolMonitor) SetExpireAfter(d time.Duration) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.expireAfter = d
+}
+
+// Subscribe registers a handler that is called after every incoming deposit is saved, to notify
+// its user. Handlers run on the monitor's goroutine and should return quickly.
+func (m *MempoolMonitor) Subscribe(handler func(IncomingDeposit)) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.subscribers = append(m.subscribers, handler)
+}
+
+// AddAddress starts watching a deposit address as soon as it is created, before the next refresh
+// of the address index; see BlockWatcher.AddAddress
+func (m *MempoolMonitor) AddAddress(w *wallet.Wallet) {
+    m.addresses.Add(w)
+}
+
+// Start watches the mempool until the context is cancelled. A failed stream is restarted with
+// backoff; transactions that enter the mempool while it is down are only seen once mined.
+func (m *MempoolMonitor) Start(ctx context.Context) error {
+    m.mu.Lock()
+    if m.running {
+        m.mu.Unlock()
+        return fmt.Errorf("mempool monitor is already running")
+    }
+    m.running = true
+    m.mu.Unlock()
+
+    defer func() {
+        m.mu.Lock()
+        m.running = false
+        m.mu.Unlock()
+    }()
+
+    if err := m.addresses.Refresh(ctx); err != nil {
+        return err
+    }
+
+    ctx, cancel := context.WithCancel(ctx)
+    var wg sync.WaitGroup
+    wg.Add(1)
+    go func() {
+        defer wg.Done()
+        m.maintain(ctx)
+    }()
+    defer func() {
+        cancel()
+        wg.Wait()
+    }()
+
+    delay := minResubscribeDelay
+    for {
+        started := time.Now()
+        err := m.watcher.WatchPending(ctx, m.addresses, func(transfer Transfer) {
+            m.record(ctx, transfer)
+        })
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+        if errors.Is(err, ErrMempoolUnsupported) || errors.Is(err, blockchain.ErrSubscriptionsUnsupported) {
+            return fmt.Errorf("chain %s: %w", m.chain, err)
+        }
+
+        if time.Since(started) > maxResubscribeDelay {
+            // The stream worked for a while before it failed, so it is restarted promptly
+            delay = minResubscribeDelay
+        }
+        log.Printf("%s mempool stream failed, restarting in %s: %v", m.chain, delay, err)
+
+        select {
+        case <-ctx.Done():
+            return ctx.Err()
+        case <-time.After(delay):
+        }
+        if delay *= 2; delay > maxResubscribeDelay {
+            delay = maxResubscribeDelay
+        }
+    }
+}
+
+// IsRunning returns whether the monitor is currently running
+func (m *MempoolMonitor) IsRunning() bool {
+    m.mu.RLock()
+    defer m.mu.RUnlock()
+    return m.running
+}
+
+// maintain loads new addresses and expires dropped deposits every poll interval
+func (m *MempoolMonitor) maintain(ctx context.Context) {
+    ticker := time.NewTicker(m.pollInterval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-ticker.C:
+            if err := m.addresses.Refresh(ctx); err != nil {
+                log.Printf("Error refreshing %s deposit addresses: %v", m.chain, err)
+            }
+            if err := m.Expire(ctx); err != nil {
+                log.Printf("Error expiring %s unconfirmed deposits: %v", m.chain, err)
+            }
+        }
+    }
+}
+
+// record saves a transfer seen in the mempool as an unconfirmed deposit and emits it if it is new
+func (m *MempoolMonitor) record(ctx context.Context, transfer Transfer) {
+    walletID, ok := m.addresses.WalletID(transfer.To)
+    if !ok {
+        return
+    }
+
+    transaction := &wallet.Transaction{
+        WalletID:      walletID,
+        TxHash:        transfer.TxHash,
+        FromAddress:   transfer.From,
+        ToAddress:     transfer.To,
+        Amount:        transfer.Amount,
+        Decimals:      transfer.Decimals,
+        Chain:         m.chain,
+        TokenContract: transfer.TokenContract,
+        TokenSymbol:   transfer.TokenSymbol,
+        LogIndex:      transfer.Index,
+        ScriptType:    transfer.ScriptType,
+        TracePath:     transfer.TracePath,
+        Type:          "deposit",
+        Status:        "unconfirmed",
+    }
+
+    saved, err := m.store.recordPending(ctx, transaction)
+    if err != nil {
+        log.Printf("Error recording unconfirmed deposit %s/%d: %v", transfer.TxHash, transfer.Index, err)
+        return
+    }
+    if !saved {
+        // Already recorded, or mined before the mempool stream saw it
+        return
+    }
+
+    var w wallet.Wallet
+    if err := m.db.WithContext(ctx).Select("id", "user_id").First(&w, walletID).Error; err != nil {
+        log.Printf("Error loading wallet %d of unconfirmed deposit %s: %v", walletID, transfer.TxHash, err)
+        return
+    }
+
+    symbol := transfer.TokenSymbol
+    if transfer.TokenContract == "" {
+        symbol = m.config.NativeSymbol
+    }
+    log.Printf("Incoming deposit %s/%d of %s %s to wallet %d", transfer.TxHash, transfer.Index, transfer.Amount.Format(transfer.Decimals), symbol, walletID)
+
+    m.emit(IncomingDeposit{Transaction: *transaction, UserID: w.UserID, Symbol: symbol})
+}
+
+// Expire settles the unconfirmed deposits that have waited longer than the expiry delay: those
+// whose transaction left the mempool without being mined, or was mined more than the confirmation
+// window ago and still not recorded by the block watcher, are dropped, and those whose
+// transaction was mined but failed are failed. The others, still in the mempool or mined
+// recently, wait another delay before they are checked again.
+func (m *MempoolMonitor) Expire(ctx context.Context) error {
+    m.mu.RLock()
+    expireAfter := m.expireAfter
+    m.mu.RUnlock()
+
+    var stale []wallet.Transaction
+    err := m.db.WithContext(ctx).
+        Where("chain = ? AND type = ? AND status = ? AND updated_at < ?", m.chain, "deposit", "unconfirmed", time.Now().Add(-expireAfter)).
+        Order("id").
+        Find(&stale).Error
+    if err != nil {
+        return fmt.Errorf("failed to load unconfirmed deposits: %w", err)
+    }
+
+    // Outputs of one Bitcoin transaction are separate deposits, so each transaction is checked once
+    statuses := make(map[string]PendingStatus)
+    for i := range stale {
+        transaction := &stale[i]
+        if ctx.Err() != nil {
+            return ctx.Err()
+        }
+
+        status, checked := statuses[transaction.TxHash]
+        if !checked {
+            var err error
+            if status, err = m.watcher.TransactionStatus(ctx, transaction.TxHash); err != nil {
+                log.Printf("Error checking unconfirmed deposit %s on %s: %v", transaction.TxHash, m.chain, err)
+                continue
+            }
+            statuses[transaction.TxHash] = status
+        }
+
+        var reason string
+        switch {
+        case !status.Known:
+            transaction.Status = "dropped"
+            reason = "left the mempool"
+        case status.Failed:
+            transaction.Status = "failed"
+            reason = "was mined but failed"
+        case status.Confirmations > m.config.Confirmations:
+            // The block watcher has passed its block without recording it; if it does record it
+            // later, the dropped row is restored
+            transaction.Status = "dropped"
+            reason = fmt.Sprintf("was mined %d blocks ago but never recorded", status.Confirmations)
+        }
+        // Otherwise it is still waiting, or mined and about to be recorded by the block watcher
+        transaction.UpdatedAt = time.Now()
+
+        err := updateStatus(m.db.WithContext(ctx), transaction, "unconfirmed")
+        if errors.Is(err, errTransactionChanged) {
+            // The block watcher recorded it in the meantime
+            continue
+        }
+        if err != nil {
+            return fmt.Errorf("failed to update unconfirmed deposit %s: %w", transaction.TxHash, err)
+        }
+        if reason != "" {
+            log.Printf("Unconfirmed deposit %s/%d on %s %s and is now %s", transaction.TxHash, transaction.LogIndex, m.chain, reason, transaction.Status)
+        }
+    }
+
+    return nil
+}
+
+// emit calls every subscriber with an incoming deposit
+func (m *MempoolMonitor) emit(deposit IncomingDeposit) {
+    m.mu.RLock()
+    subscribers := m.subscribers
+    m.mu.RUnlock()
+
+    for _, handler := range subscribers {
+        handler(deposit)
+    }
+}
@@ -0,0 +1,531 @@
+package services
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "testing"
+    "time"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain/simulator"
+    "gorm.io/driver/sqlite"
+    "gorm.io/gorm"
+    "gorm.io/gorm/logger"
+)
+
+// simulation is a simulated chain with the deposit pipeline running on it
+type simulation struct {
+    db       *gorm.DB
+    chain    *simulator.Chain
+    deposits *DepositService
+    watcher  *BlockWatcher
+    tracker  *ConfirmationTracker
+}
+
+// newSimulation creates a simulated Ethereum with an in-memory database, a deposit service
+// whose new addresses are watched right away, a block watcher and a confirmation tracker
+func newSimulation(t *testing.T) *simulation {
+    t.Helper()
+
+    db, err := gorm.Open(sqlite.Open(fmt.Sprintf("file:%s?mode=memory&cache=shared", t.Name())), &gorm.Config{
+        Logger: logger.Default.LogMode(logger.Silent),
+    })
+    if err != nil {
+        t.Fatalf("failed to open database: %v", err)
+    }
+    sqlDB, err := db.DB()
+    if err != nil {
+        t.Fatalf("failed to open database: %v", err)
+    }
+    sqlDB.SetMaxOpenConns(1)
+    t.Cleanup(func() { sqlDB.Close() })
+
+    for _, model := range []interface{}{&wallet.Wallet{}, &wallet.Transaction{}, &wallet.BlockCursor{}, &wallet.WatchedBlock{}} {
+        if err := db.AutoMigrate(model); err != nil {
+            t.Fatalf("failed to migrate %T: %v", model, err)
+        }
+    }
+
+    config, err := blockchain.Chains().Lookup("ethereum")
+    if err != nil {
+        t.Fatal(err)
+    }
+    chain, err := simulator.NewChain(config)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    adapters := map[string]blockchain.Adapter{config.Name: chain}
+    s := &simulation{
+        db:       db,
+        chain:    chain,
+        deposits: NewDepositService(db, adapters),
+        watcher:  NewBlockWatcherWithChain(db, config, NewSimulatorChainWatcher(chain), time.Second),
+        tracker:  NewConfirmationTracker(db, adapters, time.Second),
+    }
+    s.deposits.OnAddressCreated(s.watcher.AddAddress)
+    s.tracker.UseBlockWatcher(s.watcher)
+
+    // The watcher starts at the genesis block
+    if err := s.watcher.Poll(context.Background()); err != nil {
+        t.Fatalf("failed to start the block watcher: %v", err)
+    }
+    return s
+}
+
+// depositAddress generates a deposit address for user 1
+func (s *simulation) depositAddress(t *testing.T) *wallet.Wallet {
+    t.Helper()
+
+    w, err := s.deposits.GenerateDepositAddress(context.Background(), 1, "ethereum")
+    if err != nil {
+        t.Fatalf("failed to generate deposit address: %v", err)
+    }
+    return w
+}
+
+// deposit loads the deposit recorded for a transaction
+func (s *simulation) deposit(t *testing.T, hash string) wallet.Transaction {
+    t.Helper()
+
+    var deposit wallet.Transaction
+    if err := s.db.Where("tx_hash = ? AND type = ?", hash, "deposit").First(&deposit).Error; err != nil {
+        t.Fatalf("failed to load deposit %s: %v", hash, err)
+    }
+    return deposit
+}
+
+// cursor loads the block watcher's cursor
+func (s *simulation) cursor(t *testing.T) *wallet.BlockCursor {
+    t.Helper()
+
+    cursor, err := s.watcher.Cursor(context.Background())
+    if err != nil || cursor == nil {
+        t.Fatalf("failed to load the block cursor: %v", err)
+    }
+    return cursor
+}
+
+func TestBlockWatcherRecordsDeposit(t *testing.T) {
+    ctx := context.Background()
+    s := newSimulation(t)
+    w := s.depositAddress(t)
+
+    hash, err := s.chain.Fund(w.Address, units.FromInt64(5000))
+    if err != nil {
+        t.Fatal(err)
+    }
+    block := s.chain.MineBlock()
+
+    if err := s.watcher.Poll(ctx); err != nil {
+        t.Fatalf("Poll() error = %v", err)
+    }
+
+    deposit := s.deposit(t, hash)
+    if deposit.Status != "confirmed" || deposit.WalletID != w.ID || deposit.Amount.String() != "5000" {
+        t.Errorf("deposit = %s to wallet %d of %s, want confirmed to wallet %d of 5000", deposit.Status, deposit.WalletID, deposit.Amount, w.ID)
+    }
+    if deposit.BlockNumber != block.Number || deposit.BlockHash != block.Hash {
+        t.Errorf("deposit block = %d %s, want %d %s", deposit.BlockNumber, deposit.BlockHash, block.Number, block.Hash)
+    }
+}
+
+func TestBlockWatcherRollsBackReorgedDeposit(t *testing.T) {
+    ctx := context.Background()
+    s := newSimulation(t)
+    w := s.depositAddress(t)
+
+    hash, err := s.chain.Fund(w.Address, units.FromInt64(5000))
+    if err != nil {
+        t.Fatal(err)
+    }
+    s.chain.MineBlock()
+    if err := s.watcher.Poll(ctx); err != nil {
+        t.Fatalf("Poll() error = %v", err)
+    }
+
+    // The deposit is double-spent away on the new fork
+    if _, err := s.chain.Reorg(1, hash); err != nil {
+        t.Fatal(err)
+    }
+    if err := s.watcher.Poll(ctx); err != nil {
+        t.Fatalf("Poll() after reorg error = %v", err)
+    }
+
+    if deposit := s.deposit(t, hash); deposit.Status != "reverted" || deposit.Confirmations != 0 {
+        t.Errorf("deposit = %s with %d confirmations, want reverted with 0", deposit.Status, deposit.Confirmations)
+    }
+
+    head, err := s.chain.BlockByNumber(s.chain.Head())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if cursor := s.cursor(t); cursor.Height != head.Number || cursor.Hash != head.Hash {
+        t.Errorf("cursor = %d %s, want the new head %d %s", cursor.Height, cursor.Hash, head.Number, head.Hash)
+    }
+}
+
+func TestBlockWatcherRestoresDepositMinedAgainAfterReorg(t *testing.T) {
+    ctx := context.Background()
+    s := newSimulation(t)
+    w := s.depositAddress(t)
+
+    hash, err := s.chain.Fund(w.Address, units.FromInt64(5000))
+    if err != nil {
+        t.Fatal(err)
+    }
+    orphaned := s.chain.MineBlock()
+    if err := s.watcher.Poll(ctx); err != nil {
+        t.Fatalf("Poll() error = %v", err)
+    }
+
+    // The deposit is mined again in the first block of the new fork
+    if _, err := s.chain.Reorg(1); err != nil {
+        t.Fatal(err)
+    }
+    if err := s.watcher.Poll(ctx); err != nil {
+        t.Fatalf("Poll() after reorg error = %v", err)
+    }
+
+    canonical, err := s.chain.BlockByNumber(orphaned.Number)
+    if err != nil {
+        t.Fatal(err)
+    }
+    deposit := s.deposit(t, hash)
+    if deposit.Status != "confirmed" || deposit.BlockHash != canonical.Hash {
+        t.Errorf("deposit = %s in block %s, want confirmed in the canonical block %s", deposit.Status, deposit.BlockHash, canonical.Hash)
+    }
+}
+
+func TestBlockWatcherRetriesBlockAfterRPCFailure(t *testing.T) {
+    ctx := context.Background()
+    s := newSimulation(t)
+    w := s.depositAddress(t)
+
+    hash, err := s.chain.Fund(w.Address, units.FromInt64(5000))
+    if err != nil {
+        t.Fatal(err)
+    }
+    s.chain.MineBlock()
+
+    s.chain.FailNext("HeadBlock", 1, nil)
+    if err := s.watcher.Poll(ctx); !errors.Is(err, blockchain.ErrChainUnavailable) {
+        t.Fatalf("Poll() with a failed head read error = %v, want ErrChainUnavailable", err)
+    }
+
+    s.chain.FailNext("BlockByNumber", 1, nil)
+    if err := s.watcher.Poll(ctx); !errors.Is(err, blockchain.ErrChainUnavailable) {
+        t.Fatalf("Poll() with a failed block read error = %v, want ErrChainUnavailable", err)
+    }
+    if cursor := s.cursor(t); cursor.Height != 0 {
+        t.Fatalf("cursor moved to %d past a block that was never read", cursor.Height)
+    }
+    var count int64
+    s.db.Model(&wallet.Transaction{}).Count(&count)
+    if count != 0 {
+        t.Fatalf("%d deposits recorded from a block that was never read", count)
+    }
+
+    // The next poll reads the block it missed
+    if err := s.watcher.Poll(ctx); err != nil {
+        t.Fatalf("Poll() after the failure error = %v", err)
+    }
+    if deposit := s.deposit(t, hash); deposit.Status != "confirmed" {
+        t.Errorf("deposit = %s, want confirmed", deposit.Status)
+    }
+    if cursor := s.cursor(t); cursor.Height != 1 {
+        t.Errorf("cursor = %d, want 1", cursor.Height)
+    }
+}
+
+func TestDepositServiceReportsRPCFailure(t *testing.T) {
+    s := newSimulation(t)
+
+    s.chain.FailNext("CreateWallet", 1, nil)
+    if _, err := s.deposits.GenerateDepositAddress(context.Background(), 1, "ethereum"); !errors.Is(err, blockchain.ErrChainUnavailable) {
+        t.Fatalf("GenerateDepositAddress() error = %v, want ErrChainUnavailable", err)
+    }
+    if n := s.watcher.Metrics().Addresses; n != 0 {
+        t.Errorf("%d addresses watched after a failed generation, want 0", n)
+    }
+
+    w := s.depositAddress(t)
+    if !s.watcher.addresses.Contains(w.Address) {
+        t.Errorf("new deposit address %s is not watched", w.Address)
+    }
+}
+
+func TestConfirmationTrackerHoldsDepositTheChainDoesNotShow(t *testing.T) {
+    ctx := context.Background()
+    s := newSimulation(t)
+    w := s.depositAddress(t)
+
+    hash, err := s.chain.Fund(w.Address, units.FromInt64(5000))
+    if err != nil {
+        t.Fatal(err)
+    }
+    s.chain.MineBlock()
+    if err := s.watcher.Poll(ctx); err != nil {
+        t.Fatalf("Poll() error = %v", err)
+    }
+
+    // The recorded amount no longer matches what the transaction paid
+    recorded := s.deposit(t, hash)
+    if err := s.db.Model(&recorded).Update("amount", units.FromInt64(5000000)).Error; err != nil {
+        t.Fatal(err)
+    }
+
+    config := s.chain.Config()
+    for i := 0; i < config.Confirmations; i++ {
+        s.chain.MineBlock()
+    }
+    if err := s.tracker.Poll(ctx); err != nil {
+        t.Fatalf("Poll() error = %v", err)
+    }
+
+    if deposit := s.deposit(t, hash); deposit.Status != "needs_review" {
+        t.Errorf("deposit = %s, want needs_review", deposit.Status)
+    }
+    var saved wallet.Wallet
+    if err := s.db.First(&saved, w.ID).Error; err != nil {
+        t.Fatal(err)
+    }
+    if saved.Balance.Sign() != 0 {
+        t.Errorf("wallet balance = %s, want nothing credited", saved.Balance)
+    }
+}
+
+func TestConfirmationTrackerDropsTransactionEvictedFromMempool(t *testing.T) {
+    ctx := context.Background()
+    s := newSimulation(t)
+    s.tracker.SetDropAfter(0)
+
+    sender, err := s.chain.CreateWallet(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    recipient, err := s.chain.CreateWallet(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if _, err := s.chain.Fund(sender.Address, units.FromInt64(100000)); err != nil {
+        t.Fatal(err)
+    }
+    s.chain.MineBlock()
+
+    sent, err := s.chain.SendTransaction(ctx, sender.Address, recipient.Address, units.FromInt64(5000), sender.PrivateKey, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    withdrawal := &wallet.Transaction{
+        TxHash:      sent.Hash,
+        FromAddress: sender.Address,
+        ToAddress:   recipient.Address,
+        Amount:      units.FromInt64(5000),
+        Chain:       "ethereum",
+        Type:        "withdrawal",
+        Status:      "pending",
+    }
+    if err := s.db.Create(withdrawal).Error; err != nil {
+        t.Fatal(err)
+    }
+
+    var changes []StatusChange
+    s.tracker.Subscribe(func(change StatusChange) { changes = append(changes, change) })
+
+    // Still in the mempool, so nothing changes
+    if err := s.tracker.Poll(ctx); err != nil {
+        t.Fatalf("Poll() error = %v", err)
+    }
+    if len(changes) != 0 {
+        t.Fatalf("status changed to %s while the transaction was in the mempool", changes[0].To)
+    }
+
+    if err := s.chain.Drop(sent.Hash); err != nil {
+        t.Fatal(err)
+    }
+    if err := s.tracker.Poll(ctx); err != nil {
+        t.Fatalf("Poll() after the drop error = %v", err)
+    }
+
+    var saved wallet.Transaction
+    if err := s.db.First(&saved, withdrawal.ID).Error; err != nil {
+        t.Fatal(err)
+    }
+    if saved.Status != "dropped" {
+        t.Errorf("withdrawal = %s, want dropped", saved.Status)
+    }
+    if len(changes) != 1 || changes[0].From != "pending" || changes[0].To != "dropped" {
+        t.Errorf("status changes = %+v, want one from pending to dropped", changes)
+    }
+}
+
+// hotWallet stores a funded private wallet of user 1 that signs its withdrawals
+func (s *simulation) hotWallet(t *testing.T, funds units.Amount) *wallet.Wallet {
+    t.Helper()
+
+    key, err := s.chain.CreateWallet(context.Background())
+    if err != nil {
+        t.Fatal(err)
+    }
+    w := &wallet.Wallet{
+        UserID:     1,
+        Address:    key.Address,
+        Chain:      "ethereum",
+        PublicKey:  key.PublicKey,
+        PrivateKey: key.PrivateKey,
+        Type:       "private",
+        IsActive:   true,
+    }
+    if err := s.db.Create(w).Error; err != nil {
+        t.Fatalf("failed to save hot wallet: %v", err)
+    }
+    if _, err := s.chain.Fund(w.Address, funds); err != nil {
+        t.Fatal(err)
+    }
+    s.chain.MineBlock()
+    return w
+}
+
+// withdrawals creates a withdrawal service signing with the simulated chain
+func (s *simulation) withdrawals() *WithdrawalService {
+    return NewWithdrawalService(s.db, map[string]blockchain.Adapter{"ethereum": s.chain}, nil)
+}
+
+// withdrawal loads a withdrawal by ID
+func (s *simulation) withdrawal(t *testing.T, id uint) wallet.Transaction {
+    t.Helper()
+
+    var withdrawal wallet.Transaction
+    if err := s.db.First(&withdrawal, id).Error; err != nil {
+        t.Fatalf("failed to load withdrawal %d: %v", id, err)
+    }
+    return withdrawal
+}
+
+func TestWithdrawalServiceSendsWithdrawal(t *testing.T) {
+    ctx := context.Background()
+    s := newSimulation(t)
+    hot := s.hotWallet(t, units.FromInt64(100000))
+    recipient, err := s.chain.CreateWallet(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    ws := s.withdrawals()
+
+    requested, err := ws.RequestWithdrawal(ctx, 1, "ethereum", recipient.Address, units.FromInt64(5000), blockchain.FeeStandard, false)
+    if err != nil {
+        t.Fatalf("RequestWithdrawal() error = %v", err)
+    }
+    if requested.Status != "pending" || requested.TxHash != "" {
+        t.Fatalf("requested withdrawal = %s %q, want pending and not broadcast", requested.Status, requested.TxHash)
+    }
+
+    if err := ws.ProcessWithdrawal(ctx, requested.ID); err != nil {
+        t.Fatalf("ProcessWithdrawal() error = %v", err)
+    }
+    sent := s.withdrawal(t, requested.ID)
+    if sent.TxHash == "" || sent.Status != "pending" {
+        t.Fatalf("sent withdrawal = %s %q, want pending with a hash", sent.Status, sent.TxHash)
+    }
+    if sent.FromAddress != hot.Address || sent.FeeTier != string(blockchain.FeeStandard) || sent.Fee.String() != "2000" {
+        t.Errorf("sent withdrawal from %s at %s for %s, want from %s at standard for 2000", sent.FromAddress, sent.FeeTier, sent.Fee, hot.Address)
+    }
+
+    // A withdrawal is only ever sent once
+    if err := ws.ProcessWithdrawal(ctx, requested.ID); err == nil {
+        t.Errorf("second ProcessWithdrawal() succeeded")
+    }
+    if pending := s.chain.Pending(); len(pending) != 1 {
+        t.Errorf("%d transactions pending, want 1", len(pending))
+    }
+
+    s.chain.MineBlock()
+    balance, err := s.chain.GetBalance(ctx, recipient.Address)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if balance.String() != "5000" {
+        t.Errorf("recipient balance = %s, want 5000", balance)
+    }
+}
+
+func TestWithdrawalServiceRejectsInvalidRequests(t *testing.T) {
+    ctx := context.Background()
+    s := newSimulation(t)
+    s.hotWallet(t, units.FromInt64(100000))
+    recipient, err := s.chain.CreateWallet(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    ws := s.withdrawals()
+
+    tests := []struct {
+        name   string
+        chain  string
+        to     string
+        amount units.Amount
+        tier   blockchain.FeeTier
+    }{
+        {"unknown chain", "dogecoin", recipient.Address, units.FromInt64(1), blockchain.FeeStandard},
+        {"invalid address", "ethereum", "not-an-address", units.FromInt64(1), blockchain.FeeStandard},
+        {"zero amount", "ethereum", recipient.Address, units.Amount{}, blockchain.FeeStandard},
+        {"unknown tier", "ethereum", recipient.Address, units.FromInt64(1), blockchain.FeeTier("instant")},
+    }
+    for _, tt := range tests {
+        if _, err := ws.RequestWithdrawal(ctx, 1, tt.chain, tt.to, tt.amount, tt.tier, false); err == nil {
+            t.Errorf("%s: RequestWithdrawal() succeeded", tt.name)
+        }
+    }
+
+    var count int64
+    if err := s.db.Model(&wallet.Transaction{}).Where("type = ?", "withdrawal").Count(&count).Error; err != nil {
+        t.Fatal(err)
+    }
+    if count != 0 {
+        t.Errorf("%d withdrawals recorded, want none", count)
+    }
+}
+
+func TestWithdrawalServiceSpeedsUpWithdrawal(t *testing.T) {
+    ctx := context.Background()
+    s := newSimulation(t)
+    s.hotWallet(t, units.FromInt64(100000))
+    recipient, err := s.chain.CreateWallet(ctx)
+    if err != nil {
+        t.Fatal(err)
+    }
+    ws := s.withdrawals()
+
+    requested, err := ws.RequestWithdrawal(ctx, 1, "ethereum", recipient.Address, units.FromInt64(5000), blockchain.FeeSlow, false)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := ws.ProcessWithdrawal(ctx, requested.ID); err != nil {
+        t.Fatal(err)
+    }
+    original := s.withdrawal(t, requested.ID)
+
+    replacement, err := ws.SpeedUpWithdrawal(ctx, requested.ID, blockchain.FeeFast)
+    if err != nil {
+        t.Fatalf("SpeedUpWithdrawal() error = %v", err)
+    }
+    if replacement.Status != "pending" || replacement.ReplacesTxHash != original.TxHash || replacement.Fee.String() != "3000" {
+        t.Errorf("replacement = %s replacing %q for %s, want pending replacing %s for 3000", replacement.Status, replacement.ReplacesTxHash, replacement.Fee, original.TxHash)
+    }
+    replaced := s.withdrawal(t, requested.ID)
+    if replaced.Status != "replaced" || replaced.ReplacedByTxHash != replacement.TxHash {
+        t.Errorf("original = %s replaced by %q, want replaced by %s", replaced.Status, replaced.ReplacedByTxHash, replacement.TxHash)
+    }
+
+    // The original can only be replaced once
+    if _, err := ws.SpeedUpWithdrawal(ctx, requested.ID, blockchain.FeeFast); err == nil {
+        t.Errorf("second SpeedUpWithdrawal() succeeded")
+    }
+    if pending := s.chain.Pending(); len(pending) != 1 || pending[0].Hash != replacement.TxHash {
+        t.Errorf("pending transactions = %d, want only the replacement", len(pending))
+    }
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain/simulator"
+)
+
+// simulatorChainWatcher finds the transfers to our addresses in the blocks of a simulated chain,
+// so the block watcher, and the deposits it records, can be exercised without a network. Reads
+// fail with the failures injected in the simulator for "HeadBlock" and "BlockByNumber".
+type simulatorChainWatcher struct {
+    chain *simulator.Chain
+}
+
+// NewSimulatorChainWatcher creates a chain watcher for a simulated chain
+func NewSimulatorChainWatcher(chain *simulator.Chain) ChainWatcher {
+    return &simulatorChainWatcher{chain: chain}
+}
+
+// Head returns the number of the latest block
+func (w *simulatorChainWatcher) Head(ctx context.Context) (uint64, error) {
+    return w.chain.HeadBlock(ctx)
+}
+
+// FetchRange returns the blocks from one number to another, inclusive
+func (w *simulatorChainWatcher) FetchRange(ctx context.Context, from, to uint64) ([]ChainBlock, error) {
+    result := make([]ChainBlock, 0, to-from+1)
+    for height := from; height <= to; height++ {
+        block, err := w.chain.BlockByNumber(height)
+        if err != nil {
+            return nil, err
+        }
+        result = append(result, ChainBlock{
+            Height:     block.Number,
+            Hash:       block.Hash,
+            ParentHash: block.ParentHash,
+            raw:        block,
+        })
+    }
+    return result, nil
+}
+
+// BlockHash returns the hash of the block at a number
+func (w *simulatorChainWatcher) BlockHash(ctx context.Context, height uint64) (string, error) {
+    block, err := w.chain.BlockByNumber(height)
+    if err != nil {
+        return "", err
+    }
+    return block.Hash, nil
+}
+
+// Transfers returns the native and token transfers in a block to one of the addresses. Failed
+// transactions moved nothing and are left out.
+func (w *simulatorChainWatcher) Transfers(ctx context.Context, block ChainBlock, addresses *AddressIndex) ([]Transfer, error) {
+    config := w.chain.Config()
+    raw, ok := block.raw.(*simulator.Block)
+    if !ok {
+        return nil, fmt.Errorf("block %d was not fetched by the %s simulator watcher", block.Height, config.Name)
+    }
+
+    var transfers []Transfer
+    for _, tx := range raw.Transactions {
+        if tx.Status != simulator.StatusConfirmed || !addresses.Contains(tx.To) {
+            continue
+        }
+
+        transfer := Transfer{
+            TxHash:   tx.Hash,
+            From:     tx.From,
+            To:       tx.To,
+            Amount:   units.NewAmount(tx.Amount),
+            Decimals: config.Decimals,
+        }
+        if tx.Token != "" {
+            meta, err := w.chain.GetTokenMetadata(ctx, tx.Token)
+            if err != nil {
+                return nil, fmt.Errorf("failed to get token metadata: %w", err)
+            }
+            transfer.Decimals = meta.Decimals
+            transfer.TokenContract = meta.Contract
+            transfer.TokenSymbol = meta.Symbol
+        }
+        transfers = append(transfers, transfer)
+    }
+
+    return transfers, nil
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+)
+
+// solanaChainWatcher finds SOL and deposit token payments to our addresses by scanning the
+// finalized blocks of every slot. Payments are read from the balance changes of successful
+// transactions, so transfers made by any program, directly or through inner instructions, are
+// seen; SPL tokens are credited to the owner of the token account that received them.
+type solanaChainWatcher struct {
+    adapter *blockchain.SolanaAdapter
+    config  blockchain.ChainConfig
+    mints   map[string]bool
+}
+
+// NewSolanaChainWatcher creates a chain watcher for Solana
+func NewSolanaChainWatcher(config blockchain.ChainConfig, adapter *blockchain.SolanaAdapter) ChainWatcher {
+    mints := make(map[string]bool, len(config.DepositTokens))
+    for _, mint := range config.DepositTokens {
+        mints[mint] = true
+    }
+    return &solanaChainWatcher{adapter: adapter, config: config, mints: mints}
+}
+
+// Head returns the latest finalized slot
+func (w *solanaChainWatcher) Head(ctx context.Context) (uint64, error) {
+    return w.adapter.FinalizedSlot(ctx)
+}
+
+// FetchRange returns the blocks of the slots from one to another, inclusive, leaving out skipped slots
+func (w *solanaChainWatcher) FetchRange(ctx context.Context, from, to uint64) ([]ChainBlock, error) {
+    blocks, err := w.adapter.GetBlocks(ctx, from, to)
+    if err != nil {
+        return nil, err
+    }
+
+    result := make([]ChainBlock, 0, len(blocks))
+    for _, block := range blocks {
+        result = append(result, ChainBlock{
+            Height:     block.Slot,
+            Hash:       block.Hash,
+            ParentHash: block.PreviousHash,
+            raw:        block,
+        })
+    }
+    return result, nil
+}
+
+// BlockHash returns the hash of the block of a slot
+func (w *solanaChainWatcher) BlockHash(ctx context.Context, height uint64) (string, error) {
+    return w.adapter.BlockHash(ctx, height)
+}
+
+// Transfers returns the SOL and deposit token payments in a block to one of the addresses
+func (w *solanaChainWatcher) Transfers(ctx context.Context, block ChainBlock, addresses *AddressIndex) ([]Transfer, error) {
+    raw, ok := block.raw.(*blockchain.SolanaBlock)
+    if !ok {
+        return nil, fmt.Errorf("block %d was not fetched by the %s watcher", block.Height, w.config.Name)
+    }
+
+    var transfers []Transfer
+    for _, received := range raw.Transfers {
+        if !addresses.Contains(received.To) {
+            continue
+        }
+
+        transfer := Transfer{
+            TxHash:   received.Signature,
+            Index:    received.Index,
+            From:     received.From,
+            To:       received.To,
+            Amount:   units.NewAmount(received.Amount),
+            Decimals: received.Decimals,
+        }
+        if received.Mint != "" {
+            if !w.mints[received.Mint] {
+                // Anyone can send any mint; only the configured deposit tokens are credited
+                continue
+            }
+            meta, err := w.adapter.GetTokenMetadata(ctx, received.Mint)
+            if err != nil {
+                return nil, fmt.Errorf("failed to get token metadata: %w", err)
+            }
+            transfer.TokenContract = meta.Contract
+            transfer.TokenSymbol = meta.Symbol
+        }
+        transfers = append(transfers, transfer)
+    }
+
+    return transfers, nil
+}
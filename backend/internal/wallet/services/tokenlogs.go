@@ -0,0 +1,64 @@
+package services
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+    "github.com/ethereum/go-ethereum"
+    "github.com/ethereum/go-ethereum/common"
+)
+
+// tokenTransfers returns the transfers of the chain's deposit tokens to the addresses found by a
+// log query. The query only needs its block range or block hash; the token contracts and the
+// Transfer topic are filled in. Every transfer carries its log index, so one transaction can
+// credit several transfers.
+func (w *evmChainWatcher) tokenTransfers(ctx context.Context, query ethereum.FilterQuery, addresses *AddressIndex) ([]Transfer, error) {
+    if len(w.config.DepositTokens) == 0 {
+        return nil, nil
+    }
+
+    for _, token := range w.config.DepositTokens {
+        query.Addresses = append(query.Addresses, common.HexToAddress(token))
+    }
+    query.Topics = [][]common.Hash{{blockchain.ERC20TransferTopic}}
+
+    logs, err := w.client.FilterLogs(ctx, query)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get transfer logs: %w", err)
+    }
+
+    var transfers []Transfer
+    for _, l := range logs {
+        if l.Removed {
+            continue
+        }
+        transfer, err := blockchain.ParseERC20Transfer(l)
+        if err != nil {
+            // Not a fungible transfer, such as an ERC-721 Transfer with an indexed token ID
+            continue
+        }
+        if transfer.Value.Sign() == 0 || !addresses.Contains(transfer.To.Hex()) {
+            continue
+        }
+
+        meta, err := w.tokens.GetTokenMetadata(ctx, transfer.Token.Hex())
+        if err != nil {
+            return nil, fmt.Errorf("failed to get token metadata: %w", err)
+        }
+
+        transfers = append(transfers, Transfer{
+            TxHash:        transfer.TxHash.Hex(),
+            Index:         transfer.LogIndex,
+            From:          transfer.From.Hex(),
+            To:            transfer.To.Hex(),
+            Amount:        units.NewAmount(transfer.Value),
+            Decimals:      meta.Decimals,
+            TokenContract: meta.Contract,
+            TokenSymbol:   meta.Symbol,
+        })
+    }
+
+    return transfers, nil
+}
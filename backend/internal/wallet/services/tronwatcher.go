@@ -0,0 +1,95 @@
+package services
+
+import (
+    "context"
+    "fmt"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+)
+
+// tronChainWatcher finds TRX transfers and deposit token transfers to our addresses by scanning
+// Tron blocks. Token transfers are direct transfer(address,uint256) calls to a deposit token;
+// tokens moved by other contracts are not seen.
+type tronChainWatcher struct {
+    adapter *blockchain.TronAdapter
+    config  blockchain.ChainConfig
+    tokens  map[string]bool
+}
+
+// NewTronChainWatcher creates a chain watcher for Tron
+func NewTronChainWatcher(config blockchain.ChainConfig, adapter *blockchain.TronAdapter) ChainWatcher {
+    tokens := make(map[string]bool, len(config.DepositTokens))
+    for _, token := range config.DepositTokens {
+        tokens[token] = true
+    }
+    return &tronChainWatcher{adapter: adapter, config: config, tokens: tokens}
+}
+
+// Head returns the number of the latest block
+func (w *tronChainWatcher) Head(ctx context.Context) (uint64, error) {
+    return w.adapter.HeadBlock(ctx)
+}
+
+// FetchRange returns the blocks from one number to another, inclusive
+func (w *tronChainWatcher) FetchRange(ctx context.Context, from, to uint64) ([]ChainBlock, error) {
+    blocks, err := w.adapter.GetBlocks(ctx, from, to)
+    if err != nil {
+        return nil, err
+    }
+
+    result := make([]ChainBlock, 0, len(blocks))
+    for _, block := range blocks {
+        result = append(result, ChainBlock{
+            Height:     block.Number,
+            Hash:       block.Hash,
+            ParentHash: block.ParentHash,
+            raw:        block,
+        })
+    }
+    return result, nil
+}
+
+// BlockHash returns the ID of the block at a number
+func (w *tronChainWatcher) BlockHash(ctx context.Context, height uint64) (string, error) {
+    return w.adapter.BlockHash(ctx, height)
+}
+
+// Transfers returns the TRX and deposit token transfers in a block to one of the addresses
+func (w *tronChainWatcher) Transfers(ctx context.Context, block ChainBlock, addresses *AddressIndex) ([]Transfer, error) {
+    raw, ok := block.raw.(*blockchain.TronBlock)
+    if !ok {
+        return nil, fmt.Errorf("block %d was not fetched by the %s watcher", block.Height, w.config.Name)
+    }
+
+    var transfers []Transfer
+    for _, sent := range raw.Transfers {
+        if !addresses.Contains(sent.To) {
+            continue
+        }
+
+        transfer := Transfer{
+            TxHash:   sent.TxID,
+            Index:    sent.Index,
+            From:     sent.From,
+            To:       sent.To,
+            Amount:   units.NewAmount(sent.Amount),
+            Decimals: w.config.Decimals,
+        }
+        if sent.Token != "" {
+            if !w.tokens[sent.Token] {
+                continue
+            }
+            meta, err := w.adapter.GetTokenMetadata(ctx, sent.Token)
+            if err != nil {
+                return nil, fmt.Errorf("failed to get token metadata: %w", err)
+            }
+            transfer.Decimals = meta.Decimals
+            transfer.TokenContract = meta.Contract
+            transfer.TokenSymbol = meta.Symbol
+        }
+        transfers = append(transfers, transfer)
+    }
+
+    return transfers, nil
+}
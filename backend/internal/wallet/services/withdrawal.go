@@ -2,10 +2,13 @@ package services
 
 import (
     "context"
+    "errors"
     "fmt"
+    "log"
     "sync"
     "time"
 
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
     "github.com/blockchain-dapp/backend/internal/wallet"
     "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
     "github.com/blockchain-dapp/backend/internal/wallet/custodial"
@@ -30,23 +33,98 @@ func NewWithdrawalService(db *gorm.DB, adapters map[string]blockchain.Adapter, c
     }
 }
 
-// RequestWithdrawal creates a new withdrawal request
-func (ws *WithdrawalService) RequestWithdrawal(ctx context.Context, userID uint, chain, toAddress string, amount float64, useCustodial bool) (*wallet.Transaction, error) {
+// UseNonceManager makes every EVM adapter reserve nonces through the given manager,
+// so concurrent withdrawals from the same hot address never collide
+func (ws *WithdrawalService) UseNonceManager(nonces blockchain.NonceManager) {
+    ws.mu.Lock()
+    defer ws.mu.Unlock()
+
+    for _, adapter := range ws.adapters {
+        if managed, ok := adapter.(blockchain.NonceManagedAdapter); ok {
+            managed.SetNonceManager(nonces)
+        }
+    }
+}
+
+// RequestWithdrawal creates a new withdrawal request for the chain's native coin
+func (ws *WithdrawalService) RequestWithdrawal(ctx context.Context, userID uint, chain, toAddress string, amount units.Amount, tier blockchain.FeeTier, useCustodial bool) (*wallet.Transaction, error) {
+    return ws.RequestTokenWithdrawal(ctx, userID, chain, "", toAddress, amount, tier, useCustodial)
+}
+
+// EstimateWithdrawalFees returns the fee and expected confirmation time of a withdrawal at
+// every tier, so the user can pick one before requesting it
+func (ws *WithdrawalService) EstimateWithdrawalFees(ctx context.Context, userID uint, chain, token, toAddress string, amount units.Amount) (blockchain.FeeEstimates, error) {
+    if err := ws.validateAddress(chain, toAddress); err != nil {
+        return nil, fmt.Errorf("invalid destination address for chain %s: %w", chain, err)
+    }
+
+    w, err := ws.getPrivateWallet(ctx, userID, chain)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get private wallet: %w", err)
+    }
+
+    return ws.estimateFees(ctx, chain, token, w.Address, toAddress, amount)
+}
+
+// RequestTokenWithdrawal creates a new withdrawal request for a token such as USDT or USDC.
+// An empty token withdraws the chain's native coin. The amount is in base units of the asset,
+// and the fee tier decides how the transaction is priced when it is sent.
+func (ws *WithdrawalService) RequestTokenWithdrawal(ctx context.Context, userID uint, chain, token, toAddress string, amount units.Amount, tier blockchain.FeeTier, useCustodial bool) (*wallet.Transaction, error) {
     // Validate the destination address
-    if !ws.isValidAddress(chain, toAddress) {
-        return nil, fmt.Errorf("invalid destination address for chain %s", chain)
+    if err := ws.validateAddress(chain, toAddress); err != nil {
+        return nil, fmt.Errorf("invalid destination address for chain %s: %w", chain, err)
+    }
+
+    if amount.Sign() <= 0 {
+        return nil, fmt.Errorf("withdrawal amount must be positive")
+    }
+
+    tier, err := blockchain.ParseFeeTier(string(tier))
+    if err != nil {
+        return nil, err
+    }
+
+    config, err := blockchain.Chains().Lookup(chain)
+    if err != nil {
+        return nil, err
     }
     
-    // Create a transaction record
     transaction := &wallet.Transaction{
-        UserID:      userID,
-        Chain:       chain,
-        ToAddress:   toAddress,
-        Amount:      amount,
-        Status:      "pending",
-        CreatedAt:   time.Now(),
+        UserID:       userID,
+        Chain:        chain,
+        ToAddress:    toAddress,
+        Amount:       amount,
+        Decimals:     config.Decimals,
+        FeeTier:      string(tier),
+        Type:         "withdrawal",
+        Status:       "pending",
+        CreatedAt:    time.Now(),
         UseCustodial: useCustodial,
     }
+
+    if token != "" {
+        if useCustodial {
+            return nil, fmt.Errorf("custodial token withdrawals are not supported")
+        }
+
+        ws.mu.RLock()
+        adapter, exists := ws.adapters[chain]
+        ws.mu.RUnlock()
+
+        tokenAdapter, ok := adapter.(blockchain.TokenAdapter)
+        if !exists || !ok {
+            return nil, fmt.Errorf("token withdrawals are not supported on chain %s", chain)
+        }
+
+        meta, err := tokenAdapter.GetTokenMetadata(ctx, token)
+        if err != nil {
+            return nil, fmt.Errorf("failed to get token metadata: %w", err)
+        }
+
+        transaction.TokenContract = meta.Contract
+        transaction.TokenSymbol = meta.Symbol
+        transaction.Decimals = meta.Decimals
+    }
     
     // Save the transaction to the database
     if err := ws.db.Create(transaction).Error; err != nil {
@@ -56,26 +134,54 @@ func (ws *WithdrawalService) RequestWithdrawal(ctx context.Context, userID uint,
     return transaction, nil
 }
 
-// ProcessWithdrawal processes a withdrawal request
+// ProcessWithdrawal processes a withdrawal request. The withdrawal is claimed by moving it from
+// pending to processing first, so concurrent calls for the same one never both send it.
 func (ws *WithdrawalService) ProcessWithdrawal(ctx context.Context, transactionID uint) error {
+    result := ws.db.WithContext(ctx).Model(&wallet.Transaction{}).
+        Where("id = ? AND type = ? AND status = ?", transactionID, "withdrawal", "pending").
+        Updates(map[string]interface{}{"status": "processing", "updated_at": time.Now()})
+    if result.Error != nil {
+        return fmt.Errorf("failed to claim transaction: %w", result.Error)
+    }
+    if result.RowsAffected != 1 {
+        return fmt.Errorf("transaction is not in pending status")
+    }
+
     // Get the transaction
     var transaction wallet.Transaction
-    err := ws.db.First(&transaction, transactionID).Error
-    if err != nil {
+    if err := ws.db.WithContext(ctx).First(&transaction, transactionID).Error; err != nil {
+        ws.unclaim(ctx, transactionID)
         return fmt.Errorf("failed to fetch transaction: %w", err)
     }
-    
-    // Check if the transaction is already processed
-    if transaction.Status != "pending" {
-        return fmt.Errorf("transaction is not in pending status")
+
+    var err error
+    switch {
+    case transaction.TxHash != "" && transaction.RawTx == "":
+        err = fmt.Errorf("transaction %s is already broadcast", transaction.TxHash)
+    case transaction.TxHash != "":
+        // A withdrawal whose broadcast got no answer is sent again as it was signed, never re-signed
+        err = ws.rebroadcastWithdrawal(ctx, &transaction)
+    case transaction.UseCustodial:
+        err = ws.processCustodialWithdrawal(ctx, &transaction)
+    default:
+        err = ws.processDirectWithdrawal(ctx, &transaction)
     }
-    
-    // Process based on whether we're using custodial or direct signing
-    if transaction.UseCustodial {
-        return ws.processCustodialWithdrawal(ctx, &transaction)
+
+    // A withdrawal left processing got no new status, so it goes back to pending to be retried
+    if transaction.Status == "processing" {
+        ws.unclaim(ctx, transactionID)
+    }
+    return err
+}
+
+// unclaim hands a claimed withdrawal back to pending
+func (ws *WithdrawalService) unclaim(ctx context.Context, transactionID uint) {
+    err := ws.db.WithContext(ctx).Model(&wallet.Transaction{}).
+        Where("id = ? AND status = ?", transactionID, "processing").
+        Updates(map[string]interface{}{"status": "pending", "updated_at": time.Now()}).Error
+    if err != nil {
+        log.Printf("Failed to return withdrawal %d to pending: %v", transactionID, err)
     }
-    
-    return ws.processDirectWithdrawal(ctx, &transaction)
 }
 
 // processCustodialWithdrawal processes a withdrawal using a custodial provider
@@ -97,9 +203,7 @@ func (ws *WithdrawalService) processCustodialWithdrawal(ctx context.Context, tra
     // Send the transaction through the custodial provider
     tx, err := provider.SendTransaction(ctx, custodialWallet.ExternalID, transaction.ToAddress, transaction.Amount)
     if err != nil {
-        transaction.Status = "failed"
-        transaction.ErrorMessage = err.Error()
-        ws.db.Save(transaction)
+        ws.recordSendError(transaction, err)
         return fmt.Errorf("failed to send transaction through custodial provider: %w", err)
     }
     
@@ -146,11 +250,29 @@ func (ws *WithdrawalService) processDirectWithdrawal(ctx context.Context, transa
     // Send the transaction using the blockchain adapter
     // Note: In a real implementation, the private key should never be stored in plain text
     // and should be retrieved from a secure key management system
-    tx, err := adapter.SendTransaction(ctx, w.Address, transaction.ToAddress, transaction.Amount, w.PrivateKey)
+    // Price the transaction with the tier the user picked, at current network conditions
+    fee, err := ws.selectFee(ctx, transaction, w.Address)
     if err != nil {
-        transaction.Status = "failed"
-        transaction.ErrorMessage = err.Error()
-        ws.db.Save(transaction)
+        ws.recordSendError(transaction, err)
+        return fmt.Errorf("failed to estimate fee: %w", err)
+    }
+
+    // Recorded before sending, in case the broadcast ends without an answer
+    transaction.FromAddress = w.Address
+    transaction.FeeTier = string(fee.Tier)
+
+    var tx *blockchain.Transaction
+    if transaction.TokenContract != "" {
+        tokenAdapter, ok := adapter.(blockchain.TokenAdapter)
+        if !ok {
+            return fmt.Errorf("token withdrawals are not supported on chain %s", transaction.Chain)
+        }
+        tx, err = tokenAdapter.SendTokenTransaction(ctx, transaction.TokenContract, w.Address, transaction.ToAddress, transaction.Amount, w.PrivateKey, fee)
+    } else {
+        tx, err = adapter.SendTransaction(ctx, w.Address, transaction.ToAddress, transaction.Amount, w.PrivateKey, fee)
+    }
+    if err != nil {
+        ws.recordSendError(transaction, err)
         return fmt.Errorf("failed to send transaction: %w", err)
     }
     
@@ -158,6 +280,11 @@ func (ws *WithdrawalService) processDirectWithdrawal(ctx context.Context, transa
     transaction.TxHash = tx.Hash
     transaction.FromAddress = tx.From
     transaction.Fee = tx.Fee
+    transaction.FeeTier = string(fee.Tier)
+    transaction.GasPrice = tx.GasPrice
+    transaction.GasTipCap = tx.GasTipCap
+    transaction.GasLimit = float64(tx.GasLimit)
+    transaction.FeeRate = tx.FeeRate
     transaction.Status = tx.Status
     transaction.Confirmations = tx.Confirmations
     transaction.UpdatedAt = time.Now()
@@ -165,6 +292,158 @@ func (ws *WithdrawalService) processDirectWithdrawal(ctx context.Context, transa
     return ws.db.Save(transaction).Error
 }
 
+// rebroadcastWithdrawal sends a withdrawal whose first broadcast got no answer again, byte for
+// byte. A node that refuses it moves the withdrawal to needs_review, as only an operator can tell
+// whether signing it again would pay the user twice.
+func (ws *WithdrawalService) rebroadcastWithdrawal(ctx context.Context, transaction *wallet.Transaction) error {
+    ws.mu.RLock()
+    adapter, exists := ws.adapters[transaction.Chain]
+    ws.mu.RUnlock()
+
+    if !exists {
+        return fmt.Errorf("unsupported chain: %s", transaction.Chain)
+    }
+
+    // The first broadcast may have reached the network after all
+    _, err := adapter.GetTransaction(ctx, transaction.TxHash)
+    if err == nil {
+        log.Printf("Withdrawal %d on %s was broadcast as %s", transaction.ID, transaction.Chain, transaction.TxHash)
+        return ws.rebroadcasted(transaction)
+    }
+    if !errors.Is(err, blockchain.ErrNotFound) {
+        return fmt.Errorf("failed to look up transaction %s: %w", transaction.TxHash, err)
+    }
+
+    rebroadcaster, ok := adapter.(blockchain.RebroadcastAdapter)
+    if !ok {
+        return ws.needsReview(transaction, fmt.Errorf("chain %s cannot rebroadcast transaction %s", transaction.Chain, transaction.TxHash))
+    }
+
+    err = rebroadcaster.RebroadcastTransaction(ctx, transaction.RawTx)
+    var broadcastErr *blockchain.BroadcastError
+    switch {
+    case err == nil:
+        log.Printf("Rebroadcast withdrawal %d on %s as %s", transaction.ID, transaction.Chain, transaction.TxHash)
+        return ws.rebroadcasted(transaction)
+    case errors.As(err, &broadcastErr):
+        return fmt.Errorf("failed to rebroadcast transaction: %w", err)
+    default:
+        return ws.needsReview(transaction, err)
+    }
+}
+
+// rebroadcasted hands a withdrawal that reached the network back to the ConfirmationTracker. The
+// signed transaction is forgotten, as the node has it and nothing may send it again.
+func (ws *WithdrawalService) rebroadcasted(transaction *wallet.Transaction) error {
+    transaction.Status = "pending"
+    transaction.RawTx = ""
+    transaction.ErrorMessage = ""
+    transaction.UpdatedAt = time.Now()
+    return ws.db.Save(transaction).Error
+}
+
+// needsReview parks a withdrawal that may or may not have been sent until an operator settles it
+func (ws *WithdrawalService) needsReview(transaction *wallet.Transaction, err error) error {
+    transaction.Status = "needs_review"
+    transaction.ErrorMessage = err.Error()
+    transaction.UpdatedAt = time.Now()
+    alertf("withdrawal %d on %s needs review: %v", transaction.ID, transaction.Chain, err)
+
+    if saveErr := ws.db.Save(transaction).Error; saveErr != nil {
+        return fmt.Errorf("failed to record review of withdrawal %d: %w", transaction.ID, saveErr)
+    }
+    return err
+}
+
+// recordSendError decides what a failed send means for a withdrawal. Errors raised before the
+// transaction was signed and that may clear up on their own, such as an unreachable chain or a
+// stale nonce, leave it pending so the next ProcessWithdrawal call retries it. A signed
+// transaction that may have reached the network is kept on the withdrawal, to be rebroadcast
+// as it is. Anything else fails it, and failures the user did not cause raise an alert for
+// operators.
+func (ws *WithdrawalService) recordSendError(transaction *wallet.Transaction, err error) {
+    transaction.ErrorMessage = err.Error()
+    transaction.UpdatedAt = time.Now()
+
+    var broadcastErr *blockchain.BroadcastError
+    switch {
+    case errors.As(err, &broadcastErr):
+        // Still pending, so the ConfirmationTracker settles it if it was mined after all
+        transaction.Status = "pending"
+        transaction.TxHash = broadcastErr.Hash
+        transaction.RawTx = broadcastErr.Raw
+        log.Printf("Withdrawal %d on %s may have been broadcast as %s and will be rebroadcast: %v", transaction.ID, transaction.Chain, broadcastErr.Hash, err)
+    case blockchain.IsRetryable(err) && transaction.UseCustodial:
+        // The provider may have taken the request before failing, and would send it again
+        transaction.Status = "needs_review"
+        alertf("custodial withdrawal %d on %s may have been sent: %v", transaction.ID, transaction.Chain, err)
+    case blockchain.IsRetryable(err):
+        transaction.Status = "pending"
+        log.Printf("Withdrawal %d on %s will be retried: %v", transaction.ID, transaction.Chain, err)
+    case errors.Is(err, blockchain.ErrInsufficientFunds) && transaction.UseCustodial:
+        // The custodial wallet funds every user's withdrawals, so it needs topping up
+        transaction.Status = "failed"
+        alertf("custodial wallet on %s cannot cover withdrawal %d: %v", transaction.Chain, transaction.ID, err)
+    case errors.Is(err, blockchain.ErrReverted):
+        // Gas estimation should have caught a revert before broadcasting
+        transaction.Status = "failed"
+        alertf("withdrawal %d reverted on %s: %v", transaction.ID, transaction.Chain, err)
+    default:
+        transaction.Status = "failed"
+    }
+
+    if err := ws.db.Save(transaction).Error; err != nil {
+        log.Printf("Failed to record error of withdrawal %d: %v", transaction.ID, err)
+    }
+}
+
+// alertf logs a failure that needs an operator's attention
+func alertf(format string, args ...interface{}) {
+    log.Printf("ALERT: "+format, args...)
+}
+
+// selectFee estimates the fees of a withdrawal and picks the estimate of its tier
+func (ws *WithdrawalService) selectFee(ctx context.Context, transaction *wallet.Transaction, from string) (*blockchain.FeeEstimate, error) {
+    tier, err := blockchain.ParseFeeTier(transaction.FeeTier)
+    if err != nil {
+        return nil, err
+    }
+
+    estimates, err := ws.estimateFees(ctx, transaction.Chain, transaction.TokenContract, from, transaction.ToAddress, transaction.Amount)
+    if err != nil {
+        return nil, err
+    }
+
+    fee, err := estimates.Tier(tier)
+    if err != nil {
+        return nil, err
+    }
+
+    return &fee, nil
+}
+
+// estimateFees estimates the fees of sending the native coin, or a token when one is given
+func (ws *WithdrawalService) estimateFees(ctx context.Context, chain, token, from, to string, amount units.Amount) (blockchain.FeeEstimates, error) {
+    ws.mu.RLock()
+    adapter, exists := ws.adapters[chain]
+    ws.mu.RUnlock()
+
+    if !exists {
+        return nil, fmt.Errorf("unsupported chain: %s", chain)
+    }
+
+    if token == "" {
+        return adapter.EstimateFee(ctx, from, to, amount)
+    }
+
+    tokenAdapter, ok := adapter.(blockchain.TokenAdapter)
+    if !ok {
+        return nil, fmt.Errorf("token withdrawals are not supported on chain %s", chain)
+    }
+
+    return tokenAdapter.EstimateTokenFee(ctx, token, from, to, amount)
+}
+
 // getCustodialWallet retrieves a user's custodial wallet for a specific chain
 func (ws *WithdrawalService) getCustodialWallet(ctx context.Context, userID uint, chain string) (*wallet.CustodialWallet, error) {
     var w wallet.CustodialWallet
@@ -187,19 +466,17 @@ func (ws *WithdrawalService) getPrivateWallet(ctx context.Context, userID uint,
     return &w, nil
 }
 
-// isValidAddress validates an address for a specific chain
-func (ws *WithdrawalService) isValidAddress(chain, address string) bool {
+// validateAddress checks an address against the format of a specific chain
+func (ws *WithdrawalService) validateAddress(chain, address string) error {
     ws.mu.RLock()
     adapter, exists := ws.adapters[chain]
     ws.mu.RUnlock()
     
     if !exists {
-        return false
+        return fmt.Errorf("unsupported chain: %s", chain)
     }
     
-    // In a real implementation, each adapter would have its own address validation logic
-    // For now, we'll just check that the address is not empty
-    return address != ""
+    return adapter.ValidateAddress(address)
 }
 
 // CancelWithdrawal cancels a pending withdrawal request
@@ -213,11 +490,255 @@ func (ws *WithdrawalService) CancelWithdrawal(ctx context.Context, transactionID
     if transaction.Status != "pending" {
         return fmt.Errorf("transaction is not in pending status")
     }
+
+    // Once broadcast, only a replacement on chain can stop the withdrawal
+    if transaction.TxHash != "" {
+        return fmt.Errorf("transaction %s is already broadcast, use CancelBroadcastWithdrawal", transaction.TxHash)
+    }
     
-    transaction.Status = "cancelled"
-    transaction.UpdatedAt = time.Now()
-    
-    return ws.db.Save(&transaction).Error
+    // Only while nobody claimed it to send it
+    result := ws.db.WithContext(ctx).Model(&wallet.Transaction{}).
+        Where("id = ? AND status = ? AND tx_hash = ?", transactionID, "pending", "").
+        Updates(map[string]interface{}{"status": "cancelled", "updated_at": time.Now()})
+    if result.Error != nil {
+        return fmt.Errorf("failed to cancel transaction: %w", result.Error)
+    }
+    if result.RowsAffected != 1 {
+        return fmt.Errorf("transaction is not in pending status")
+    }
+    return nil
+}
+
+// SpeedUpWithdrawal replaces a stuck broadcast withdrawal with the same transfer priced at the
+// given fee tier: the same nonce on EVM chains, or an RBF spend of the same inputs on Bitcoin
+func (ws *WithdrawalService) SpeedUpWithdrawal(ctx context.Context, transactionID uint, tier blockchain.FeeTier) (*wallet.Transaction, error) {
+    return ws.replaceWithdrawal(ctx, transactionID, tier, false)
+}
+
+// CancelBroadcastWithdrawal replaces a stuck broadcast withdrawal with a transaction that sends
+// the funds back to the hot wallet, priced at the given fee tier
+func (ws *WithdrawalService) CancelBroadcastWithdrawal(ctx context.Context, transactionID uint, tier blockchain.FeeTier) (*wallet.Transaction, error) {
+    return ws.replaceWithdrawal(ctx, transactionID, tier, true)
+}
+
+// replaceWithdrawal broadcasts a replacement for a pending withdrawal and records it as a new
+// transaction linked to the one it replaces. The withdrawal is claimed by moving it from pending
+// to processing first, like ProcessWithdrawal does, so concurrent calls never both replace it, and
+// it goes back to pending if no replacement is recorded.
+func (ws *WithdrawalService) replaceWithdrawal(ctx context.Context, transactionID uint, tier blockchain.FeeTier, cancel bool) (*wallet.Transaction, error) {
+    tier, err := blockchain.ParseFeeTier(string(tier))
+    if err != nil {
+        return nil, err
+    }
+
+    result := ws.db.WithContext(ctx).Model(&wallet.Transaction{}).
+        Where("id = ? AND type = ? AND status = ? AND tx_hash <> ? AND replaced_by_tx_hash = ?", transactionID, "withdrawal", "pending", "", "").
+        Updates(map[string]interface{}{"status": "processing", "updated_at": time.Now()})
+    if result.Error != nil {
+        return nil, fmt.Errorf("failed to claim transaction: %w", result.Error)
+    }
+    if result.RowsAffected != 1 {
+        return nil, fmt.Errorf("only broadcast pending transactions that were not replaced yet can be replaced")
+    }
+
+    replacement, err := ws.replaceClaimed(ctx, transactionID, tier, cancel)
+    if err != nil {
+        ws.unclaim(ctx, transactionID)
+        return nil, err
+    }
+    return replacement, nil
+}
+
+// replaceClaimed broadcasts and records the replacement of a withdrawal claimed by replaceWithdrawal
+func (ws *WithdrawalService) replaceClaimed(ctx context.Context, transactionID uint, tier blockchain.FeeTier, cancel bool) (*wallet.Transaction, error) {
+    var original wallet.Transaction
+    if err := ws.db.WithContext(ctx).First(&original, transactionID).Error; err != nil {
+        return nil, fmt.Errorf("failed to fetch transaction: %w", err)
+    }
+    if original.UseCustodial {
+        return nil, fmt.Errorf("custodial withdrawals must be replaced through the provider")
+    }
+
+    ws.mu.RLock()
+    adapter, exists := ws.adapters[original.Chain]
+    ws.mu.RUnlock()
+
+    replaceable, ok := adapter.(blockchain.ReplaceableAdapter)
+    if !exists || !ok {
+        return nil, fmt.Errorf("replacing transactions is not supported on chain %s", original.Chain)
+    }
+
+    w, err := ws.getPrivateWallet(ctx, original.UserID, original.Chain)
+    if err != nil {
+        return nil, fmt.Errorf("failed to get private wallet: %w", err)
+    }
+
+    // A cancellation is priced as a plain transfer back to ourselves
+    var estimates blockchain.FeeEstimates
+    if cancel {
+        estimates, err = adapter.EstimateFee(ctx, w.Address, w.Address, units.Amount{})
+    } else {
+        estimates, err = ws.estimateFees(ctx, original.Chain, original.TokenContract, w.Address, original.ToAddress, original.Amount)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to estimate fee: %w", err)
+    }
+    fee, err := estimates.Tier(tier)
+    if err != nil {
+        return nil, err
+    }
+
+    var tx *blockchain.Transaction
+    if cancel {
+        tx, err = replaceable.CancelTransaction(ctx, original.TxHash, w.Address, w.PrivateKey, &fee)
+    } else {
+        tx, err = replaceable.SpeedUpTransaction(ctx, original.TxHash, w.Address, w.PrivateKey, &fee)
+    }
+    if errors.Is(err, blockchain.ErrTransactionNotPending) {
+        return nil, fmt.Errorf("transaction %s was already mined or dropped: %w", original.TxHash, err)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to replace transaction: %w", err)
+    }
+
+    replacement := original
+    replacement.ID = 0
+    replacement.TxHash = tx.Hash
+    replacement.Fee = tx.Fee
+    replacement.FeeTier = string(fee.Tier)
+    replacement.GasPrice = tx.GasPrice
+    replacement.GasTipCap = tx.GasTipCap
+    replacement.GasLimit = float64(tx.GasLimit)
+    replacement.FeeRate = tx.FeeRate
+    replacement.RawTx = ""
+    replacement.ErrorMessage = ""
+    replacement.Status = "pending"
+    replacement.Confirmations = 0
+    replacement.ReplacesTxHash = original.TxHash
+    replacement.ReplacedByTxHash = ""
+    replacement.CreatedAt = time.Now()
+    replacement.UpdatedAt = time.Now()
+    if cancel {
+        config, err := blockchain.Chains().Lookup(original.Chain)
+        if err != nil {
+            return nil, err
+        }
+        replacement.ToAddress = tx.To
+        replacement.Amount = units.Amount{}
+        replacement.Decimals = config.Decimals
+        replacement.TokenContract = ""
+        replacement.TokenSymbol = ""
+        replacement.Memo = fmt.Sprintf("cancels %s", original.TxHash)
+    }
+
+    // The replacement is already on the network, so record it even if linking fails halfway
+    err = ws.db.Transaction(func(db *gorm.DB) error {
+        if err := db.Create(&replacement).Error; err != nil {
+            return err
+        }
+        return db.Model(&wallet.Transaction{}).
+            Where("id = ? AND status = ?", original.ID, "processing").
+            Updates(map[string]interface{}{
+                "replaced_by_tx_hash": replacement.TxHash,
+                "status":              "replaced",
+                "updated_at":          time.Now(),
+            }).Error
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to record replacement %s of %s: %w", replacement.TxHash, original.TxHash, err)
+    }
+
+    return &replacement, nil
+}
+
+// ResolveReplacements settles a withdrawal that was replaced once any transaction in its
+// replacement chain is mined: the mined one takes its status from the chain and every other one
+// is marked dropped. It returns the mined transaction, or nil while none of them is mined.
+func (ws *WithdrawalService) ResolveReplacements(ctx context.Context, transactionID uint) (*wallet.Transaction, error) {
+    var transaction wallet.Transaction
+    if err := ws.db.First(&transaction, transactionID).Error; err != nil {
+        return nil, fmt.Errorf("failed to fetch transaction: %w", err)
+    }
+
+    ws.mu.RLock()
+    adapter, exists := ws.adapters[transaction.Chain]
+    ws.mu.RUnlock()
+
+    if !exists {
+        return nil, fmt.Errorf("unsupported chain: %s", transaction.Chain)
+    }
+
+    group, err := replacementChain(ctx, ws.db, transaction)
+    if err != nil {
+        return nil, err
+    }
+
+    var mined *wallet.Transaction
+    var minedTx *blockchain.Transaction
+    for i := range group {
+        tx, err := adapter.GetTransaction(ctx, group[i].TxHash)
+        if errors.Is(err, blockchain.ErrNotFound) {
+            // Dropped replacements are unknown to the node
+            continue
+        }
+        if err != nil {
+            return nil, fmt.Errorf("failed to fetch transaction %s: %w", group[i].TxHash, err)
+        }
+        if tx.Confirmations > 0 {
+            mined, minedTx = &group[i], tx
+            break
+        }
+    }
+    if mined == nil {
+        return nil, nil
+    }
+
+    err = ws.db.Transaction(func(db *gorm.DB) error {
+        for i := range group {
+            if &group[i] == mined {
+                continue
+            }
+            if err := db.Model(&group[i]).Updates(map[string]interface{}{"status": "dropped", "updated_at": time.Now()}).Error; err != nil {
+                return err
+            }
+        }
+
+        mined.Status = minedTx.Status
+        mined.Confirmations = minedTx.Confirmations
+        mined.UpdatedAt = time.Now()
+        return db.Save(mined).Error
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to settle replacements of %s: %w", transaction.TxHash, err)
+    }
+
+    return mined, nil
+}
+
+// replacementChain loads every transaction linked to one by fee bumps, from the original
+// transaction to the latest replacement
+func replacementChain(ctx context.Context, db *gorm.DB, transaction wallet.Transaction) ([]wallet.Transaction, error) {
+    // Walk back to the first transaction
+    for transaction.ReplacesTxHash != "" {
+        var previous wallet.Transaction
+        if err := db.WithContext(ctx).Where("tx_hash = ?", transaction.ReplacesTxHash).First(&previous).Error; err != nil {
+            return nil, fmt.Errorf("failed to fetch replaced transaction %s: %w", transaction.ReplacesTxHash, err)
+        }
+        transaction = previous
+    }
+
+    // Then forward through every replacement
+    group := []wallet.Transaction{transaction}
+    for transaction.ReplacedByTxHash != "" {
+        var next wallet.Transaction
+        if err := db.WithContext(ctx).Where("tx_hash = ?", transaction.ReplacedByTxHash).First(&next).Error; err != nil {
+            return nil, fmt.Errorf("failed to fetch replacement %s: %w", transaction.ReplacedByTxHash, err)
+        }
+        transaction = next
+        group = append(group, transaction)
+    }
+
+    return group, nil
 }
 
 // SecurityReview returns the security review checklist for the withdrawal service
@@ -0,0 +1,127 @@
+package wallet
+
+import (
+    "encoding/json"
+    "fmt"
+
+    "github.com/blockchain-dapp/backend/internal/pkg/units"
+    "github.com/blockchain-dapp/backend/internal/wallet/blockchain"
+)
+
+// Amounts are stored and passed around in base units; these views add the display
+// values for API responses and parse them from requests, which are the only places
+// amounts are converted
+
+// walletView is the API representation of a wallet
+type walletView struct {
+    Wallet
+    BalanceFormatted string `json:"balance_formatted"`
+    Symbol           string `json:"symbol"`
+}
+
+// newWalletView formats a wallet's balance with its chain's decimals
+func newWalletView(w Wallet) walletView {
+    config, _ := blockchain.Chains().Get(w.Chain)
+    return walletView{
+        Wallet:           w,
+        BalanceFormatted: w.Balance.Format(config.Decimals),
+        Symbol:           config.NativeSymbol,
+    }
+}
+
+// newWalletViews formats a list of wallets
+func newWalletViews(wallets []Wallet) []walletView {
+    views := make([]walletView, 0, len(wallets))
+    for _, w := range wallets {
+        views = append(views, newWalletView(w))
+    }
+    return views
+}
+
+// transactionView is the API representation of a transaction
+type transactionView struct {
+    Transaction
+    AmountFormatted string `json:"amount_formatted"`
+    FeeFormatted    string `json:"fee_formatted"`
+}
+
+// newTransactionView formats a transaction's amount with its asset's decimals and its fee
+// with the decimals of the chain's native coin
+func newTransactionView(tx Transaction) transactionView {
+    config, _ := blockchain.Chains().Get(tx.Chain)
+
+    decimals := tx.Decimals
+    if tx.TokenContract == "" {
+        decimals = config.Decimals
+    }
+
+    return transactionView{
+        Transaction:     tx,
+        AmountFormatted: tx.Amount.Format(decimals),
+        FeeFormatted:    tx.Fee.Format(config.Decimals),
+    }
+}
+
+// transactionRequest is the API representation of a new transaction. Its amount is in display
+// units, like amount_formatted in responses, as a string or a number.
+type transactionRequest struct {
+    Transaction
+    Amount json.Number `json:"amount"`
+}
+
+// transaction converts the request's amount to base units with the decimals of the chain's
+// native coin, or the decimals given for a token transfer
+func (r transactionRequest) transaction() (Transaction, error) {
+    tx := r.Transaction
+    config, ok := blockchain.Chains().Get(tx.Chain)
+    if !ok {
+        return tx, fmt.Errorf("%w: %s", ErrUnsupportedChain, tx.Chain)
+    }
+
+    decimals := tx.Decimals
+    if tx.TokenContract == "" {
+        decimals = config.Decimals
+    }
+
+    amount, err := units.Parse(r.Amount.String(), decimals)
+    if err != nil {
+        return tx, fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+    }
+    tx.Amount = amount
+    return tx, nil
+}
+
+// newTransactionViews formats a list of transactions
+func newTransactionViews(transactions []Transaction) []transactionView {
+    views := make([]transactionView, 0, len(transactions))
+    for _, tx := range transactions {
+        views = append(views, newTransactionView(tx))
+    }
+    return views
+}
+
+// custodialWalletView is the API representation of a custodial wallet
+type custodialWalletView struct {
+    CustodialWallet
+    BalanceFormatted string `json:"balance_formatted"`
+    Symbol           string `json:"symbol"`
+}
+
+// newCustodialWalletView formats a custodial wallet's balance with its chain's decimals
+func newCustodialWalletView(w CustodialWallet) custodialWalletView {
+    config, _ := blockchain.Chains().Get(w.Chain)
+    return custodialWalletView{
+        CustodialWallet:  w,
+        BalanceFormatted: w.Balance.Format(config.Decimals),
+        Symbol:           config.NativeSymbol,
+    }
+}
+
+// newCustodialWalletViews formats a list of custodial wallets
+func newCustodialWalletViews(wallets []CustodialWallet) []custodialWalletView {
+    views := make([]custodialWalletView, 0, len(wallets))
+    for _, w := range wallets {
+        views = append(views, newCustodialWalletView(w))
+    }
+    return views
+}